@@ -0,0 +1,92 @@
+package utfbom
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// ScanZip detects the BOM of every regular file of the zip archive read from r, size bytes long,
+// without extracting it. Reports are returned in lexical path order, and Size is the uncompressed size.
+//
+// Options apply the same way as for ScanDir, which does the work over the archive as an fs.FS;
+// entry names are cleaned the same way as by ScanTar, so "../a.csv" is reported as "a.csv".
+func ScanZip(r io.ReaderAt, size int64, opts ...ScanOption) ([]FileReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return nil, err
+	}
+
+	return ScanDir(zr, opts...)
+}
+
+// ScanTar detects the BOM of every regular file of the tar stream read from r, without extracting it.
+// Reports are returned in archive order, with the paths cleaned as if rooted at the archive,
+// dropping leading "/", "./" and "../" elements.
+// Include and exclude patterns apply the same way as for ScanDir, including exclusion by parent directory;
+// entries are inspected one at a time, as the stream is read.
+//
+// If the stream is corrupt, the reports of the entries before the corruption are returned along with the error.
+// Wrap compressed streams with a decompressor, such as gzip.NewReader, first.
+func ScanTar(r io.Reader, opts ...ScanOption) ([]FileReport, error) {
+	cfg := newScanConfig(opts)
+	tr := tar.NewReader(r)
+
+	var reports []FileReport
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			cfg.addStats(reports)
+
+			return reports, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		p := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if p == "" || cfg.excluded(p) || !cfg.included(p) {
+			continue
+		}
+
+		var buf [maxBOMLen]byte
+
+		n, err := io.ReadFull(tr, buf[:])
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			cfg.addStats(reports)
+
+			return reports, err
+		}
+
+		reports = append(reports, FileReport{
+			Path:     p,
+			Encoding: DetectEncoding(buf[:n]),
+			Size:     hdr.Size,
+			Offset:   0,
+		})
+	}
+
+	cfg.addStats(reports)
+
+	return reports, nil
+}
+
+// excluded reports whether p or any of its parent directories matches an exclude pattern.
+func (c *scanConfig) excluded(p string) bool {
+	for ; p != "." && p != "/"; p = path.Dir(p) {
+		if matchAny(c.exclude, p) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,163 @@
+package utfbom_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+// archiveEntry is a file of a test archive.
+type archiveEntry struct {
+	name string
+	data string
+}
+
+var archiveEntries = []archiveEntry{
+	{"b.csv", "plain"},
+	{"a.csv", "\ufeffa,b"},
+	{"./docs/c.txt", "\xff\xfec\x00"},
+	{"vendor/deep/d.csv", "\ufeffd"},
+	{"/abs.csv", "\xfe\xff\x00a"},
+}
+
+func makeZip(t *testing.T, entries []archiveEntry) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		be.Err(t, err, nil)
+
+		_, err = w.Write([]byte(e.data))
+		be.Err(t, err, nil)
+	}
+
+	be.Err(t, zw.Close(), nil)
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func makeTar(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+
+	be.Err(t, tw.WriteHeader(&tar.Header{Name: "docs/", Typeflag: tar.TypeDir, Mode: 0o755}), nil)
+	be.Err(t, tw.WriteHeader(&tar.Header{Name: "link.csv", Typeflag: tar.TypeSymlink, Linkname: "a.csv"}), nil)
+
+	for _, e := range entries {
+		be.Err(t, tw.WriteHeader(&tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e.data))}), nil)
+
+		_, err := tw.Write([]byte(e.data))
+		be.Err(t, err, nil)
+	}
+
+	be.Err(t, tw.Close(), nil)
+
+	return buf.Bytes()
+}
+
+func TestScanZip(t *testing.T) {
+	t.Parallel()
+
+	zr := makeZip(t, append(archiveEntries, archiveEntry{"../evil.csv", "evil"}))
+
+	reports, err := utfbom.ScanZip(zr, zr.Size())
+	be.Err(t, err, nil)
+	be.Equal(t, reports, []utfbom.FileReport{
+		{Path: "a.csv", Encoding: utfbom.UTF8, Size: 6},
+		{Path: "abs.csv", Encoding: utfbom.UTF16BigEndian, Size: 4},
+		{Path: "b.csv", Encoding: utfbom.Unknown, Size: 5},
+		{Path: "docs/c.txt", Encoding: utfbom.UTF16LittleEndian, Size: 4},
+		{Path: "evil.csv", Encoding: utfbom.Unknown, Size: 4},
+		{Path: "vendor/deep/d.csv", Encoding: utfbom.UTF8, Size: 4},
+	})
+
+	st := utfbom.Stats{}
+
+	reports, err = utfbom.ScanZip(zr, zr.Size(), utfbom.ScanExclude("vendor"), utfbom.ScanStats(&st))
+	be.Err(t, err, nil)
+	be.Equal(t, len(reports), 5)
+	be.Equal(t, st.Total.Files, 5)
+}
+
+func TestScanZip_NotZip(t *testing.T) {
+	t.Parallel()
+
+	r := bytes.NewReader([]byte("\ufeffnot a zip"))
+
+	_, err := utfbom.ScanZip(r, r.Size())
+	be.Err(t, err, zip.ErrFormat)
+}
+
+func TestScanTar(t *testing.T) {
+	t.Parallel()
+
+	data := makeTar(t, archiveEntries)
+
+	reports, err := utfbom.ScanTar(bytes.NewReader(data))
+	be.Err(t, err, nil)
+	be.Equal(t, reports, []utfbom.FileReport{
+		{Path: "b.csv", Encoding: utfbom.Unknown, Size: 5},
+		{Path: "a.csv", Encoding: utfbom.UTF8, Size: 6},
+		{Path: "docs/c.txt", Encoding: utfbom.UTF16LittleEndian, Size: 4},
+		{Path: "vendor/deep/d.csv", Encoding: utfbom.UTF8, Size: 4},
+		{Path: "abs.csv", Encoding: utfbom.UTF16BigEndian, Size: 4},
+	})
+
+	st := utfbom.Stats{}
+
+	reports, err = utfbom.ScanTar(bytes.NewReader(data), utfbom.ScanInclude("*.csv"), utfbom.ScanExclude("vendor"), utfbom.ScanStats(&st))
+	be.Err(t, err, nil)
+
+	paths := make([]string, 0, len(reports))
+	for _, r := range reports {
+		paths = append(paths, r.Path)
+	}
+
+	be.Equal(t, paths, []string{"b.csv", "a.csv", "abs.csv"})
+	be.Equal(t, st.Total.Files, 3)
+}
+
+func TestScanTar_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	data := makeTar(t, archiveEntries)
+
+	// the first regular entry is intact, the header of the second one is cut short
+	reports, err := utfbom.ScanTar(bytes.NewReader(data[:512*4+100]))
+	be.Err(t, err, "unexpected EOF")
+	be.Equal(t, reports, []utfbom.FileReport{
+		{Path: "b.csv", Encoding: utfbom.Unknown, Size: 5},
+	})
+}
+
+func ExampleScanZip() {
+	var buf bytes.Buffer
+
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("export.csv")
+	_, _ = w.Write([]byte("\ufeffName,City\n"))
+	_ = zw.Close()
+
+	reports, err := utfbom.ScanZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		panic(err)
+	}
+
+	for _, r := range reports {
+		fmt.Println(r.Path, r.Encoding)
+	}
+	// output:
+	// export.csv UTF8
+}
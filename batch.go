@@ -0,0 +1,32 @@
+package utfbom
+
+import "iter"
+
+// TrimSlice removes the BOM prefix from every element of s, same as Trim,
+// and returns s along with the encoding detected for each element, Unknown for elements without a BOM.
+//
+// The elements are trimmed in place, so s itself is returned and no element is copied:
+// the only allocation is the returned []Encoding.
+// It suits cleaning up CSV headers and cells, where BOMs tend to hide in the first field.
+func TrimSlice[S ~[]E, E ~string | ~[]byte](s S) (S, []Encoding) {
+	encs := make([]Encoding, len(s))
+
+	for i, v := range s {
+		s[i], encs[i] = Trim(v)
+	}
+
+	return s, encs
+}
+
+// TrimSeq returns an iterator over the elements of seq with their BOM prefix removed, same as Trim,
+// yielding each element along with its detected encoding, Unknown for elements without a BOM.
+// Elements are trimmed as they are pulled, without buffering nor allocating.
+func TrimSeq[E ~string | ~[]byte](seq iter.Seq[E]) iter.Seq2[E, Encoding] {
+	return func(yield func(E, Encoding) bool) {
+		for v := range seq {
+			if !yield(Trim(v)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestTrimSlice(t *testing.T) {
+	t.Parallel()
+
+	row := []string{"\ufeffName", "City", "\xfe\xffx", ""}
+
+	out, encs := utfbom.TrimSlice(row)
+	be.Equal(t, out, []string{"Name", "City", "x", ""})
+	be.Equal(t, encs, []utfbom.Encoding{utfbom.UTF8, utfbom.Unknown, utfbom.UTF16BigEndian, utfbom.Unknown})
+	be.Equal(t, row, out)
+
+	out, encs = utfbom.TrimSlice([]string(nil))
+	be.Equal(t, len(out), 0)
+	be.Equal(t, len(encs), 0)
+
+	b, benc := utfbom.TrimSlice([][]byte{[]byte("\ufeffab")})
+	be.Equal(t, b, [][]byte{[]byte("ab")})
+	be.Equal(t, benc, []utfbom.Encoding{utfbom.UTF8})
+}
+
+func TestTrimSlice_Allocations(t *testing.T) {
+	row := []string{"\ufeffName", "City", "Country"}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = utfbom.TrimSlice(row)
+	})
+	be.Equal(t, allocs, 1.0)
+}
+
+func TestTrimSeq(t *testing.T) {
+	t.Parallel()
+
+	seq := utfbom.TrimSeq(slices.Values([]string{"\ufeffa", "b", "\ufeffc"}))
+
+	var (
+		vals []string
+		encs []utfbom.Encoding
+	)
+
+	for v, enc := range seq {
+		vals = append(vals, v)
+		encs = append(encs, enc)
+	}
+
+	be.Equal(t, vals, []string{"a", "b", "c"})
+	be.Equal(t, encs, []utfbom.Encoding{utfbom.UTF8, utfbom.Unknown, utfbom.UTF8})
+
+	// stopping early
+	for v := range seq {
+		be.Equal(t, v, "a")
+
+		break
+	}
+
+	keys := maps.Collect(utfbom.TrimSeq(maps.Keys(map[string]int{"\ufeffid": 1})))
+	be.Equal(t, keys, map[string]utfbom.Encoding{"id": utfbom.UTF8})
+}
+
+func ExampleTrimSlice() {
+	header, encs := utfbom.TrimSlice([]string{"\ufeffName", "City"})
+
+	fmt.Printf("%q %v\n", header, encs)
+
+	// output:
+	// ["Name" "City"] [UTF8 Unknown]
+}
@@ -0,0 +1,147 @@
+package utfbom
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CleanStruct strips the leading UTF-8 BOM from every string reachable from v,
+// which must be a non-nil pointer, typically to a struct filled in by an unmarshaler.
+// BOMs smuggled in through CSV headers or concatenated files are invisible in logs
+// and make otherwise equal strings compare unequal.
+//
+// CleanStruct walks exported struct fields, nested structs, pointers, interfaces,
+// arrays, slices and map keys and values, following each pointer, slice and map once.
+// Byte slices, unexported fields and values of other kinds are left alone.
+// Map entries whose keys become equal after cleaning are merged, keeping one of the values.
+//
+// Only the UTF-8 BOM is stripped, as Go strings hold UTF-8 text and the BOMs of other encodings,
+// such as "+/v8" of UTF-7, read as ordinary characters there.
+// With the ScrubInterior option, interior U+FEFF characters are removed as well;
+// other options are ignored.
+func CleanStruct(v any, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Pointer {
+		return fmt.Errorf("utfbom.CleanStruct: non-pointer %T", v)
+	}
+
+	if rv.IsNil() {
+		return fmt.Errorf("utfbom.CleanStruct: nil %T", v)
+	}
+
+	c := cleaner{
+		scrub: newOptions(opts).scrub,
+		seen:  map[visit]bool{},
+	}
+
+	c.clean(rv)
+
+	return nil
+}
+
+// visit identifies a pointer, slice or map already walked by cleaner.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// cleaner walks values for CleanStruct.
+type cleaner struct {
+	scrub bool
+	seen  map[visit]bool
+}
+
+// clean cleans the strings reachable from v, which must be settable unless it holds no strings.
+func (c *cleaner) clean(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		s := c.cleanString(v.String())
+		if len(s) != v.Len() {
+			v.SetString(s)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() && c.visit(v, 0) {
+			c.clean(v.Elem())
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			v.Set(c.cleanCopy(v.Elem()))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				c.clean(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 || v.IsNil() || !c.visit(v, v.Len()) {
+			return
+		}
+
+		for i := range v.Len() {
+			c.clean(v.Index(i))
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			c.clean(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() || !c.visit(v, 0) {
+			return
+		}
+
+		// NaN keys can be neither looked up nor replaced,
+		// so the entries are cleaned first and the map is then refilled with them
+		type entry struct {
+			key, value reflect.Value
+		}
+
+		entries := make([]entry, 0, v.Len())
+
+		for it := v.MapRange(); it.Next(); {
+			entries = append(entries, entry{c.cleanCopy(it.Key()), c.cleanCopy(it.Value())})
+		}
+
+		v.Clear()
+
+		for _, e := range entries {
+			v.SetMapIndex(e.key, e.value)
+		}
+	default:
+		// numbers, byte slices, functions and channels hold no strings to clean
+	}
+}
+
+// cleanCopy returns a cleaned copy of v, for values that can't be set in place such as map entries.
+func (c *cleaner) cleanCopy(v reflect.Value) reflect.Value {
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	c.clean(cp)
+
+	return cp
+}
+
+// visit records v and reports whether it is seen for the first time.
+func (c *cleaner) visit(v reflect.Value, n int) bool {
+	key := visit{ptr: v.Pointer(), typ: v.Type(), len: n}
+	if c.seen[key] {
+		return false
+	}
+
+	c.seen[key] = true
+
+	return true
+}
+
+func (c *cleaner) cleanString(s string) string {
+	s = strings.TrimPrefix(s, zwnbsp)
+
+	if c.scrub && strings.Contains(s, zwnbsp) {
+		s = strings.ReplaceAll(s, zwnbsp, "")
+	}
+
+	return s
+}
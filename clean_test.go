@@ -0,0 +1,150 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+type cleanAddress struct {
+	City string
+}
+
+type cleanName string
+
+type cleanRecord struct {
+	ID       string
+	Name     cleanName
+	Tags     []string
+	Attrs    map[string]string
+	Address  cleanAddress
+	Previous *cleanAddress
+	Extra    any
+	Pair     [2]string
+	Raw      []byte
+	Count    int
+	hidden   string
+}
+
+func TestCleanStruct(t *testing.T) {
+	t.Parallel()
+
+	rec := cleanRecord{
+		ID:       "\ufeff42",
+		Name:     "\ufeffJürgen",
+		Tags:     []string{"\ufeffa", "b"},
+		Attrs:    map[string]string{"\ufeffcity": "\ufeffKöln", "zip": "50667"},
+		Address:  cleanAddress{City: "\ufeffKöln"},
+		Previous: &cleanAddress{City: "\ufeffBonn"},
+		Extra:    map[string]any{"list": []any{"\ufeffx", cleanAddress{City: "\ufeffy"}}},
+		Pair:     [2]string{"\ufeffl", "r\ufeff"},
+		Raw:      []byte("\ufeffraw"),
+		Count:    1,
+		hidden:   "\ufeffsecret",
+	}
+
+	err := utfbom.CleanStruct(&rec)
+	be.Err(t, err, nil)
+
+	be.Equal(t, rec, cleanRecord{
+		ID:       "42",
+		Name:     "Jürgen",
+		Tags:     []string{"a", "b"},
+		Attrs:    map[string]string{"city": "Köln", "zip": "50667"},
+		Address:  cleanAddress{City: "Köln"},
+		Previous: &cleanAddress{City: "Bonn"},
+		Extra:    map[string]any{"list": []any{"x", cleanAddress{City: "y"}}},
+		Pair:     [2]string{"l", "r\ufeff"},
+		Raw:      []byte("\ufeffraw"),
+		Count:    1,
+		hidden:   "\ufeffsecret",
+	})
+}
+
+func TestCleanStruct_ScrubInterior(t *testing.T) {
+	t.Parallel()
+
+	rows := [][]string{{"\ufeffa\ufeffb", "c\ufeff"}}
+
+	err := utfbom.CleanStruct(&rows, utfbom.ScrubInterior())
+	be.Err(t, err, nil)
+	be.Equal(t, rows, [][]string{{"ab", "c"}})
+}
+
+func TestCleanStruct_Cycles(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	n := &node{Name: "\ufeffa"}
+	n.Next = n
+
+	err := utfbom.CleanStruct(n)
+	be.Err(t, err, nil)
+	be.Equal(t, n.Name, "a")
+
+	m := map[string]any{"\ufeffk": "\ufeffv"}
+	m["self"] = m
+
+	err = utfbom.CleanStruct(&m)
+	be.Err(t, err, nil)
+	be.Equal(t, m["k"], any("v"))
+}
+
+func TestCleanStruct_NaNKeys(t *testing.T) {
+	t.Parallel()
+
+	m := map[float64]string{math.NaN(): "\ufeffa", math.NaN(): "\ufeffb", 1: "\ufeffc"}
+
+	err := utfbom.CleanStruct(&m)
+	be.Err(t, err, nil)
+	be.Equal(t, len(m), 3)
+	be.Equal(t, m[1], "c")
+
+	var nans []string
+
+	for k, v := range m {
+		if math.IsNaN(k) {
+			nans = append(nans, v)
+		}
+	}
+
+	slices.Sort(nans)
+	be.Equal(t, nans, []string{"a", "b"})
+}
+
+func TestCleanStruct_Errors(t *testing.T) {
+	t.Parallel()
+
+	err := utfbom.CleanStruct(cleanAddress{})
+	be.Err(t, err, "non-pointer utfbom_test.cleanAddress")
+
+	err = utfbom.CleanStruct((*cleanAddress)(nil))
+	be.Err(t, err, "nil *utfbom_test.cleanAddress")
+
+	err = utfbom.CleanStruct(nil)
+	be.Err(t, err, "non-pointer <nil>")
+}
+
+func ExampleCleanStruct() {
+	type row struct {
+		Name string
+		City string
+	}
+
+	r := row{Name: "\ufeffJürgen", City: "Köln"}
+
+	_ = utfbom.CleanStruct(&r)
+
+	fmt.Printf("%q\n", r.Name)
+
+	// output:
+	// "Jürgen"
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/slash3b/utfbom"
+)
+
+// headLen is the number of leading bytes read to tell whether a file would change, the length of the longest BOM.
+const headLen = 4
+
+// errDiffWithoutDryRun is returned when -diff is given without -dry-run.
+var errDiffWithoutDryRun = errors.New("-diff requires -dry-run")
+
+// dryRun tells whether the changes are only reported rather than made.
+type dryRun struct {
+	enabled bool
+	diff    bool
+}
+
+func (c *cli) dryRunFlags(fs *flag.FlagSet) *dryRun {
+	d := &dryRun{}
+	fs.BoolVar(&d.enabled, "dry-run", false, "report the files which would change without touching them")
+	fs.BoolVar(&d.diff, "diff", false, "with -dry-run, show the change of the first line of each file as a unified diff")
+
+	return d
+}
+
+func (d *dryRun) check() error {
+	if d.diff && !d.enabled {
+		return errDiffWithoutDryRun
+	}
+
+	return nil
+}
+
+// change computes the leading bytes of a file after the change from the leading bytes before it,
+// along with a description of the change. It returns a nil slice if the file would be left alone.
+// The leading bytes are either the first headLen bytes or the whole first line, which a BOM never spans.
+type change func(head []byte) ([]byte, string)
+
+// preview reports the change each named file would go through, without touching any, at most jobs files at once.
+// Reports and errors are written in the order of the files.
+func (c *cli) preview(args []string, list *fileList, jobs int, diff bool, ch change) int {
+	names, err := list.expand(files(args), c.stdin)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	outs := make([]string, len(names))
+	errs := make([]error, len(names))
+
+	parallel(len(names), jobs, func(i int) {
+		outs[i], errs[i] = c.previewOne(names[i], diff, ch)
+	})
+
+	status := exitOK
+
+	for i, name := range names {
+		if errs[i] != nil {
+			fmt.Fprintf(c.stderr, "utfbom: %s: %v\n", name, errs[i])
+
+			status = exitError
+
+			continue
+		}
+
+		fmt.Fprint(c.stdout, outs[i])
+	}
+
+	return status
+}
+
+// previewOne returns the report of the change the named file would go through, empty if none.
+func (c *cli) previewOne(name string, diff bool, ch change) (string, error) {
+	head, err := c.readHead(name, diff)
+	if err != nil {
+		return "", err
+	}
+
+	after, what := ch(head)
+	if after == nil {
+		return "", nil
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s: would %s\n", name, what)
+
+	if diff {
+		writeUnifiedDiff(&sb, name, head, after)
+	}
+
+	return sb.String(), nil
+}
+
+// readHead returns up to headLen leading bytes of the named file, or of standard input for "-",
+// or its whole first line, '\n' included, with line.
+func (c *cli) readHead(name string, line bool) ([]byte, error) {
+	rd := c.stdin
+
+	if name == "-" {
+		c.stdinMu.Lock()
+		defer c.stdinMu.Unlock()
+	} else {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+
+		defer f.Close()
+
+		rd = f
+	}
+
+	if line {
+		head, err := bufio.NewReader(rd).ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		return head, nil
+	}
+
+	head := make([]byte, headLen)
+
+	n, err := io.ReadFull(rd, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	return head[:n], nil
+}
+
+// writeUnifiedDiff writes the change of the first line of the named file from before to after as a unified diff,
+// which patch -p1 applies. Absolute paths lose their leading slash, so they are relative to the root, as in Git diffs.
+func writeUnifiedDiff(w io.Writer, name string, before, after []byte) {
+	p := strings.TrimPrefix(filepath.ToSlash(name), "/")
+
+	fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n@@ -%s +%s @@\n", p, p, hunkRange(before), hunkRange(after))
+	writeHunkLine(w, "-", before)
+	writeHunkLine(w, "+", after)
+}
+
+// hunkRange returns the range of a hunk made up of the first line only, or of nothing for an empty file.
+func hunkRange(line []byte) string {
+	if len(line) == 0 {
+		return "0,0"
+	}
+
+	return "1"
+}
+
+// writeHunkLine writes line prefixed with prefix, marking a line without a final newline as such.
+func writeHunkLine(w io.Writer, prefix string, line []byte) {
+	if len(line) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s", prefix, line)
+
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		fmt.Fprint(w, "\n\\ No newline at end of file\n")
+	}
+}
+
+// stripChange is the change made by strip.
+func stripChange(head []byte) ([]byte, string) {
+	enc := utfbom.DetectEncoding(head)
+	if enc == utfbom.Unknown {
+		return nil, ""
+	}
+
+	return head[enc.Len():], "strip " + enc.String() + " BOM"
+}
+
+// addChange returns the change made by add with the BOM of enc.
+func addChange(enc utfbom.Encoding) change {
+	return func(head []byte) ([]byte, string) {
+		if utfbom.DetectEncoding(head) != utfbom.Unknown {
+			return nil, ""
+		}
+
+		return slices.Concat(enc.Bytes(), head), "add " + enc.String() + " BOM"
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	bom := writeFile(t, dir, "bom.csv", "\ufeffa,b\n")
+	plain := writeFile(t, dir, "plain.csv", "a,b\n")
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdout string
+	}{
+		{"strip", []string{"strip", "-dry-run", bom, plain}, bom + ": would strip UTF8 BOM\n"},
+		{"add", []string{"add", "-enc", "UTF-16LE", "-dry-run", bom, plain}, plain + ": would add UTF16LittleEndian BOM\n"},
+		{
+			"strip diff",
+			[]string{"strip", "-dry-run", "-diff", bom},
+			bom + ": would strip UTF8 BOM\n" +
+				"--- a" + bom + "\n" +
+				"+++ b" + bom + "\n" +
+				"@@ -1 +1 @@\n" +
+				"-\ufeffa,b\n" +
+				"+a,b\n",
+		},
+		{
+			"add diff",
+			[]string{"add", "-dry-run", "-diff", plain},
+			plain + ": would add UTF8 BOM\n" +
+				"--- a" + plain + "\n" +
+				"+++ b" + plain + "\n" +
+				"@@ -1 +1 @@\n" +
+				"-a,b\n" +
+				"+\ufeffa,b\n",
+		},
+		{
+			"jobs",
+			[]string{"strip", "-dry-run", "-jobs", "1", plain, bom, plain, bom},
+			bom + ": would strip UTF8 BOM\n" + bom + ": would strip UTF8 BOM\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, stdout, stderr := runCLI(t, "", tc.args...)
+			be.Equal(t, stderr, "")
+			be.Equal(t, code, exitOK)
+			be.Equal(t, stdout, tc.stdout)
+		})
+	}
+
+	// files are left alone
+	be.Equal(t, readFile(t, bom), "\ufeffa,b\n")
+	be.Equal(t, readFile(t, plain), "a,b\n")
+}
+
+func TestDryRun_Diff(t *testing.T) {
+	t.Parallel()
+
+	// only the first line is part of the diff
+	code, stdout, _ := runCLI(t, "\ufeffa,b\nc,d\n", "strip", "-dry-run", "-diff")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "-: would strip UTF8 BOM\n"+
+		"--- a/-\n"+
+		"+++ b/-\n"+
+		"@@ -1 +1 @@\n"+
+		"-\ufeffa,b\n"+
+		"+a,b\n")
+
+	// a stream made up of the BOM only is emptied
+	code, stdout, _ = runCLI(t, "\ufeff", "strip", "-dry-run", "-diff", "-")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "-: would strip UTF8 BOM\n"+
+		"--- a/-\n"+
+		"+++ b/-\n"+
+		"@@ -1 +0,0 @@\n"+
+		"-\ufeff\n"+
+		"\\ No newline at end of file\n")
+
+	code, stdout, _ = runCLI(t, "", "add", "-dry-run", "-diff")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "-: would add UTF8 BOM\n"+
+		"--- a/-\n"+
+		"+++ b/-\n"+
+		"@@ -0,0 +1 @@\n"+
+		"+\ufeff\n"+
+		"\\ No newline at end of file\n")
+}
+
+func TestDryRun_Errors(t *testing.T) {
+	t.Parallel()
+
+	code, _, stderr := runCLI(t, "", "strip", "-diff")
+	be.Equal(t, code, exitError)
+	be.Equal(t, stderr, "utfbom: -diff requires -dry-run\n")
+
+	code, _, stderr = runCLI(t, "", "add", "-dry-run", "missing.csv")
+	be.Equal(t, code, exitError)
+	be.True(t, strings.Contains(stderr, "missing.csv"))
+}
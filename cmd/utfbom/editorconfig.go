@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/slash3b/utfbom"
+)
+
+// charsetBOMs maps the charset values of .editorconfig to the BOM the files must start with, Unknown for none.
+// Files with other values, or none, are not checked.
+var charsetBOMs = map[string]utfbom.Encoding{
+	"latin1":    utfbom.Unknown,
+	"utf-8":     utfbom.Unknown,
+	"utf-8-bom": utfbom.UTF8,
+	"utf-16be":  utfbom.UTF16BigEndian,
+	"utf-16le":  utfbom.UTF16LittleEndian,
+}
+
+// editorConfig looks up the charset declared for files by .editorconfig files,
+// following https://spec.editorconfig.org: the files are read from the directory of a file up to
+// the one declaring root = true, nearer files and later sections taking precedence.
+//
+// editorConfig is not safe for concurrent use.
+type editorConfig struct {
+	dirs map[string]*ecFile // parsed .editorconfig by directory, nil if there is none
+}
+
+// ecFile is a parsed .editorconfig file.
+type ecFile struct {
+	dir      string
+	root     bool
+	sections []ecSection
+}
+
+// ecSection is a section of an .editorconfig file setting the charset.
+type ecSection struct {
+	glob    *regexp.Regexp
+	ranges  []numRange // numeric ranges of the glob, matched by the groups of the expression
+	charset string
+}
+
+// numRange is a {num1..num2} glob.
+type numRange struct {
+	lo, hi int
+}
+
+func newEditorConfig() *editorConfig {
+	return &editorConfig{dirs: map[string]*ecFile{}}
+}
+
+// expectation returns the BOM the named file must start with according to its charset.
+func (ec *editorConfig) expectation(name string) (expectation, error) {
+	charset, err := ec.charset(name)
+	if err != nil {
+		return expectation{}, err
+	}
+
+	enc, ok := charsetBOMs[charset]
+	if !ok {
+		return expectation{}, nil
+	}
+
+	return expectation{
+		checked: true,
+		bom:     enc != utfbom.Unknown,
+		enc:     enc,
+		why:     " (charset " + charset + ")",
+	}, nil
+}
+
+// charset returns the charset declared for the named file, the empty string if there is none.
+func (ec *editorConfig) charset(name string) (string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+
+	var chain []*ecFile
+
+	for dir := filepath.Dir(abs); ; {
+		f, err := ec.load(dir)
+		if err != nil {
+			return "", err
+		}
+
+		if f != nil {
+			chain = append(chain, f)
+
+			if f.root {
+				break
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	charset := ""
+
+	// the farthest file is applied first, so that nearer ones override it
+	for i := len(chain) - 1; i >= 0; i-- {
+		f := chain[i]
+
+		rel, err := filepath.Rel(f.dir, abs)
+		if err != nil {
+			return "", err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		for _, sec := range f.sections {
+			if sec.match(rel) {
+				charset = sec.charset
+			}
+		}
+	}
+
+	if charset == "unset" {
+		charset = ""
+	}
+
+	return charset, nil
+}
+
+// load returns the .editorconfig file of dir, nil if there is none.
+func (ec *editorConfig) load(dir string) (*ecFile, error) {
+	if f, ok := ec.dirs[dir]; ok {
+		return f, nil
+	}
+
+	f, err := parseEditorConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ec.dirs[dir] = f
+
+	return f, nil
+}
+
+// parseEditorConfig parses the .editorconfig file of dir, returning nil if there is none.
+// Malformed lines and globs are skipped, as editors do.
+func parseEditorConfig(dir string) (*ecFile, error) {
+	file, err := os.Open(filepath.Join(dir, ".editorconfig"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	f := &ecFile{dir: dir}
+
+	var (
+		cur       *ecSection
+		inSection bool
+	)
+
+	sc := bufio.NewScanner(utfbom.NewReader(file))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			inSection = true
+			cur = nil
+
+			re, ranges, err := compileGlob(line[1 : len(line)-1])
+			if err == nil {
+				f.sections = append(f.sections, ecSection{glob: re, ranges: ranges})
+				cur = &f.sections[len(f.sections)-1]
+			}
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.ToLower(strings.TrimSpace(value))
+
+			switch {
+			case !inSection && key == "root":
+				f.root = value == "true"
+			case cur != nil && key == "charset":
+				cur.charset = value
+			default:
+			}
+		}
+	}
+
+	err = sc.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// sections not setting the charset leave it alone
+	sections := f.sections[:0]
+
+	for _, sec := range f.sections {
+		if sec.charset != "" {
+			sections = append(sections, sec)
+		}
+	}
+
+	f.sections = sections
+
+	return f, nil
+}
+
+// match reports whether the glob of the section matches the slash-separated path relative to its file.
+func (s ecSection) match(rel string) bool {
+	m := s.glob.FindStringSubmatch(rel)
+	if m == nil {
+		return false
+	}
+
+	for i, r := range s.ranges {
+		// the group of a range in an alternative that didn't match is empty
+		if m[i+1] == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil || n < r.lo || n > r.hi {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compileGlob translates an .editorconfig section glob into a regular expression.
+// Globs without a slash match files at any depth, others are relative to the directory of the .editorconfig file.
+func compileGlob(glob string) (*regexp.Regexp, []numRange, error) {
+	prefix := ""
+
+	switch {
+	case strings.HasPrefix(glob, "/"):
+		glob = glob[1:]
+	case !strings.Contains(glob, "/"):
+		prefix = "(?:.*/)?"
+	default:
+	}
+
+	expr, ranges := translateGlob(glob)
+
+	re, err := regexp.Compile("^" + prefix + expr + "$")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return re, ranges, nil
+}
+
+var numRangeRe = regexp.MustCompile(`^([+-]?[0-9]+)\.\.([+-]?[0-9]+)$`)
+
+// translateGlob translates glob into a regular expression, numeric ranges becoming capturing groups.
+func translateGlob(glob string) (string, []numRange) {
+	var (
+		sb     strings.Builder
+		ranges []numRange
+	)
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+
+				continue
+			}
+
+			class := glob[i+1 : i+1+end]
+			i += 1 + end
+
+			sb.WriteByte('[')
+
+			if strings.HasPrefix(class, "!") {
+				sb.WriteByte('^')
+				class = class[1:]
+			}
+
+			for _, r := range class {
+				if r == '-' {
+					sb.WriteRune(r)
+				} else {
+					sb.WriteString(regexp.QuoteMeta(string(r)))
+				}
+			}
+
+			sb.WriteByte(']')
+		case '{':
+			end := closingBrace(glob, i)
+			if end < 0 {
+				sb.WriteString(`\{`)
+
+				continue
+			}
+
+			inner := glob[i+1 : end]
+			i = end
+
+			if m := numRangeRe.FindStringSubmatch(inner); m != nil {
+				lo, _ := strconv.Atoi(m[1])
+				hi, _ := strconv.Atoi(m[2])
+				ranges = append(ranges, numRange{lo: min(lo, hi), hi: max(lo, hi)})
+
+				sb.WriteString("([+-]?[0-9]+)")
+
+				continue
+			}
+
+			alts := splitAlternatives(inner)
+			if len(alts) < 2 {
+				// a single choice is literal
+				expr, rs := translateGlob(inner)
+				ranges = append(ranges, rs...)
+
+				sb.WriteString(`\{` + expr + `\}`)
+
+				continue
+			}
+
+			sb.WriteString("(?:")
+
+			for j, alt := range alts {
+				if j > 0 {
+					sb.WriteByte('|')
+				}
+
+				expr, rs := translateGlob(alt)
+				ranges = append(ranges, rs...)
+
+				sb.WriteString(expr)
+			}
+
+			sb.WriteByte(')')
+		default:
+			// a byte slice rather than string(c), which would encode the byte of a multi-byte character as a rune
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+
+	return sb.String(), ranges
+}
+
+// closingBrace returns the index of the brace closing the one at start, -1 if there is none.
+func closingBrace(glob string, start int) int {
+	depth := 0
+
+	for i := start; i < len(glob); i++ {
+		switch glob[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		default:
+		}
+	}
+
+	return -1
+}
+
+// splitAlternatives splits the contents of braces at the commas outside nested braces.
+func splitAlternatives(s string) []string {
+	var (
+		alts  []string
+		depth int
+		start int
+	)
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, s[start:i])
+				start = i + 1
+			}
+		default:
+		}
+	}
+
+	return append(alts, s[start:])
+}
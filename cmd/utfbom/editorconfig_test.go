@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nalgeon/be"
+)
+
+func TestCompileGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*", "a.csv", true},
+		{"*", "sub/a.csv", true},
+		{"*.csv", "sub/deep/a.csv", true},
+		{"*.csv", "a.txt", false},
+		{"sub/*.csv", "sub/a.csv", true},
+		{"sub/*.csv", "other/sub/a.csv", false},
+		{"sub/*.csv", "sub/deep/a.csv", false},
+		{"/a.csv", "a.csv", true},
+		{"/a.csv", "sub/a.csv", false},
+		{"sub/**.csv", "sub/deep/a.csv", true},
+		{"a?.csv", "ab.csv", true},
+		{"a?.csv", "a/.csv", false},
+		{"[ab].csv", "b.csv", true},
+		{"[!ab].csv", "b.csv", false},
+		{"[a-c].csv", "c.csv", true},
+		{"*.{csv,tsv}", "a.tsv", true},
+		{"*.{csv,tsv}", "a.txt", false},
+		{"{a,{b,c}}.csv", "c.csv", true},
+		{"{single}.csv", "{single}.csv", true},
+		{"file{1..3}.csv", "file2.csv", true},
+		{"file{1..3}.csv", "file4.csv", false},
+		{"file{3..1}.csv", "file1.csv", true},
+		{`\*.csv`, "*.csv", true},
+		{`\*.csv`, "a.csv", false},
+		{"[unclosed.csv", "[unclosed.csv", true},
+		{"{unclosed.csv", "{unclosed.csv", true},
+		{"a+b(c).csv", "a+b(c).csv", true},
+		{"{a{1..3},b}.csv", "b.csv", true},
+		{"{a{1..3},b}.csv", "a2.csv", true},
+		{"{a{1..3},b}.csv", "a4.csv", false},
+		{"données/*.csv", "données/a.csv", true},
+		{"*.csv", "café.csv", true},
+		{`\é.csv`, "é.csv", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.glob+" "+tc.path, func(t *testing.T) {
+			t.Parallel()
+
+			re, ranges, err := compileGlob(tc.glob)
+			be.Err(t, err, nil)
+			be.Equal(t, ecSection{glob: re, ranges: ranges}.match(tc.path), tc.match)
+		})
+	}
+}
+
+func TestEditorConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, ".editorconfig", "\ufeff# top-most\nroot = true\n\n"+
+		"[*]\ncharset = utf-8\n\n"+
+		"[*.csv]\nCharset = UTF-8-BOM\n\n"+
+		"[legacy/**]\ncharset = latin1\n"+
+		"[*.log]\nindent_style = tab\n")
+
+	be.Err(t, os.MkdirAll(filepath.Join(dir, "sub", "deep"), 0o750), nil)
+	writeFile(t, dir, filepath.Join("sub", ".editorconfig"), "[*.csv]\ncharset = utf-16le\n[deep/*]\ncharset = unset\n")
+
+	tests := []struct {
+		name    string
+		charset string
+	}{
+		{"a.txt", "utf-8"},
+		{"a.csv", "utf-8-bom"},
+		{"a.log", "utf-8"},
+		{"legacy/a.csv", "latin1"},
+		{"sub/a.csv", "utf-16le"},
+		{"sub/a.txt", "utf-8"},
+		{"sub/deep/a.txt", ""},
+	}
+
+	ec := newEditorConfig()
+
+	for _, tc := range tests {
+		charset, err := ec.charset(filepath.Join(dir, filepath.FromSlash(tc.name)))
+		be.Err(t, err, nil)
+		be.Equal(t, charset, tc.charset)
+	}
+}
+
+func TestVerify_EditorConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, ".editorconfig", "root = true\n[*.txt]\ncharset = utf-8\n[*.csv]\ncharset = utf-8-bom\n[*.tsv]\ncharset = utf-16le\n")
+	writeFile(t, dir, "ok.txt", "plain")
+	writeFile(t, dir, "ok.csv", "\ufeffa,b")
+	writeFile(t, dir, "ok.tsv", "\xff\xfea\x00")
+	writeFile(t, dir, "other.md", "\ufeffany")
+	bad := writeFile(t, dir, "bad.txt", "\ufeffplain")
+	missing := writeFile(t, dir, "missing.csv", "a,b")
+	mismatch := writeFile(t, dir, "mismatch.tsv", "\ufeffa")
+
+	code, stdout, stderr := runCLI(t, "", "verify", "-policy", "editorconfig", dir)
+	be.Equal(t, stderr, "")
+	be.Equal(t, code, exitFound)
+	be.Equal(t, stdout, ""+
+		filepath.ToSlash(bad)+":0: file starts with a UTF8 BOM (charset utf-8)\n"+
+		filepath.ToSlash(mismatch)+":0: file starts with a UTF8 BOM instead of a UTF16LittleEndian BOM (charset utf-16le)\n"+
+		filepath.ToSlash(missing)+":0: file does not start with a UTF8 BOM (charset utf-8-bom)\n")
+}
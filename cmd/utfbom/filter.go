@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/slash3b/utfbom"
+)
+
+// clean is the clean command of a Git filter driver, it strips the BOM on the way into the repository.
+func (c *cli) clean(args []string) int {
+	fs := c.flagSet("clean")
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	return c.filter(stripBOM)
+}
+
+// smudge is the smudge command of a Git filter driver, it adds the BOM back on checkout if asked to.
+func (c *cli) smudge(args []string) int {
+	fs := c.flagSet("smudge")
+	enc := utfbom.Unknown
+	fs.Var(&enc, "enc", "`encoding` of the BOM to add, none by default")
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	return c.filter(func(dst io.Writer, src io.Reader) error {
+		return smudgeBOM(dst, src, enc)
+	})
+}
+
+// filter streams standard input through stream to standard output, the way Git runs filter drivers.
+func (c *cli) filter(stream func(dst io.Writer, src io.Reader) error) int {
+	err := stream(c.stdout, c.stdin)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	return exitOK
+}
+
+// filterProcess runs the long-running filter protocol of Git, see gitattributes(5),
+// so that a single process cleans and smudges all the files of a commit or checkout.
+func (c *cli) filterProcess(args []string) int {
+	fs := c.flagSet("filter-process")
+	enc := utfbom.Unknown
+	fs.Var(&enc, "enc", "`encoding` of the BOM smudge adds, none by default")
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	p := &pktConn{
+		r: bufio.NewReader(c.stdin),
+		w: bufio.NewWriter(c.stdout),
+	}
+
+	err := p.serve(enc)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: filter-process: %v\n", err)
+
+		return exitError
+	}
+
+	return exitOK
+}
+
+// smudgeBOM copies src to dst prefixed with the BOM of enc, if any,
+// unless src is empty or already starts with that BOM.
+func smudgeBOM(dst io.Writer, src io.Reader, enc utfbom.Encoding) error {
+	_, err := io.Copy(utfbom.NewWriter(dst, enc, utfbom.DedupBOM()), src)
+
+	return err
+}
+
+const (
+	// pktMaxData is the largest payload of a pkt-line packet.
+	pktMaxData = 65516
+	// pktHeaderLen is the length of the hexadecimal packet length prefix, which counts itself.
+	pktHeaderLen = 4
+)
+
+// pktConn exchanges pkt-line packets with Git, see gitprotocol-common(5).
+type pktConn struct {
+	r   *bufio.Reader
+	w   *bufio.Writer
+	buf [pktMaxData]byte
+}
+
+// serve answers the handshake of Git and then filters files until Git closes the connection.
+func (p *pktConn) serve(enc utfbom.Encoding) error {
+	hello, err := p.readList()
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(hello, "git-filter-client") || !slices.Contains(hello, "version=2") {
+		return fmt.Errorf("unsupported handshake %q", hello)
+	}
+
+	err = p.writeList("git-filter-server", "version=2")
+	if err != nil {
+		return err
+	}
+
+	caps, err := p.readList()
+	if err != nil {
+		return err
+	}
+
+	var supported []string
+
+	for _, c := range []string{"capability=clean", "capability=smudge"} {
+		if slices.Contains(caps, c) {
+			supported = append(supported, c)
+		}
+	}
+
+	err = p.writeList(supported...)
+	if err != nil {
+		return err
+	}
+
+	for {
+		err = p.filterOne(enc)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// filterOne filters a single file, reporting a failure to Git rather than giving up.
+// It returns io.EOF once Git has no more files to filter.
+func (p *pktConn) filterOne(enc utfbom.Encoding) error {
+	req, err := p.readList()
+	if err != nil {
+		return err
+	}
+
+	var stream func(dst io.Writer, src io.Reader) error
+
+	switch command, _ := lookupKey(req, "command"); command {
+	case "clean":
+		stream = stripBOM
+	case "smudge":
+		stream = func(dst io.Writer, src io.Reader) error {
+			return smudgeBOM(dst, src, enc)
+		}
+	default:
+		stream = nil
+	}
+
+	content := &pktReader{p: p}
+
+	if stream == nil {
+		_, err = io.Copy(io.Discard, content)
+		if err != nil {
+			return err
+		}
+
+		return p.writeList("status=error")
+	}
+
+	// Git sends the whole file before reading the response, so the response is only written
+	// once the content is read in full, else both ends block on full pipes for large files
+	in, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+
+	err = stream(&out, bytes.NewReader(in))
+	if err != nil {
+		return p.writeList("status=error")
+	}
+
+	err = p.writeList("status=success")
+	if err != nil {
+		return err
+	}
+
+	_, err = out.WriteTo(&pktWriter{p: p})
+	if err != nil {
+		return err
+	}
+
+	err = p.writeFlush()
+	if err != nil {
+		return err
+	}
+
+	// an empty list keeps the status sent before the content
+	return p.writeList()
+}
+
+// lookupKey returns the value of key in a list of key=value lines.
+func lookupKey(lines []string, key string) (string, bool) {
+	for _, l := range lines {
+		if v, ok := strings.CutPrefix(l, key+"="); ok {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// readPacket returns the payload of the next packet, nil for a flush packet.
+// The payload is valid until the next call.
+func (p *pktConn) readPacket() ([]byte, error) {
+	var head [pktHeaderLen]byte
+
+	_, err := io.ReadFull(p.r, head[:])
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.ParseUint(string(head[:]), 16, 16)
+	if err != nil || n != 0 && (n < pktHeaderLen || n > pktHeaderLen+pktMaxData) {
+		return nil, fmt.Errorf("malformed packet length %q", head[:])
+	}
+
+	if n == 0 {
+		return nil, nil
+	}
+
+	b := p.buf[:n-pktHeaderLen]
+
+	_, err = io.ReadFull(p.r, b)
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return b, nil
+}
+
+// readList reads text packets up to the next flush packet.
+func (p *pktConn) readList() ([]string, error) {
+	var lines []string
+
+	for {
+		b, err := p.readPacket()
+		if errors.Is(err, io.EOF) && len(lines) != 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if b == nil {
+			return lines, nil
+		}
+
+		lines = append(lines, strings.TrimSuffix(string(b), "\n"))
+	}
+}
+
+// writeList writes text packets followed by a flush packet and sends them to Git.
+func (p *pktConn) writeList(lines ...string) error {
+	for _, l := range lines {
+		fmt.Fprintf(p.w, "%04x%s\n", len(l)+1+pktHeaderLen, l)
+	}
+
+	return p.writeFlush()
+}
+
+// writeFlush writes a flush packet and sends everything written so far to Git.
+func (p *pktConn) writeFlush() error {
+	_, _ = p.w.WriteString("0000")
+
+	return p.w.Flush()
+}
+
+// pktReader reads file content sent by Git as data packets up to the next flush packet.
+type pktReader struct {
+	p    *pktConn
+	rest []byte
+	done bool
+}
+
+// Read implements the io.Reader interface.
+func (r *pktReader) Read(b []byte) (int, error) {
+	for len(r.rest) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		pkt, err := r.p.readPacket()
+		if errors.Is(err, io.EOF) {
+			return 0, io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		r.rest, r.done = pkt, pkt == nil
+	}
+
+	n := copy(b, r.rest)
+	r.rest = r.rest[n:]
+
+	return n, nil
+}
+
+// pktWriter writes file content to Git as data packets.
+type pktWriter struct {
+	p *pktConn
+}
+
+// Write implements the io.Writer interface.
+func (w *pktWriter) Write(b []byte) (int, error) {
+	written := 0
+
+	for len(b) != 0 {
+		chunk := b[:min(len(b), pktMaxData)]
+
+		_, err := fmt.Fprintf(w.p.w, "%04x", len(chunk)+pktHeaderLen)
+		if err != nil {
+			return written, err
+		}
+
+		n, err := w.p.w.Write(chunk)
+		written += n
+
+		if err != nil {
+			return written, err
+		}
+
+		b = b[n:]
+	}
+
+	return written, nil
+}
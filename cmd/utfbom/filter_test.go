@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+// pkt encodes lines as pkt-line packets the way Git does, "" stands for a flush packet.
+func pkt(lines ...string) string {
+	var b strings.Builder
+
+	for _, l := range lines {
+		if l == "" {
+			b.WriteString("0000")
+
+			continue
+		}
+
+		fmt.Fprintf(&b, "%04x%s", len(l)+4, l)
+	}
+
+	return b.String()
+}
+
+// unpkt decodes pkt-line packets, merging consecutive ones, so that tests don't depend on
+// how content is split into packets. Flush packets are returned as "".
+func unpkt(t *testing.T, s string) []string {
+	t.Helper()
+
+	var (
+		out  []string
+		data bool
+	)
+
+	for s != "" {
+		n, err := strconv.ParseUint(s[:4], 16, 16)
+		be.Err(t, err, nil)
+
+		if n == 0 {
+			out = append(out, "")
+			data = false
+			s = s[4:]
+
+			continue
+		}
+
+		be.True(t, n > 4 && n <= 4+pktMaxData)
+
+		if data {
+			out[len(out)-1] += s[4:n]
+		} else {
+			out = append(out, s[4:n])
+		}
+
+		data = true
+		s = s[n:]
+	}
+
+	return out
+}
+
+func TestCleanSmudge(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, _ := runCLI(t, "\ufeffa,b\n", "clean")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "a,b\n")
+
+	code, stdout, _ = runCLI(t, "a,b\n", "smudge")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "a,b\n")
+
+	code, stdout, _ = runCLI(t, "a,b\n", "smudge", "-enc", "UTF-8")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "\ufeffa,b\n")
+
+	code, stdout, _ = runCLI(t, "\ufeffa,b\n", "smudge", "-enc", "UTF-8")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "\ufeffa,b\n")
+
+	code, stdout, _ = runCLI(t, "", "smudge", "-enc", "UTF-8")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "")
+}
+
+func TestFilterProcess(t *testing.T) {
+	t.Parallel()
+
+	large := strings.Repeat("x", 70000)
+
+	stdin := pkt("git-filter-client\n", "version=2\n", "",
+		"capability=clean\n", "capability=smudge\n", "capability=delay\n", "",
+		"command=clean\n", "pathname=a.csv\n", "", "\ufeffa,", "b\n", "",
+		"command=smudge\n", "pathname=a.csv\n", "", "a,b\n", "",
+		"command=smudge\n", "pathname=empty.csv\n", "", "",
+		"command=clean\n", "pathname=large.csv\n", "", "\ufeff"+large[:60000], large[60000:], "",
+		"command=frobnicate\n", "pathname=a.csv\n", "", "data", "",
+	)
+
+	code, stdout, stderr := runCLI(t, stdin, "filter-process", "-enc", "UTF-8")
+	be.Equal(t, stderr, "")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, unpkt(t, stdout), unpkt(t, pkt("git-filter-server\n", "version=2\n", "",
+		"capability=clean\n", "capability=smudge\n", "",
+		"status=success\n", "", "a,b\n", "", "",
+		"status=success\n", "", "\ufeffa,b\n", "", "",
+		"status=success\n", "", "", "",
+		"status=success\n", "", large[:pktMaxData], large[pktMaxData:], "", "",
+		"status=error\n", "",
+	)))
+}
+
+func TestFilterProcess_Errors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		stdin string
+		err   string
+	}{
+		{"handshake", pkt("git-filter-client\n", "version=3\n", ""), `unsupported handshake ["git-filter-client" "version=3"]`},
+		{"truncated", pkt("git-filter-client\n", "version=2\n"), "unexpected EOF"},
+		{"malformed", "00zzgit", `malformed packet length "00zz"`},
+		{"short_packet", "0002", `malformed packet length "0002"`},
+		{
+			"truncated_content",
+			pkt("git-filter-client\n", "version=2\n", "", "capability=clean\n", "", "command=clean\n", "") + "0010abc",
+			"unexpected EOF",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, _, stderr := runCLI(t, tc.stdin, "filter-process")
+			be.Equal(t, code, exitError)
+			be.Equal(t, stderr, "utfbom: filter-process: "+tc.err+"\n")
+		})
+	}
+}
+
+// TestFilterProcess_Pipes talks to the filter over pipes the way Git does, writing the whole file
+// before reading the response, which deadlocks if the filter answers while the file is still coming.
+func TestFilterProcess_Pipes(t *testing.T) {
+	t.Parallel()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	go func() {
+		run(t.Context(), []string{"filter-process"}, inR, outW, io.Discard)
+		outW.Close()
+	}()
+
+	large := strings.Repeat("x", 2<<20)
+
+	req := []string{"command=clean\n", "pathname=large.csv\n", ""}
+	for chunk := "\ufeff" + large; chunk != ""; chunk = chunk[min(len(chunk), pktMaxData):] {
+		req = append(req, chunk[:min(len(chunk), pktMaxData)])
+	}
+
+	req = append(req, "")
+
+	type result struct {
+		out string
+		err error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		// the handshake goes step by step, as each side waits for the other one
+		var err error
+
+		for _, step := range [][2]string{
+			{pkt("git-filter-client\n", "version=2\n", ""), pkt("git-filter-server\n", "version=2\n", "")},
+			{pkt("capability=clean\n", ""), pkt("capability=clean\n", "")},
+		} {
+			if err == nil {
+				_, err = io.WriteString(inW, step[0])
+			}
+
+			if err == nil {
+				_, err = io.ReadFull(outR, make([]byte, len(step[1])))
+			}
+		}
+
+		if err == nil {
+			_, err = io.WriteString(inW, pkt(req...))
+		}
+
+		inW.Close()
+
+		out, rerr := io.ReadAll(outR)
+
+		done <- result{string(out), errors.Join(err, rerr)}
+	}()
+
+	select {
+	case res := <-done:
+		be.Err(t, res.err, nil)
+		be.Equal(t, unpkt(t, res.out), []string{"status=success\n", "", large, "", ""})
+	case <-time.After(10 * time.Second):
+		// unblock both ends before failing
+		inW.Close()
+		outR.Close()
+		t.Fatal("filter-process deadlocked")
+	}
+}
@@ -0,0 +1,463 @@
+// Command utfbom detects, strips and adds Unicode Byte Order Marks.
+//
+// Usage:
+//
+//	utfbom detect [-fail] [-list|-null] [-jobs n] [file ...]
+//	utfbom strip [-list|-null] [-jobs n] [-dry-run [-diff]] [file ...]
+//	utfbom add [-enc encoding] [-list|-null] [-jobs n] [-dry-run [-diff]] [file ...]
+//	utfbom verify [-format text|json|sarif] [-policy forbid|expect|editorconfig] [-stray] [-jobs n] [path ...]
+//	utfbom stats [-format text|json] [-top n] [-jobs n] [path ...]
+//	utfbom clean
+//	utfbom smudge [-enc encoding]
+//	utfbom filter-process [-enc encoding]
+//	utfbom watch [-strip] [-pattern glob] [-settle duration] dir
+//
+// With no files, or when a file is "-", standard input is processed
+// and the result is written to standard output. Named files are rewritten in place.
+// With -list or -null, "-" stands for the names of the files to process, read from standard input
+// one per line or separated by NUL bytes, so that the output of git ls-files -z or find -print0 can be piped in:
+//
+//	git ls-files -z '*.csv' | utfbom strip -null -
+//
+// With -dry-run, strip and add only report the files they would change, leaving them alone.
+// Adding -diff shows the change of the first line of each such file as a unified diff, which patch -p1 applies.
+//
+// Files are processed in parallel, -jobs at once, the number of CPUs by default;
+// the output follows the order of the files regardless.
+//
+// The verify command checks files and directory trees, the current directory by default,
+// and reports the files violating the BOM policy, in SARIF for code scanning tools to annotate them.
+// The editorconfig policy takes the charset property of .editorconfig files as the policy of each file:
+// utf-8-bom, utf-16be and utf-16le require the matching BOM, utf-8 and latin1 forbid any.
+// With -stray, verify also reads the files whole and reports every U+FEFF past their beginning
+// with its byte offset and line, such as the BOMs left in the middle of concatenated files.
+// The stats command counts the files and bytes of such trees by BOM encoding and by extension
+// and lists the largest files having a BOM, to size up a migration.
+//
+// The clean and smudge commands filter standard input to standard output for use as a Git filter driver:
+// clean strips the BOM when files are committed, smudge adds the BOM of the given encoding back on checkout.
+// filter-process does both in a single process speaking the long-running filter protocol of Git:
+//
+//	git config filter.utfbom.process "utfbom filter-process -enc UTF-8"
+//	echo '*.csv filter=utfbom' >> .gitattributes
+//
+// The watch command monitors a directory, without its subdirectories, until interrupted,
+// and reports or strips the BOM of the files matching the pattern as they are created or modified:
+//
+//	utfbom watch -strip -pattern '*.csv' exports
+//
+// Exit status is 0 on success, 1 if detect -fail found a BOM or verify found a violation, and 2 on errors.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/slash3b/utfbom"
+)
+
+const (
+	exitOK    = 0
+	exitFound = 1
+	exitError = 2
+)
+
+const usage = `usage:
+	utfbom detect [-fail] [-list|-null] [-jobs n] [file ...]
+	utfbom strip [-list|-null] [-jobs n] [-dry-run [-diff]] [file ...]
+	utfbom add [-enc encoding] [-list|-null] [-jobs n] [-dry-run [-diff]] [file ...]
+	utfbom verify [-format text|json|sarif] [-policy forbid|expect|editorconfig] [-stray] [-jobs n] [path ...]
+	utfbom stats [-format text|json] [-top n] [-jobs n] [path ...]
+	utfbom clean
+	utfbom smudge [-enc encoding]
+	utfbom filter-process [-enc encoding]
+	utfbom watch [-strip] [-pattern glob] [-settle duration] dir
+
+With no files, or when a file is "-", standard input is processed
+and the result is written to standard output. Named files are rewritten in place.
+With -list or -null, "-" stands for file names read from standard input, one per line or NUL-separated.
+With -dry-run, strip and add report the changes, shown as a unified diff with -diff, without making them.
+Files are processed -jobs at once, with the output in the order of the files.
+Verify reports files and directory trees violating the BOM policy.
+Stats counts files and bytes by BOM encoding and extension and lists the largest files with a BOM.
+Clean, smudge and filter-process filter standard input to standard output as a Git filter driver.
+Watch reports or strips BOMs of files created or modified in a directory until interrupted.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(code)
+}
+
+type cli struct {
+	ctx    context.Context // canceled on interrupt, ends watch
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// stdinMu serializes the files named "-" processed in parallel
+	stdinMu sync.Mutex
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+
+		return exitError
+	}
+
+	switch args[0] {
+	case "detect":
+		return c.detect(args[1:])
+	case "strip":
+		return c.strip(args[1:])
+	case "add":
+		return c.add(args[1:])
+	case "verify":
+		return c.verify(args[1:])
+	case "stats":
+		return c.stats(args[1:])
+	case "clean":
+		return c.clean(args[1:])
+	case "smudge":
+		return c.smudge(args[1:])
+	case "filter-process":
+		return c.filterProcess(args[1:])
+	case "watch":
+		return c.watch(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "utfbom: unknown command %q\n", args[0])
+		fmt.Fprint(stderr, usage)
+
+		return exitError
+	}
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	return fs
+}
+
+func (c *cli) parse(fs *flag.FlagSet, args []string) (int, bool) {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK, false
+	}
+
+	if err != nil {
+		return exitError, false
+	}
+
+	return exitOK, true
+}
+
+func (c *cli) detect(args []string) int {
+	fs := c.flagSet("detect")
+	fail := fs.Bool("fail", false, "exit with status 1 if any file has a BOM")
+	list := c.listFlags(fs)
+	jobs := c.jobsFlag(fs)
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	names, err := list.expand(files(fs.Args()), c.stdin)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	encs := make([]utfbom.Encoding, len(names))
+	errs := make([]error, len(names))
+
+	parallel(len(names), *jobs, func(i int) {
+		encs[i], errs[i] = c.detectOne(names[i])
+	})
+
+	status, found := exitOK, false
+
+	for i, name := range names {
+		if errs[i] != nil {
+			fmt.Fprintf(c.stderr, "utfbom: %v\n", errs[i])
+
+			status = exitError
+
+			continue
+		}
+
+		found = found || encs[i] != utfbom.Unknown
+
+		fmt.Fprintf(c.stdout, "%s: %s\n", name, encs[i])
+	}
+
+	if status == exitOK && *fail && found {
+		return exitFound
+	}
+
+	return status
+}
+
+func (c *cli) detectOne(name string) (utfbom.Encoding, error) {
+	if name == "-" {
+		c.stdinMu.Lock()
+		defer c.stdinMu.Unlock()
+
+		_, enc, err := utfbom.Skip(c.stdin)
+
+		return enc, err
+	}
+
+	return utfbom.DetectFile(name)
+}
+
+func (c *cli) strip(args []string) int {
+	fs := c.flagSet("strip")
+	list := c.listFlags(fs)
+	dry := c.dryRunFlags(fs)
+	jobs := c.jobsFlag(fs)
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	err := dry.check()
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	if dry.enabled {
+		return c.preview(fs.Args(), list, *jobs, dry.diff, stripChange)
+	}
+
+	return c.each(fs.Args(), list, *jobs, stripBOM, func(name string) error {
+		_, err := utfbom.TrimFile(name)
+
+		return err
+	})
+}
+
+func (c *cli) add(args []string) int {
+	fs := c.flagSet("add")
+	enc := utfbom.UTF8
+	fs.Var(&enc, "enc", "`encoding` of the BOM to add, such as UTF-8 or UTF-16LE")
+	list := c.listFlags(fs)
+	dry := c.dryRunFlags(fs)
+	jobs := c.jobsFlag(fs)
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	if enc == utfbom.Unknown {
+		fmt.Fprintf(c.stderr, "utfbom: %v: %s has no BOM\n", utfbom.ErrUnknownEncoding, enc)
+
+		return exitError
+	}
+
+	err := dry.check()
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	if dry.enabled {
+		return c.preview(fs.Args(), list, *jobs, dry.diff, addChange(enc))
+	}
+
+	stream := func(dst io.Writer, src io.Reader) error {
+		return addBOM(dst, src, enc)
+	}
+
+	return c.each(fs.Args(), list, *jobs, stream, func(name string) error {
+		return utfbom.PrependFile(name, enc)
+	})
+}
+
+// each applies stream to standard input and file to every named file, at most jobs files at once.
+// Errors are reported in the order of the files.
+func (c *cli) each(args []string, list *fileList, jobs int, stream func(dst io.Writer, src io.Reader) error, file func(name string) error) int {
+	names, err := list.expand(files(args), c.stdin)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	errs := make([]error, len(names))
+
+	parallel(len(names), jobs, func(i int) {
+		if names[i] == "-" {
+			c.stdinMu.Lock()
+			defer c.stdinMu.Unlock()
+
+			errs[i] = stream(c.stdout, c.stdin)
+		} else {
+			errs[i] = file(names[i])
+		}
+	})
+
+	status := exitOK
+
+	for i, name := range names {
+		if errs[i] != nil {
+			fmt.Fprintf(c.stderr, "utfbom: %s: %v\n", name, errs[i])
+
+			status = exitError
+		}
+	}
+
+	return status
+}
+
+func (c *cli) jobsFlag(fs *flag.FlagSet) *int {
+	return fs.Int("jobs", runtime.GOMAXPROCS(0), "maximum `number` of files processed at once")
+}
+
+// parallel calls fn for every index below n, at most jobs calls at once, and waits for all of them to return.
+func parallel(n, jobs int, fn func(i int)) {
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, max(jobs, 1))
+
+	for i := range n {
+		sem <- struct{}{}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			fn(i)
+		})
+	}
+
+	wg.Wait()
+}
+
+// stripBOM copies src to dst without a leading BOM.
+func stripBOM(dst io.Writer, src io.Reader) error {
+	rd, _, err := utfbom.Skip(src)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, rd)
+
+	return err
+}
+
+// addBOM copies src to dst prefixed with the BOM of enc unless src already starts with a BOM.
+func addBOM(dst io.Writer, src io.Reader, enc utfbom.Encoding) error {
+	rd, found, err := utfbom.Skip(src)
+	if err != nil {
+		return err
+	}
+
+	if found != utfbom.Unknown {
+		enc = found
+	}
+
+	_, err = dst.Write(enc.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, rd)
+
+	return err
+}
+
+// errListNull is returned when both -list and -null are given.
+var errListNull = errors.New("-list and -null are mutually exclusive")
+
+// fileList tells whether "-" stands for a list of file names read from standard input.
+type fileList struct {
+	lines bool
+	null  bool
+}
+
+func (c *cli) listFlags(fs *flag.FlagSet) *fileList {
+	l := &fileList{}
+	fs.BoolVar(&l.lines, "list", false, `read the names of the files from standard input for "-", one per line`)
+	fs.BoolVar(&l.null, "null", false, `read NUL-separated names of the files from standard input for "-", as printed by git ls-files -z`)
+
+	return l
+}
+
+// expand replaces "-" in names with the file names read from stdin if a list is expected.
+// Empty names are skipped, so a trailing separator does no harm.
+func (l *fileList) expand(names []string, stdin io.Reader) ([]string, error) {
+	if l.lines && l.null {
+		return nil, errListNull
+	}
+
+	if !l.lines && !l.null || !slices.Contains(names, "-") {
+		return names, nil
+	}
+
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	sep := "\n"
+	if l.null {
+		sep = "\x00"
+	}
+
+	var listed []string
+
+	for name := range strings.SplitSeq(string(b), sep) {
+		if !l.null {
+			name = strings.TrimSuffix(name, "\r")
+		}
+
+		if name != "" {
+			listed = append(listed, name)
+		}
+	}
+
+	var out []string
+
+	for _, name := range names {
+		if name == "-" {
+			out = append(out, listed...)
+			// standard input is read once
+			listed = nil
+		} else {
+			out = append(out, name)
+		}
+	}
+
+	return out, nil
+}
+
+func files(args []string) []string {
+	if len(args) == 0 {
+		return []string{"-"}
+	}
+
+	return args
+}
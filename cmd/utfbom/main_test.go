@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	code := run(t.Context(), args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	be.Err(t, os.WriteFile(path, []byte(content), 0o640), nil)
+
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	be.Err(t, err, nil)
+
+	return string(b)
+}
+
+func TestRun_Usage(t *testing.T) {
+	t.Parallel()
+
+	code, _, stderr := runCLI(t, "")
+	be.Equal(t, code, exitError)
+	be.True(t, strings.Contains(stderr, "usage:"))
+
+	code, _, stderr = runCLI(t, "", "frobnicate")
+	be.Equal(t, code, exitError)
+	be.True(t, strings.Contains(stderr, `unknown command "frobnicate"`))
+
+	code, stdout, _ := runCLI(t, "", "help")
+	be.Equal(t, code, exitOK)
+	be.True(t, strings.Contains(stdout, "usage:"))
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clean := writeFile(t, dir, "clean.txt", "hello")
+	dirty := writeFile(t, dir, "dirty.txt", "\ufeffhello")
+
+	code, stdout, _ := runCLI(t, "", "detect", clean, dirty)
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, clean+": Unknown\n"+dirty+": UTF8\n")
+
+	code, _, _ = runCLI(t, "", "detect", "--fail", clean, dirty)
+	be.Equal(t, code, exitFound)
+
+	code, _, _ = runCLI(t, "", "detect", "--fail", clean)
+	be.Equal(t, code, exitOK)
+
+	code, stdout, _ = runCLI(t, "\xff\xfeh\x00", "detect", "-fail")
+	be.Equal(t, code, exitFound)
+	be.Equal(t, stdout, "-: UTF16LittleEndian\n")
+
+	code, _, stderr := runCLI(t, "", "detect", filepath.Join(dir, "missing.txt"))
+	be.Equal(t, code, exitError)
+	be.True(t, strings.Contains(stderr, "missing.txt"))
+}
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, _ := runCLI(t, "\ufeffhello", "strip")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "hello")
+
+	dir := t.TempDir()
+	dirty := writeFile(t, dir, "dirty.txt", "\ufeffhello")
+	clean := writeFile(t, dir, "clean.txt", "hello")
+
+	code, _, _ = runCLI(t, "", "strip", dirty, clean)
+	be.Equal(t, code, exitOK)
+	be.Equal(t, readFile(t, dirty), "hello")
+	be.Equal(t, readFile(t, clean), "hello")
+
+	fi, err := os.Stat(dirty)
+	be.Err(t, err, nil)
+	be.Equal(t, fi.Mode().Perm(), os.FileMode(0o640))
+
+	entries, err := os.ReadDir(dir)
+	be.Err(t, err, nil)
+	be.Equal(t, len(entries), 2)
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	code, stdout, _ := runCLI(t, "hello", "add")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "\ufeffhello")
+
+	code, stdout, _ = runCLI(t, "\ufeffhello", "add", "-enc", "utf16littleendian")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "\ufeffhello")
+
+	code, stdout, _ = runCLI(t, "h\x00", "add", "-enc", "UTF16LittleEndian")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "\xff\xfeh\x00")
+
+	code, stdout, _ = runCLI(t, "\x00h", "add", "-enc", "utf-16be")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "\xfe\xff\x00h")
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "plain.txt", "hello")
+
+	code, _, _ = runCLI(t, "", "add", path)
+	be.Equal(t, code, exitOK)
+	be.Equal(t, readFile(t, path), "\ufeffhello")
+
+	code, _, stderr := runCLI(t, "", "add", "-enc", "latin1", path)
+	be.Equal(t, code, exitError)
+	be.True(t, strings.Contains(stderr, `unknown encoding: "latin1"`))
+
+	code, _, stderr = runCLI(t, "", "add", "-enc", "unknown", path)
+	be.Equal(t, code, exitError)
+	be.True(t, strings.Contains(stderr, "Unknown has no BOM"))
+}
+
+func TestFileList(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.txt", "\ufeffa")
+	b := writeFile(t, dir, "b\nc.txt", "\ufeffb")
+	c := writeFile(t, dir, "c.txt", "\ufeffc")
+
+	code, _, stderr := runCLI(t, a+"\x00"+b+"\x00", "strip", "-null", "-", c)
+	be.Equal(t, stderr, "")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, readFile(t, a), "a")
+	be.Equal(t, readFile(t, b), "b")
+	be.Equal(t, readFile(t, c), "c")
+
+	code, stdout, _ := runCLI(t, a+"\r\n"+c+"\n\n", "add", "-list")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "")
+	be.Equal(t, readFile(t, a), "\ufeffa")
+	be.Equal(t, readFile(t, c), "\ufeffc")
+
+	code, stdout, _ = runCLI(t, a+"\x00", "detect", "-null", "-fail", "-")
+	be.Equal(t, code, exitFound)
+	be.Equal(t, stdout, a+": UTF8\n")
+
+	code, _, stderr = runCLI(t, "", "detect", "-null", "-list", a)
+	be.Equal(t, code, exitError)
+	be.Equal(t, stderr, "utfbom: -list and -null are mutually exclusive\n")
+}
+
+func TestJobs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var names, detected []string
+
+	for i := range 50 {
+		content := "plain"
+		enc := "Unknown"
+
+		if i%3 == 0 {
+			content, enc = "\ufeffbom", "UTF8"
+		}
+
+		name := writeFile(t, dir, fmt.Sprintf("f%02d.txt", i), content)
+		names = append(names, name)
+		detected = append(detected, name+": "+enc+"\n")
+	}
+
+	code, stdout, stderr := runCLI(t, "", append([]string{"detect", "-jobs", "8"}, names...)...)
+	be.Equal(t, stderr, "")
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, strings.Join(detected, ""))
+
+	missing := filepath.Join(dir, "missing.txt")
+
+	code, _, stderr = runCLI(t, "", append([]string{"strip", "-jobs", "8", missing}, names...)...)
+	be.Equal(t, code, exitError)
+	be.True(t, strings.HasPrefix(stderr, "utfbom: "+missing+": "))
+
+	for _, name := range names {
+		be.True(t, !strings.HasPrefix(readFile(t, name), "\ufeff"))
+	}
+
+	code, stdout, _ = runCLI(t, "", "verify", "-jobs", "8", "-policy", "expect", dir)
+	be.Equal(t, code, exitFound)
+	be.Equal(t, strings.Count(stdout, "\n"), len(names))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/slash3b/utfbom"
+)
+
+// stats reports the number and total size of files by BOM encoding and by extension,
+// along with the largest files having a BOM, for files and directory trees.
+func (c *cli) stats(args []string) int {
+	fs := c.flagSet("stats")
+	format := fs.String("format", "text", "output `format`: text or json")
+	top := fs.Int("top", 10, "`number` of the largest files with a BOM to list")
+	jobs := c.jobsFlag(fs)
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	var write func(io.Writer, *utfbom.Stats) error
+
+	switch *format {
+	case "text":
+		write = writeStatsText
+	case "json":
+		write = writeStatsJSON
+	default:
+		fmt.Fprintf(c.stderr, "utfbom: unknown format %q\n", *format)
+
+		return exitError
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	status := exitOK
+	st := &utfbom.Stats{Top: *top}
+
+	for _, p := range paths {
+		reports, err := scanPath(p, *jobs)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+			status = exitError
+		}
+
+		for _, r := range reports {
+			st.Add(r)
+		}
+	}
+
+	err := write(c.stdout, st)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	return status
+}
+
+// writeStatsText writes the statistics as tables of aligned columns, one per section.
+func writeStatsText(w io.Writer, st *utfbom.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ENCODING\tFILES\tBYTES")
+
+	for _, enc := range slices.Sorted(maps.Keys(st.Encodings)) {
+		t := st.Encodings[enc]
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", enc, t.Files, t.Bytes)
+	}
+
+	fmt.Fprintf(tw, "total\t%d\t%d\n", st.Total.Files, st.Total.Bytes)
+
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(tw, "\nEXTENSION\tENCODING\tFILES\tBYTES")
+
+	for _, ext := range slices.Sorted(maps.Keys(st.Extensions)) {
+		byEnc := st.Extensions[ext]
+		if ext == "" {
+			ext = "(none)"
+		}
+
+		for _, enc := range slices.Sorted(maps.Keys(byEnc)) {
+			t := byEnc[enc]
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", ext, enc, t.Files, t.Bytes)
+		}
+	}
+
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	if len(st.Largest) != 0 {
+		fmt.Fprintln(tw, "\nLARGEST\tENCODING\tBYTES")
+
+		for _, r := range st.Largest {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", r.Path, r.Encoding, r.Size)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func writeStatsJSON(w io.Writer, st *utfbom.Stats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(st)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.csv", "\xff\xfea\x00b\x00")
+	writeFile(t, dir, "b.CSV", "\ufeffb")
+	writeFile(t, dir, "Makefile", "all:")
+
+	be.Err(t, os.Mkdir(filepath.Join(dir, "sub"), 0o750), nil)
+	writeFile(t, dir, filepath.Join("sub", "c.csv"), "\xff\xfec\x00")
+
+	code, stdout, stderr := runCLI(t, "", "stats", "-top", "2", dir)
+	be.Equal(t, stderr, "")
+	be.Equal(t, code, exitOK)
+	a := filepath.ToSlash(filepath.Join(dir, "a.csv"))
+	b := filepath.ToSlash(filepath.Join(dir, "b.CSV"))
+	pad := strings.Repeat(" ", len(a)-len("LARGEST"))
+
+	be.Equal(t, stdout, ""+
+		"ENCODING           FILES  BYTES\n"+
+		"Unknown            1      4\n"+
+		"UTF8               1      4\n"+
+		"UTF16LittleEndian  2      10\n"+
+		"total              4      18\n"+
+		"\n"+
+		"EXTENSION  ENCODING           FILES  BYTES\n"+
+		"(none)     Unknown            1      4\n"+
+		".csv       UTF8               1      4\n"+
+		".csv       UTF16LittleEndian  2      10\n"+
+		"\n"+
+		"LARGEST"+pad+"  ENCODING           BYTES\n"+
+		a+"  UTF16LittleEndian  6\n"+
+		b+"  UTF8               4\n")
+}
+
+func TestStats_JSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "a.csv", "\xff\xfea\x00")
+
+	code, stdout, _ := runCLI(t, "", "stats", "-format", "json", dir)
+	be.Equal(t, code, exitOK)
+
+	var st utfbom.Stats
+
+	be.Err(t, json.Unmarshal([]byte(stdout), &st), nil)
+	be.Equal(t, st.Total, utfbom.Tally{Files: 1, Bytes: 4})
+	be.Equal(t, st.Extensions[".csv"][utfbom.UTF16LittleEndian].Files, 1)
+	be.Equal(t, len(st.Largest), 1)
+}
+
+func TestStats_Errors(t *testing.T) {
+	t.Parallel()
+
+	code, _, stderr := runCLI(t, "", "stats", "-format", "xml")
+	be.Equal(t, code, exitError)
+	be.Equal(t, stderr, "utfbom: unknown format \"xml\"\n")
+
+	code, stdout, _ := runCLI(t, "", "stats", filepath.Join(t.TempDir(), "missing"))
+	be.Equal(t, code, exitError)
+	be.Equal(t, stdout[:len("ENCODING")], "ENCODING")
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/slash3b/utfbom"
+)
+
+// finding is a file violating the BOM policy checked by verify.
+type finding struct {
+	Path     string          `json:"path"`
+	Encoding utfbom.Encoding `json:"encoding"`
+	Offset   int64           `json:"offset"`
+	Line     int             `json:"line"`
+	Rule     string          `json:"rule"`
+	Message  string          `json:"message"`
+}
+
+const (
+	ruleForbidden = "bom-forbidden"
+	ruleMissing   = "bom-missing"
+	ruleMismatch  = "bom-mismatch"
+	ruleStray     = "bom-stray"
+)
+
+// expectation is the BOM a file must start with according to the policy.
+type expectation struct {
+	checked bool            // the policy applies to the file
+	bom     bool            // the file must start with a BOM, otherwise it must not
+	enc     utfbom.Encoding // encoding of the BOM, Unknown for any
+	why     string          // appended to the messages
+}
+
+// verify checks files and directory trees against a BOM policy and reports the violations
+// as text, JSON or SARIF, the latter for code scanning tools to annotate the offending files.
+func (c *cli) verify(args []string) int {
+	fs := c.flagSet("verify")
+	format := fs.String("format", "text", "output `format`: text, json or sarif")
+	policy := fs.String("policy", "forbid", "`policy` to check: forbid, files must not have a BOM, expect, files must have one, "+
+		"or editorconfig, files must match the charset set by .editorconfig files")
+	stray := fs.Bool("stray", false, "also report U+FEFF characters past the beginning of files, such as BOMs left by concatenation")
+	jobs := c.jobsFlag(fs)
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	var expect func(name string) (expectation, error)
+
+	switch *policy {
+	case "forbid":
+		expect = func(string) (expectation, error) {
+			return expectation{checked: true}, nil
+		}
+	case "expect":
+		expect = func(string) (expectation, error) {
+			return expectation{checked: true, bom: true}, nil
+		}
+	case "editorconfig":
+		expect = newEditorConfig().expectation
+	default:
+		fmt.Fprintf(c.stderr, "utfbom: unknown policy %q\n", *policy)
+
+		return exitError
+	}
+
+	var write func(io.Writer, []finding) error
+
+	switch *format {
+	case "text":
+		write = writeText
+	case "json":
+		write = writeJSON
+	case "sarif":
+		write = writeSARIF
+	default:
+		fmt.Fprintf(c.stderr, "utfbom: unknown format %q\n", *format)
+
+		return exitError
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	status := exitOK
+	findings := []finding{}
+
+	for _, p := range paths {
+		reports, err := scanPath(p, *jobs)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+			status = exitError
+		}
+
+		for _, r := range reports {
+			exp, err := expect(filepath.FromSlash(r.Path))
+			if err != nil {
+				fmt.Fprintf(c.stderr, "utfbom: %s: %v\n", r.Path, err)
+
+				status = exitError
+
+				continue
+			}
+
+			if f, ok := check(r, exp); ok {
+				findings = append(findings, f)
+			}
+
+			if !*stray || !exp.checked {
+				continue
+			}
+
+			more, err := strays(r)
+			if err != nil {
+				fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+				status = exitError
+			}
+
+			findings = append(findings, more...)
+		}
+	}
+
+	err := write(c.stdout, findings)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	if status == exitOK && len(findings) != 0 {
+		return exitFound
+	}
+
+	return status
+}
+
+// check returns the finding for a file violating exp.
+func check(r utfbom.FileReport, exp expectation) (finding, bool) {
+	f := finding{Path: r.Path, Encoding: r.Encoding, Offset: r.Offset, Line: 1}
+
+	switch {
+	case !exp.checked:
+		return finding{}, false
+	case !exp.bom && r.Encoding != utfbom.Unknown:
+		f.Rule = ruleForbidden
+		f.Message = fmt.Sprintf("file starts with a %s BOM", r.Encoding)
+	case exp.bom && r.Encoding == utfbom.Unknown && exp.enc == utfbom.Unknown:
+		f.Rule = ruleMissing
+		f.Message = "file does not start with a BOM"
+	case exp.bom && r.Encoding == utfbom.Unknown:
+		f.Rule = ruleMissing
+		f.Message = fmt.Sprintf("file does not start with a %s BOM", exp.enc)
+	case exp.bom && exp.enc != utfbom.Unknown && r.Encoding != exp.enc:
+		f.Rule = ruleMismatch
+		f.Message = fmt.Sprintf("file starts with a %s BOM instead of a %s BOM", r.Encoding, exp.enc)
+	default:
+		return finding{}, false
+	}
+
+	f.Message += exp.why
+
+	return f, true
+}
+
+// strays returns the findings for the U+FEFF characters past the beginning of a file,
+// found by reading it whole.
+func strays(r utfbom.FileReport) ([]finding, error) {
+	data, err := os.ReadFile(filepath.FromSlash(r.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []finding
+
+	for _, occ := range utfbom.Find(data) {
+		if !occ.Stray() {
+			continue
+		}
+
+		line := bytes.Count(data[:occ.Offset], []byte("\n")) + 1
+
+		findings = append(findings, finding{
+			Path:     r.Path,
+			Encoding: occ.Encoding,
+			Offset:   int64(occ.Offset),
+			Line:     line,
+			Rule:     ruleStray,
+			Message:  fmt.Sprintf("%s, line %d", occ, line),
+		})
+	}
+
+	return findings, nil
+}
+
+// scanPath detects the BOM of a file or of every file in a directory tree.
+// Paths of the reports are slash-separated, as SARIF expects them.
+// At most jobs files of a tree are inspected at once.
+func scanPath(p string, jobs int) ([]utfbom.FileReport, error) {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fi.IsDir() {
+		enc, err := utfbom.DetectFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+
+		return []utfbom.FileReport{{Path: filepath.ToSlash(p), Encoding: enc, Size: fi.Size()}}, nil
+	}
+
+	reports, err := utfbom.ScanDir(os.DirFS(p), utfbom.ScanConcurrency(jobs))
+
+	for i := range reports {
+		reports[i].Path = filepath.ToSlash(filepath.Join(p, filepath.FromSlash(reports[i].Path)))
+	}
+
+	return reports, err
+}
+
+func writeText(w io.Writer, findings []finding) error {
+	for _, f := range findings {
+		_, err := fmt.Fprintf(w, "%s:%d: %s\n", f.Path, f.Offset, f.Message)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(w io.Writer, findings []finding) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(findings)
+}
+
+// The SARIF 2.1.0 subset written by verify, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine  int   `json:"startLine"`
+		ByteOffset int64 `json:"byteOffset"`
+		ByteLength int   `json:"byteLength"`
+	}
+)
+
+func writeSARIF(w io.Writer, findings []finding) error {
+	results := make([]sarifResult, 0, len(findings))
+
+	for _, f := range findings {
+		results = append(results, sarifResult{
+			RuleID:  f.Rule,
+			Level:   "error",
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.Path},
+					Region: sarifRegion{
+						StartLine:  f.Line,
+						ByteOffset: f.Offset,
+						ByteLength: f.Encoding.Len(),
+					},
+				},
+			}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "utfbom",
+					InformationURI: "https://github.com/slash3b/utfbom",
+					Rules: []sarifRule{
+						{ID: ruleForbidden, ShortDescription: sarifMessage{Text: "File starts with a byte order mark"}},
+						{ID: ruleMissing, ShortDescription: sarifMessage{Text: "File does not start with a byte order mark"}},
+						{ID: ruleMismatch, ShortDescription: sarifMessage{Text: "File starts with the byte order mark of another encoding"}},
+						{ID: ruleStray, ShortDescription: sarifMessage{Text: "File contains a byte order mark past its beginning"}},
+					},
+				},
+			},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(log)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clean := writeFile(t, dir, "clean.txt", "hello")
+	dirty := writeFile(t, dir, "dirty.txt", "\ufeffhello")
+
+	be.Err(t, os.Mkdir(filepath.Join(dir, "sub"), 0o750), nil)
+	writeFile(t, dir, filepath.Join("sub", "utf16.txt"), "\xff\xfeh\x00")
+
+	code, stdout, _ := runCLI(t, "", "verify", clean)
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "")
+
+	code, stdout, _ = runCLI(t, "", "verify", clean, dirty)
+	be.Equal(t, code, exitFound)
+	be.Equal(t, stdout, filepath.ToSlash(dirty)+":0: file starts with a UTF8 BOM\n")
+
+	code, stdout, _ = runCLI(t, "", "verify", "-policy", "expect", dir)
+	be.Equal(t, code, exitFound)
+	be.Equal(t, stdout, filepath.ToSlash(clean)+":0: file does not start with a BOM\n")
+
+	code, stdout, _ = runCLI(t, "", "verify", "-format", "json", dir)
+	be.Equal(t, code, exitFound)
+
+	var findings []finding
+
+	be.Err(t, json.Unmarshal([]byte(stdout), &findings), nil)
+	be.Equal(t, findings, []finding{
+		{Path: filepath.ToSlash(dirty), Encoding: utfbom.UTF8, Offset: 0, Line: 1, Rule: ruleForbidden, Message: "file starts with a UTF8 BOM"},
+		{Path: filepath.ToSlash(filepath.Join(dir, "sub", "utf16.txt")), Encoding: utfbom.UTF16LittleEndian, Offset: 0, Line: 1, Rule: ruleForbidden, Message: "file starts with a UTF16LittleEndian BOM"},
+	})
+	be.True(t, strings.Contains(stdout, `"encoding": "UTF8"`))
+
+	code, stdout, _ = runCLI(t, "", "verify", "-format", "json", clean)
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "[]\n")
+}
+
+func TestVerify_SARIF(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dirty := writeFile(t, dir, "dirty.txt", "\ufeffhello")
+
+	code, stdout, _ := runCLI(t, "", "verify", "-format", "sarif", dirty)
+	be.Equal(t, code, exitFound)
+
+	var log sarifLog
+
+	be.Err(t, json.Unmarshal([]byte(stdout), &log), nil)
+	be.Equal(t, log.Version, "2.1.0")
+	be.Equal(t, len(log.Runs), 1)
+	be.Equal(t, log.Runs[0].Tool.Driver.Name, "utfbom")
+	be.Equal(t, log.Runs[0].Results, []sarifResult{{
+		RuleID:  ruleForbidden,
+		Level:   "error",
+		Message: sarifMessage{Text: "file starts with a UTF8 BOM"},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(dirty)},
+				Region:           sarifRegion{StartLine: 1, ByteOffset: 0, ByteLength: 3},
+			},
+		}},
+	}})
+	be.True(t, strings.Contains(stdout, `"$schema": "https://json.schemastore.org/sarif-2.1.0.json"`))
+}
+
+func TestVerify_Stray(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	joined := writeFile(t, dir, "joined.csv", "\ufeffid\n1\n\ufeffid\n2\n")
+	clean := writeFile(t, dir, "clean.csv", "id\n1\n")
+
+	code, stdout, _ := runCLI(t, "", "verify", "-stray", clean)
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "")
+
+	// the stray BOM is reported even where the policy wants one at the beginning
+	code, stdout, _ = runCLI(t, "", "verify", "-stray", "-policy", "expect", dir)
+	be.Equal(t, code, exitFound)
+	be.Equal(t, stdout, filepath.ToSlash(clean)+":0: file does not start with a BOM\n"+
+		filepath.ToSlash(joined)+":8: stray UTF8 BOM at byte 8, line 3\n")
+
+	code, stdout, _ = runCLI(t, "", "verify", "-stray", "-format", "sarif", joined)
+	be.Equal(t, code, exitFound)
+
+	var log sarifLog
+
+	be.Err(t, json.Unmarshal([]byte(stdout), &log), nil)
+	be.Equal(t, len(log.Runs[0].Results), 2)
+	be.Equal(t, log.Runs[0].Results[1].RuleID, ruleStray)
+	be.Equal(t, log.Runs[0].Results[1].Locations[0].PhysicalLocation.Region, sarifRegion{StartLine: 3, ByteOffset: 8, ByteLength: 3})
+
+	// without -stray only the beginning of files is checked
+	code, stdout, _ = runCLI(t, "", "verify", "-policy", "expect", joined)
+	be.Equal(t, code, exitOK)
+	be.Equal(t, stdout, "")
+}
+
+func TestVerify_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dirty := writeFile(t, dir, "dirty.txt", "\ufeffhello")
+
+	code, _, stderr := runCLI(t, "", "verify", "-format", "xml", dirty)
+	be.Equal(t, code, exitError)
+	be.Equal(t, stderr, "utfbom: unknown format \"xml\"\n")
+
+	code, _, stderr = runCLI(t, "", "verify", "-policy", "ignore", dirty)
+	be.Equal(t, code, exitError)
+	be.Equal(t, stderr, "utfbom: unknown policy \"ignore\"\n")
+
+	code, stdout, stderr := runCLI(t, "", "verify", filepath.Join(dir, "missing.txt"), dirty)
+	be.Equal(t, code, exitError)
+	be.True(t, strings.Contains(stderr, "missing.txt"))
+	be.Equal(t, stdout, filepath.ToSlash(dirty)+":0: file starts with a UTF8 BOM\n")
+}
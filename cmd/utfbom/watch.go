@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/slash3b/utfbom"
+)
+
+// watch monitors a directory and reports, or strips with -strip, the BOM of files created or modified in it.
+// A file is processed once it has not changed for the settle duration, so that files still being written,
+// for example by an export job, are not rewritten under the feet of their writer.
+func (c *cli) watch(args []string) int {
+	fs := c.flagSet("watch")
+	strip := fs.Bool("strip", false, "strip the BOM of the files instead of only reporting it")
+	pattern := fs.String("pattern", "*", "`glob` the base names of the files must match, such as *.csv")
+	settle := fs.Duration("settle", time.Second, "`duration` a file must stay unchanged before it is processed")
+
+	if status, ok := c.parse(fs, args); !ok {
+		return status
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "utfbom: watch expects exactly one directory")
+
+		return exitError
+	}
+
+	_, err := filepath.Match(*pattern, "")
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: pattern %q: %v\n", *pattern, err)
+
+		return exitError
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	defer w.Close()
+
+	err = w.Add(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+
+		return exitError
+	}
+
+	// settled receives the files which have not changed for the settle duration,
+	// along with the generation of the timer which fired
+	settled := make(chan settledFile)
+	done := make(chan struct{})
+	pending := map[string]settleTimer{}
+	gen := 0
+
+	defer func() {
+		close(done)
+
+		for _, p := range pending {
+			p.t.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return exitOK
+		case ev, ok := <-w.Events:
+			if !ok {
+				return exitOK
+			}
+
+			if !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Write) {
+				continue
+			}
+
+			if match, _ := filepath.Match(*pattern, filepath.Base(ev.Name)); !match {
+				continue
+			}
+
+			// a timer which already fired may be blocked sending, so it is replaced
+			// rather than reset, and its send is ignored as stale
+			if p, ok := pending[ev.Name]; ok {
+				p.t.Stop()
+			}
+
+			gen++
+			f := settledFile{name: ev.Name, gen: gen}
+			pending[f.name] = settleTimer{
+				t: time.AfterFunc(*settle, func() {
+					select {
+					case settled <- f:
+					case <-done:
+					}
+				}),
+				gen: f.gen,
+			}
+		case f := <-settled:
+			if p, ok := pending[f.name]; !ok || p.gen != f.gen {
+				continue
+			}
+
+			delete(pending, f.name)
+			c.watchOne(f.name, *strip)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return exitOK
+			}
+
+			fmt.Fprintf(c.stderr, "utfbom: %v\n", err)
+		}
+	}
+}
+
+// settleTimer is the timer of a changed file, tagged with its generation.
+type settleTimer struct {
+	t   *time.Timer
+	gen int
+}
+
+// settledFile is sent by a settleTimer when it fires.
+type settledFile struct {
+	name string
+	gen  int
+}
+
+// watchOne reports or strips the BOM of a settled file.
+// Files removed in the meantime and anything but regular files are skipped.
+// Rewriting a file triggers another event for it, which finds no BOM and reports nothing.
+func (c *cli) watchOne(name string, strip bool) {
+	fi, err := os.Stat(name)
+	if err != nil || !fi.Mode().IsRegular() {
+		return
+	}
+
+	var enc utfbom.Encoding
+
+	if strip {
+		enc, err = utfbom.TrimFile(name)
+	} else {
+		enc, err = utfbom.DetectFile(name)
+	}
+
+	if err != nil {
+		fmt.Fprintf(c.stderr, "utfbom: %s: %v\n", name, err)
+
+		return
+	}
+
+	switch {
+	case enc == utfbom.Unknown:
+	case strip:
+		fmt.Fprintf(c.stdout, "%s: %s stripped\n", name, enc)
+	default:
+		fmt.Fprintf(c.stdout, "%s: %s\n", name, enc)
+	}
+}
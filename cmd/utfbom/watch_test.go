@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while watch writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// startWatch runs the watch command until the returned function is called,
+// which waits for the command to return and reports its exit code.
+func startWatch(t *testing.T, stdout *syncBuffer, args ...string) func() int {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	code := make(chan int, 1)
+
+	go func() {
+		var stderr bytes.Buffer
+
+		code <- run(ctx, append([]string{"watch", "-settle", "10ms"}, args...), strings.NewReader(""), stdout, &stderr)
+	}()
+
+	return func() int {
+		cancel()
+
+		return <-code
+	}
+}
+
+// eventually writes content to the named file until done reports true,
+// as the watcher may not be set up yet when the file is first written.
+func eventually(t *testing.T, dir, name, content string, done func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s was not processed in time", name)
+		}
+
+		writeFile(t, dir, name, content)
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestWatch_Strip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	txt := writeFile(t, dir, "keep.txt", "\ufeffkeep")
+
+	var stdout syncBuffer
+
+	stop := startWatch(t, &stdout, "-strip", "-pattern", "*.csv", dir)
+
+	csv := filepath.Join(dir, "data.csv")
+	eventually(t, dir, "data.csv", "\ufeffa,b", func() bool {
+		b, _ := os.ReadFile(csv)
+
+		return string(b) == "a,b"
+	})
+
+	be.Equal(t, stop(), exitOK)
+	be.Equal(t, readFile(t, txt), "\ufeffkeep")
+	be.True(t, strings.Contains(stdout.String(), csv+": UTF8 stripped\n"))
+}
+
+func TestWatch_Report(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var stdout syncBuffer
+
+	stop := startWatch(t, &stdout, dir)
+
+	csv := filepath.Join(dir, "data.csv")
+	eventually(t, dir, "data.csv", "\ufeffa,b", func() bool {
+		return strings.Contains(stdout.String(), csv+": UTF8\n")
+	})
+
+	be.Equal(t, stop(), exitOK)
+	be.Equal(t, readFile(t, csv), "\ufeffa,b")
+}
+
+func TestWatch_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{"no directory", []string{"watch"}, "expects exactly one directory"},
+		{"two directories", []string{"watch", dir, dir}, "expects exactly one directory"},
+		{"bad pattern", []string{"watch", "-pattern", "[", dir}, "syntax error in pattern"},
+		{"missing directory", []string{"watch", filepath.Join(dir, "missing")}, "no such file or directory"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			code, _, stderr := runCLI(t, "", tc.args...)
+			be.Equal(t, code, exitError)
+			be.True(t, strings.Contains(stderr, tc.stderr))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package utfbom
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+var _ io.Reader = (*ContextReader)(nil)
+
+// ContextReader is a Reader whose reads fail with the context error once its context is done.
+// Detecting the BOM may block indefinitely on a network stream that sends nothing,
+// so detection runs in a separate goroutine that Read stops waiting for when the context is done.
+// That goroutine ends when the blocked read of the wrapped reader returns,
+// usually once the caller closes the stream after the cancellation.
+//
+// Reads of the payload are not interrupted: they are passed to the wrapped reader
+// as long as the context is not done when they start.
+//
+// ContextReader is not safe for concurrent use.
+type ContextReader struct {
+	ctx   context.Context //nolint:containedctx // reads are bound by the context given to the constructor
+	rd    *Reader
+	start sync.Once
+	done  chan struct{} // closed when detection has finished
+}
+
+// NewContextReader wraps an incoming reader the same way as NewReader does,
+// bounding the reads by ctx.
+// Passing a nil reader will cause a panic on the first Read call.
+func NewContextReader(ctx context.Context, rd io.Reader, opts ...Option) *ContextReader {
+	return &ContextReader{
+		ctx:  ctx,
+		rd:   NewReader(rd, opts...),
+		done: make(chan struct{}),
+	}
+}
+
+// Read implements the io.Reader interface, see Reader.Read.
+// It returns ctx.Err() if the context is done before the BOM is detected or before the call.
+func (r *ContextReader) Read(buf []byte) (int, error) {
+	err := r.detect()
+	if err != nil {
+		return 0, err
+	}
+
+	return r.rd.Read(buf)
+}
+
+// Encoding detects and removes any Byte Order Mark (BOM) unless that is already done
+// and returns the detected encoding, see Reader.Encoding.
+// It returns ctx.Err() if the context is done before the BOM is detected or before the call.
+func (r *ContextReader) Encoding() (Encoding, error) {
+	err := r.detect()
+	if err != nil {
+		return Unknown, err
+	}
+
+	return r.rd.Encoding()
+}
+
+// detect waits for the BOM to be detected or for the context to be done, whichever happens first.
+// A detection error is left for the Reader to report.
+func (r *ContextReader) detect() error {
+	err := r.ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	r.start.Do(func() {
+		if r.ctx.Done() == nil {
+			// the context is never done, no need to wait in another goroutine
+			_, _ = r.rd.Encoding()
+			close(r.done)
+
+			return
+		}
+
+		go func() {
+			_, _ = r.rd.Encoding()
+			close(r.done)
+		}()
+	})
+
+	select {
+	case <-r.done:
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
@@ -0,0 +1,110 @@
+package utfbom_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestContextReader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	rd := utfbom.NewContextReader(ctx, strings.NewReader("\ufeffhello"))
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "hello")
+
+	enc, err := rd.Encoding()
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF8)
+}
+
+func TestContextReader_Background(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewContextReader(context.Background(), strings.NewReader("\xfe\xff\x00h"))
+
+	enc, err := rd.Encoding()
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF16BigEndian)
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, out, []byte{0x00, 'h'})
+}
+
+func TestContextReader_CanceledDuringDetection(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rd := utfbom.NewContextReader(ctx, pr)
+
+	go func() {
+		// only the start of a BOM arrives, detection waits for more
+		_, _ = pw.Write([]byte{0xef})
+
+		cancel()
+	}()
+
+	n, err := rd.Read(make([]byte, 16))
+	be.Err(t, err, context.Canceled)
+	be.Equal(t, n, 0)
+
+	_, err = rd.Encoding()
+	be.Err(t, err, context.Canceled)
+
+	// unblock the detection goroutine
+	pw.CloseWithError(errors.New("closed"))
+}
+
+func TestContextReader_CanceledBeforeRead(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rd := utfbom.NewContextReader(ctx, strings.NewReader("hello"))
+
+	_, err := rd.Read(make([]byte, 16))
+	be.Err(t, err, context.Canceled)
+}
+
+func TestContextReader_CanceledAfterDetection(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rd := utfbom.NewContextReader(ctx, strings.NewReader("hello"))
+
+	buf := make([]byte, 2)
+
+	n, err := rd.Read(buf)
+	be.Err(t, err, nil)
+	be.Equal(t, string(buf[:n]), "he")
+
+	cancel()
+
+	_, err = rd.Read(buf)
+	be.Err(t, err, context.Canceled)
+}
+
+func TestContextReader_DetectionError(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewContextReader(context.Background(), strings.NewReader("\ufeffx"), utfbom.Forbid())
+
+	_, err := rd.Read(make([]byte, 16))
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+}
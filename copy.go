@@ -0,0 +1,95 @@
+package utfbom
+
+import (
+	"errors"
+	"io"
+)
+
+// Copy copies from src to dst until either EOF is reached on src or an error occurs,
+// removing a leading Byte Order Mark (BOM) on the way.
+// It returns the number of bytes written and the encoding of the removed BOM.
+//
+// Unlike io.Copy over a Reader, Copy does not allocate a read buffer for BOM detection:
+// the few leading bytes needed to tell the BOM are read into a small array and the rest is copied with io.Copy,
+// making use of the io.WriterTo implementation of src or the io.ReaderFrom implementation of dst.
+//
+// Same as for CopyWithBOM, errors writing the leading bytes to dst are wrapped in ErrWrite.
+// Errors of the rest of the copy are returned as is, as io.Copy doesn't tell read errors from write errors apart.
+func Copy(dst io.Writer, src io.Reader) (int64, Encoding, error) {
+	var buf [maxBOMLen]byte
+
+	n, err := readHead(src, buf[:], false)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, Unknown, newReadError("detect", int64(n), err)
+	}
+
+	eof := err != nil
+
+	head, enc := Trim(buf[:n])
+
+	var written int64
+
+	if len(head) != 0 {
+		m, err := dst.Write(head)
+		written = int64(m)
+
+		if err != nil {
+			return written, enc, errors.Join(ErrWrite, err)
+		}
+	}
+
+	if eof {
+		// src is exhausted
+		return written, enc, nil
+	}
+
+	m, err := io.Copy(dst, src)
+
+	return written + m, enc, err
+}
+
+// CopyWithBOM writes the Byte Order Mark (BOM) of enc to dst and then copies from src
+// until either EOF is reached on src or an error occurs.
+// Same as Prepend, the BOM is not written if src already starts with any BOM,
+// so a BOM is never duplicated, and nothing but the payload is copied if enc is Unknown.
+// The BOM is written even for an empty src.
+// It returns the number of bytes written to dst, including the BOM.
+func CopyWithBOM(dst io.Writer, src io.Reader, enc Encoding) (int64, error) {
+	var buf [maxBOMLen]byte
+
+	n, err := readHead(src, buf[:], false)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, newReadError("detect", int64(n), err)
+	}
+
+	eof := err != nil
+
+	var written int64
+
+	if DetectEncoding(buf[:n]) == Unknown && enc != Unknown {
+		m, err := io.WriteString(dst, enc.bom())
+		written = int64(m)
+
+		if err != nil {
+			return written, errors.Join(ErrWrite, err)
+		}
+	}
+
+	if n != 0 {
+		m, err := dst.Write(buf[:n])
+		written += int64(m)
+
+		if err != nil {
+			return written, errors.Join(ErrWrite, err)
+		}
+	}
+
+	if eof {
+		// src is exhausted
+		return written, nil
+	}
+
+	m, err := io.Copy(dst, src)
+
+	return written + m, err
+}
@@ -0,0 +1,177 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		enc      utfbom.Encoding
+		expected []byte
+	}{
+		{"empty", nil, utfbom.Unknown, nil},
+		{"short_no_bom", []byte("hi"), utfbom.Unknown, []byte("hi")},
+		{"no_bom", []byte("hello"), utfbom.Unknown, []byte("hello")},
+		{"only_bom", utf8BOM, utfbom.UTF8, nil},
+		{"incomplete_bom", utf8BOM[:2], utfbom.Unknown, utf8BOM[:2]},
+		{"utf8", append(utf8BOM, "hello"...), utfbom.UTF8, []byte("hello")},
+		{"utf16be", append(utf16BEBOM, 0x00, 'h'), utfbom.UTF16BigEndian, []byte{0x00, 'h'}},
+		{"utf32le", append(utf32LEBOM, 'h', 0x00, 0x00, 0x00), utfbom.UTF32LittleEndian, []byte{'h', 0x00, 0x00, 0x00}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			n, enc, err := utfbom.Copy(&out, iotest.OneByteReader(bytes.NewReader(tc.input)))
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.enc)
+			be.Equal(t, n, int64(len(tc.expected)))
+			be.Equal(t, out.Bytes(), tc.expected)
+		})
+	}
+}
+
+func TestCopy_Errors(t *testing.T) {
+	t.Parallel()
+
+	errDisk := errors.New("disk failure")
+
+	_, _, err := utfbom.Copy(&bytes.Buffer{}, iotest.ErrReader(errDisk))
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.True(t, errors.Is(err, errDisk))
+
+	n, _, err := utfbom.Copy(&bytes.Buffer{}, iotest.TimeoutReader(strings.NewReader("\ufeffhello world")))
+	be.Err(t, err, iotest.ErrTimeout)
+	be.Equal(t, n, int64(1))
+
+	n, enc, err := utfbom.Copy(errWriter{errDisk}, strings.NewReader("\ufeffhello"))
+	be.Err(t, err, utfbom.ErrWrite)
+	be.Err(t, err, errDisk)
+	be.Equal(t, enc, utfbom.UTF8)
+	be.Equal(t, n, int64(0))
+}
+
+func TestCopy_Allocations(t *testing.T) {
+	input := strings.Repeat("a", 1<<16)
+
+	var out bytes.Buffer
+	out.Grow(len(input))
+
+	allocs := testing.AllocsPerRun(10, func() {
+		out.Reset()
+
+		_, _, err := utfbom.Copy(&out, strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// strings.Reader implements io.WriterTo, so only the reader itself
+	// and the tiny detection buffer are allocated
+	be.True(t, allocs <= 2)
+}
+
+func ExampleCopy() {
+	n, enc, err := utfbom.Copy(os.Stdout, strings.NewReader("\ufeffhello\n"))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("written:", n)
+	fmt.Println("removed BOM:", enc)
+
+	// output:
+	// hello
+	// written: 6
+	// removed BOM: UTF8
+}
+
+func TestCopyWithBOM(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		enc      utfbom.Encoding
+		expected []byte
+	}{
+		{"empty", nil, utfbom.UTF8, utf8BOM},
+		{"unknown", []byte("hello"), utfbom.Unknown, []byte("hello")},
+		{"short", []byte("hi"), utfbom.UTF8, append(utf8BOM, "hi"...)},
+		{"no_bom", []byte("hello"), utfbom.UTF8, append(utf8BOM, "hello"...)},
+		{"same_bom", append(utf8BOM, "hello"...), utfbom.UTF8, append(utf8BOM, "hello"...)},
+		{"only_bom", utf8BOM, utfbom.UTF8, utf8BOM},
+		{"different_bom", append(utf16LEBOM, 'h', 0x00), utfbom.UTF8, append(utf16LEBOM, 'h', 0x00)},
+		{"incomplete_bom", utf8BOM[:2], utfbom.UTF8, append(utf8BOM, utf8BOM[:2]...)},
+		{"utf32be", []byte{0x00, 0x00, 0x00, 'h'}, utfbom.UTF32BigEndian, append(utf32BEBOM, 0x00, 0x00, 0x00, 'h')},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			n, err := utfbom.CopyWithBOM(&out, iotest.OneByteReader(bytes.NewReader(tc.input)), tc.enc)
+			be.Err(t, err, nil)
+			be.Equal(t, n, int64(len(tc.expected)))
+			be.Equal(t, out.Bytes(), tc.expected)
+		})
+	}
+}
+
+func TestCopyWithBOM_Errors(t *testing.T) {
+	t.Parallel()
+
+	errDisk := errors.New("disk failure")
+
+	_, err := utfbom.CopyWithBOM(&bytes.Buffer{}, iotest.ErrReader(errDisk), utfbom.UTF8)
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.True(t, errors.Is(err, errDisk))
+
+	n, err := utfbom.CopyWithBOM(errWriter{errDisk}, strings.NewReader("hello"), utfbom.UTF8)
+	be.True(t, errors.Is(err, utfbom.ErrWrite))
+	be.True(t, errors.Is(err, errDisk))
+	be.Equal(t, n, int64(0))
+
+	n, err = utfbom.CopyWithBOM(errWriter{errDisk}, strings.NewReader("\ufeffhello"), utfbom.UTF8)
+	be.Err(t, err, utfbom.ErrWrite)
+	be.Err(t, err, errDisk)
+	be.Equal(t, n, int64(0))
+}
+
+func ExampleCopyWithBOM() {
+	var out bytes.Buffer
+
+	// the BOM already present in the source is not duplicated
+	for _, src := range []string{"hello", "\ufeffhello"} {
+		out.Reset()
+
+		_, err := utfbom.CopyWithBOM(&out, strings.NewReader(src), utfbom.UTF8)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("%q\n", out.String())
+	}
+
+	// output:
+	// "\ufeffhello"
+	// "\ufeffhello"
+}
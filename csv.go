@@ -0,0 +1,69 @@
+package utfbom
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// ExcelOption configures NewExcelCSVWriter.
+type ExcelOption func(*excelConfig)
+
+type excelConfig struct {
+	utf16 bool
+}
+
+// ExcelUTF16 makes NewExcelCSVWriter write tab-delimited UTF-16 Little Endian text with CRLF line endings,
+// the "Unicode Text" format older Excel versions require to open non-ASCII text correctly.
+func ExcelUTF16() ExcelOption {
+	return func(c *excelConfig) {
+		c.utf16 = true
+	}
+}
+
+// NewCSVReader detects and removes the Byte Order Mark (BOM) of rd right away
+// and returns a csv.Reader over the payload decoded into UTF-8 along with the detected encoding.
+// UTF-16 and UTF-32 payloads, common in spreadsheet exports, are transcoded the same way as by UTF8Reader.
+// Options are applied the same way as for UTF8Reader.
+//
+// Payloads of encodings other than UTF-8, UTF-16 and UTF-32 fail with ErrUnsupportedEncoding.
+func NewCSVReader(rd io.Reader, opts ...Option) (*csv.Reader, Encoding, error) {
+	u := NewUTF8Reader(rd, opts...)
+
+	enc, err := u.rd.Encoding()
+	if err != nil {
+		return nil, enc, err
+	}
+
+	if !enc.AnyOf(Unknown, UTF8) && !enc.IsUTF16() && !enc.IsUTF32() {
+		return nil, enc, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, enc)
+	}
+
+	return csv.NewReader(u), enc, nil
+}
+
+// NewExcelCSVWriter returns a csv.Writer whose output starts with the UTF-8 Byte Order Mark (BOM),
+// so that Excel recognizes the encoding and shows accented characters correctly.
+// With ExcelUTF16 the output is UTF-16 Little Endian with a BOM instead.
+// Same as with NewWriter, the BOM is written right before the first record, so an empty output stays empty.
+//
+// As usual for csv.Writer, call Flush after the last record and check Error.
+func NewExcelCSVWriter(wr io.Writer, opts ...ExcelOption) *csv.Writer {
+	var c excelConfig
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if !c.utf16 {
+		return csv.NewWriter(NewWriter(wr, UTF8))
+	}
+
+	// Records always end with a line break, so no incomplete rune is left
+	// in EncodingWriter after Flush and it need not be closed.
+	cw := csv.NewWriter(NewEncodingWriter(wr, UTF16LittleEndian))
+	cw.Comma = '\t'
+	cw.UseCRLF = true
+
+	return cw
+}
@@ -0,0 +1,150 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestNewCSVReader(t *testing.T) {
+	t.Parallel()
+
+	const input = "Name,City\nJürgen,Köln\n\"Zażółć, gęślą\",🙂\n"
+
+	expected := [][]string{
+		{"Name", "City"},
+		{"Jürgen", "Köln"},
+		{"Zażółć, gęślą", "🙂"},
+	}
+
+	encodings := []utfbom.Encoding{
+		utfbom.Unknown,
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			crd, detected, err := utfbom.NewCSVReader(iotest.HalfReader(bytes.NewReader(encode(enc, input))))
+			be.Err(t, err, nil)
+			be.Equal(t, detected, enc)
+
+			records, err := crd.ReadAll()
+			be.Err(t, err, nil)
+			be.Equal(t, records, expected)
+		})
+	}
+}
+
+func TestNewCSVReader_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, enc, err := utfbom.NewCSVReader(bytes.NewReader(append(utfbom.SCSU.Bytes(), 'x')), utfbom.WithExtendedBOMs())
+	be.Err(t, err, utfbom.ErrUnsupportedEncoding)
+	be.Equal(t, enc, utfbom.SCSU)
+
+	_, enc, err = utfbom.NewCSVReader(bytes.NewReader(append(utf8BOM, 'x')), utfbom.WithPolicy(utfbom.ForbidBOM))
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+	be.Equal(t, enc, utfbom.UTF8)
+
+	_, _, err = utfbom.NewCSVReader(iotest.ErrReader(errors.New("boom")))
+	be.Err(t, err, utfbom.ErrRead)
+}
+
+func ExampleNewCSVReader() {
+	// "a,é\n" as exported by a spreadsheet in UTF-16 Little Endian with a BOM
+	input := []byte{0xff, 0xfe, 'a', 0x00, ',', 0x00, 0xe9, 0x00, '\n', 0x00}
+
+	crd, enc, err := utfbom.NewCSVReader(bytes.NewReader(input))
+	if err != nil {
+		panic(err)
+	}
+
+	records, err := crd.ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("detected encoding:", enc)
+	fmt.Printf("%q\n", records)
+
+	// output:
+	// detected encoding: UTF16LittleEndian
+	// [["a" "é"]]
+}
+
+func TestNewExcelCSVWriter(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Name", "City"},
+		{"Jürgen", "Köln, NRW"},
+		{"Zażółć", strings.Repeat("🙂", 3000)},
+	}
+
+	testCases := []struct {
+		name     string
+		opts     []utfbom.ExcelOption
+		enc      utfbom.Encoding
+		expected string
+	}{
+		{
+			"utf8", nil, utfbom.UTF8,
+			"Name,City\nJürgen,\"Köln, NRW\"\nZażółć," + strings.Repeat("🙂", 3000) + "\n",
+		},
+		{
+			"utf16",
+			[]utfbom.ExcelOption{utfbom.ExcelUTF16()},
+			utfbom.UTF16LittleEndian,
+			"Name\tCity\r\nJürgen\tKöln, NRW\r\nZażółć\t" + strings.Repeat("🙂", 3000) + "\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			cw := utfbom.NewExcelCSVWriter(&buf, tc.opts...)
+			be.Err(t, cw.WriteAll(records), nil)
+			be.Equal(t, buf.Bytes(), encode(tc.enc, tc.expected))
+		})
+	}
+}
+
+func TestNewExcelCSVWriter_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cw := utfbom.NewExcelCSVWriter(&buf, utfbom.ExcelUTF16())
+	cw.Flush()
+	be.Err(t, cw.Error(), nil)
+	be.Equal(t, buf.Len(), 0)
+}
+
+func ExampleNewExcelCSVWriter() {
+	var buf bytes.Buffer
+
+	cw := utfbom.NewExcelCSVWriter(&buf)
+	_ = cw.Write([]string{"Name", "City"})
+	_ = cw.Write([]string{"Jürgen", "Köln"})
+	cw.Flush()
+
+	fmt.Printf("%q\n", buf.String())
+
+	// output:
+	// "\ufeffName,City\nJürgen,Köln\n"
+}
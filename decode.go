@@ -0,0 +1,174 @@
+package utfbom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidSequence is returned under RejectInvalid when the payload is not valid in its encoding,
+// as a TranscodeError telling where.
+var ErrInvalidSequence = errors.New("utfbom: invalid sequence")
+
+// DecodeToUTF8 detects the Byte Order Mark (BOM) of b, removes it
+// and returns the payload converted to UTF-8 along with the detected encoding.
+//
+// UTF-16 payloads are decoded with surrogate pairs combined, UTF-32 payloads code point by code point.
+// Invalid sequences, such as unpaired surrogates or a truncated last code unit,
+// are replaced with utf8.RuneError (U+FFFD), or make DecodeToUTF8 fail with ErrInvalidSequence
+// under RejectInvalid, in which case UTF-8 payloads are validated as well.
+//
+// UTF-8 payloads and payloads without a BOM are returned as is, sharing memory with b.
+// Other encodings, such as UTF-7 detected with WithExtendedBOMs, make DecodeToUTF8 fail with ErrUnsupportedEncoding.
+// Options are applied the same way as for UTF8Reader: under IgnoreBOM
+// the BOM is kept and decoded as U+FEFF, ExpectBOM and ForbidBOM are checked.
+func DecodeToUTF8(b []byte, opts ...Option) ([]byte, Encoding, error) {
+	o := newOptions(opts)
+	enc := detectPreferring(b, o.prefer, o.extended)
+
+	err := o.check(enc)
+	if err != nil {
+		return nil, enc, err
+	}
+
+	skip := 0
+	if o.strips(enc) {
+		skip = enc.Len()
+	}
+
+	payload := b[skip:]
+
+	switch {
+	case enc == Unknown:
+		return payload, enc, nil
+	case enc != UTF8 && !enc.IsUTF16() && !enc.IsUTF32():
+		return nil, enc, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, enc)
+	case o.reject:
+		if off := firstInvalid(payload, enc); off >= 0 {
+			return nil, enc, &TranscodeError{Offset: int64(skip + off), Encoding: enc}
+		}
+	}
+
+	if enc == UTF8 {
+		return payload, enc, nil
+	}
+
+	// exact for UTF-16 text below U+0800, such as most European scripts, and an upper bound for UTF-32
+	out, _ := appendDecoded(make([]byte, 0, len(payload)+utf8.UTFMax), payload, enc, true)
+
+	return out, enc, nil
+}
+
+// EncodeFromUTF8 converts the UTF-8 text s to enc, with the byte order of enc,
+// prefixed with the Byte Order Mark (BOM) of enc under the UseBOM and ExpectBOM policies.
+// A UTF-8 BOM at the beginning of s is removed first, so the BOM is never duplicated,
+// except under IgnoreBOM, where it is converted along with the text.
+// Invalid UTF-8 sequences are converted to utf8.RuneError (U+FFFD).
+//
+// For UTF8 the text is copied as is, with the BOM added or removed according to the policy,
+// for Unknown it is copied unchanged. Other encodings, such as UTF-7,
+// make EncodeFromUTF8 fail with ErrUnsupportedEncoding.
+func EncodeFromUTF8(s []byte, enc Encoding, policy BOMPolicy) ([]byte, error) {
+	if enc == Unknown {
+		return append([]byte{}, s...), nil
+	}
+
+	if enc != UTF8 && !enc.IsUTF16() && !enc.IsUTF32() {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, enc)
+	}
+
+	if policy != IgnoreBOM && DetectEncoding(s) == UTF8 {
+		s = s[UTF8.Len():]
+	}
+
+	var bom string
+	if policy.writesBOM() {
+		bom = enc.bom()
+	}
+
+	order, _ := byteOrder(enc).(binary.AppendByteOrder)
+
+	switch enc.Family() {
+	case FamilyUTF16:
+		// at most 2 bytes per byte of ASCII text, less for the rest
+		out := append(make([]byte, 0, len(bom)+2*len(s)), bom...)
+
+		for _, r := range string(s) {
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				out = order.AppendUint16(out, uint16(r1))
+				out = order.AppendUint16(out, uint16(r2))
+			} else {
+				out = order.AppendUint16(out, uint16(r))
+			}
+		}
+
+		return out, nil
+	case FamilyUTF32:
+		out := append(make([]byte, 0, len(bom)+4*utf8.RuneCount(s)), bom...)
+
+		for _, r := range string(s) {
+			out = order.AppendUint32(out, uint32(r))
+		}
+
+		return out, nil
+	default:
+		out := append(make([]byte, 0, len(bom)+len(s)), bom...)
+
+		return append(out, s...), nil
+	}
+}
+
+// firstInvalid returns the offset of the first invalid sequence of b encoded as enc, or -1 if b is valid.
+// An incomplete code unit or surrogate pair at the end of b is invalid.
+func firstInvalid(b []byte, enc Encoding) int {
+	order := byteOrder(enc)
+
+	switch enc.Family() {
+	case FamilyUTF8:
+		if utf8.Valid(b) {
+			return -1
+		}
+
+		for i := 0; i < len(b); {
+			r, n := utf8.DecodeRune(b[i:])
+			if r == utf8.RuneError && n == 1 {
+				return i
+			}
+
+			i += n
+		}
+	case FamilyUTF16:
+		for i := 0; i < len(b); i += 2 {
+			if i+2 > len(b) {
+				return i
+			}
+
+			u := rune(order.Uint16(b[i:]))
+			if !utf16.IsSurrogate(u) {
+				continue
+			}
+
+			if u >= 0xdc00 || i+4 > len(b) {
+				return i
+			}
+
+			next := rune(order.Uint16(b[i+2:]))
+			if next < 0xdc00 || next > 0xdfff {
+				return i
+			}
+
+			i += 2
+		}
+	case FamilyUTF32:
+		for i := 0; i < len(b); i += 4 {
+			if i+4 > len(b) || !utf8.ValidRune(rune(order.Uint32(b[i:]))) {
+				return i
+			}
+		}
+	default:
+	}
+
+	return -1
+}
@@ -0,0 +1,201 @@
+package utfbom_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestDecodeToUTF8(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			for _, opts := range [][]utfbom.Option{nil, {utfbom.RejectInvalid()}} {
+				out, got, err := utfbom.DecodeToUTF8(encode(enc, multilingual), opts...)
+				be.Err(t, err, nil)
+				be.Equal(t, got, enc)
+				be.Equal(t, string(out), multilingual)
+
+				out, got, err = utfbom.DecodeToUTF8(enc.Bytes(), opts...)
+				be.Err(t, err, nil)
+				be.Equal(t, got, enc)
+				be.Equal(t, len(out), 0)
+			}
+
+			out, _, err := utfbom.DecodeToUTF8(encode(enc, multilingual), utfbom.Passthrough())
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), "\ufeff"+multilingual)
+		})
+	}
+}
+
+func TestDecodeToUTF8_NoBOM(t *testing.T) {
+	t.Parallel()
+
+	input := []byte("h\xffllo")
+
+	out, enc, err := utfbom.DecodeToUTF8(input, utfbom.RejectInvalid())
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.Unknown)
+	be.Equal(t, string(out), "h\xffllo")
+	be.Equal(t, &out[0], &input[0])
+}
+
+func TestDecodeToUTF8_Invalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		replaced string
+		offset   int
+	}{
+		{"utf8", []byte("\xef\xbb\xbfab\xffc"), "ab\xffc", 5},
+		{"utf16le_lone_low", []byte{0xff, 0xfe, 'a', 0x00, 0x00, 0xdc, 'b', 0x00}, "a�b", 4},
+		{"utf16le_lone_high", []byte{0xff, 0xfe, 'a', 0x00, 0x00, 0xd8, 'b', 0x00}, "a�b", 4},
+		{"utf16be_high_at_end", []byte{0xfe, 0xff, 0x00, 'a', 0xd8, 0x00}, "a�", 4},
+		{"utf16be_odd_length", []byte{0xfe, 0xff, 0x00, 'a', 0x00}, "a�", 4},
+		{"utf32be_surrogate", []byte{0x00, 0x00, 0xfe, 0xff, 0x00, 0x00, 0xd8, 0x00}, "�", 4},
+		{"utf32le_out_of_range", []byte{0xff, 0xfe, 0x00, 0x00, 'a', 0x00, 0x00, 0x00, 0x00, 0x00, 0x11, 0x00}, "a�", 8},
+		{"utf32be_truncated", []byte{0x00, 0x00, 0xfe, 0xff, 0x00, 0x00}, "�", 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, _, err := utfbom.DecodeToUTF8(tc.input)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.replaced)
+
+			out, _, err = utfbom.DecodeToUTF8(tc.input, utfbom.RejectInvalid())
+			be.True(t, errors.Is(err, utfbom.ErrInvalidSequence))
+			be.Err(t, err, fmt.Sprintf("at offset %d", tc.offset))
+			be.Equal(t, out, nil)
+		})
+	}
+}
+
+func TestDecodeToUTF8_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, enc, err := utfbom.DecodeToUTF8([]byte("+/v8-hello"), utfbom.WithExtendedBOMs())
+	be.True(t, errors.Is(err, utfbom.ErrUnsupportedEncoding))
+	be.Equal(t, enc, utfbom.UTF7)
+
+	_, enc, err = utfbom.DecodeToUTF8([]byte("\xff\xfeh\x00"), utfbom.Forbid())
+	be.True(t, errors.Is(err, utfbom.ErrBOMForbidden))
+	be.Equal(t, enc, utfbom.UTF16LittleEndian)
+}
+
+func TestEncodeFromUTF8(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			withBOM := encode(enc, multilingual)
+			noBOM := withBOM[enc.Len():]
+
+			testCases := []struct {
+				policy   utfbom.BOMPolicy
+				input    string
+				expected []byte
+			}{
+				{utfbom.UseBOM, multilingual, withBOM},
+				{utfbom.ExpectBOM, multilingual, withBOM},
+				{utfbom.ForbidBOM, multilingual, noBOM},
+				{utfbom.IgnoreBOM, multilingual, noBOM},
+				{utfbom.UseBOM, "\ufeff" + multilingual, withBOM},
+				{utfbom.ForbidBOM, "\ufeff" + multilingual, noBOM},
+				{utfbom.IgnoreBOM, "\ufeff" + multilingual, withBOM},
+				{utfbom.UseBOM, "", enc.Bytes()},
+			}
+
+			for _, tc := range testCases {
+				out, err := utfbom.EncodeFromUTF8([]byte(tc.input), enc, tc.policy)
+				be.Err(t, err, nil)
+				be.Equal(t, out, tc.expected)
+
+				if !utfbom.Has(out) {
+					continue
+				}
+
+				decoded, _, err := utfbom.DecodeToUTF8(out, utfbom.RejectInvalid())
+				be.Err(t, err, nil)
+				be.Equal(t, string(decoded), strings.TrimPrefix(tc.input, "\ufeff"))
+			}
+		})
+	}
+}
+
+func TestEncodeFromUTF8_InvalidInput(t *testing.T) {
+	t.Parallel()
+
+	out, err := utfbom.EncodeFromUTF8([]byte("a\xffb"), utfbom.UTF16LittleEndian, utfbom.IgnoreBOM)
+	be.Err(t, err, nil)
+	be.Equal(t, out, []byte{'a', 0x00, 0xfd, 0xff, 'b', 0x00})
+}
+
+func TestEncodeFromUTF8_Errors(t *testing.T) {
+	t.Parallel()
+
+	out, err := utfbom.EncodeFromUTF8([]byte("hello"), utfbom.Unknown, utfbom.UseBOM)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "hello")
+
+	_, err = utfbom.EncodeFromUTF8([]byte("hello"), utfbom.UTF7, utfbom.UseBOM)
+	be.True(t, errors.Is(err, utfbom.ErrUnsupportedEncoding))
+}
+
+func ExampleEncodeFromUTF8() {
+	// SQL Server bulk import expects UTF-16 Little Endian with a BOM
+	out, err := utfbom.EncodeFromUTF8([]byte("id,name\n"), utfbom.UTF16LittleEndian, utfbom.UseBOM)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("% x\n", out)
+
+	// output:
+	// ff fe 69 00 64 00 2c 00 6e 00 61 00 6d 00 65 00 0a 00
+}
+
+func ExampleDecodeToUTF8() {
+	// "héllo" in UTF-16 Little Endian with a BOM, as saved by Windows Notepad
+	input := []byte{0xff, 0xfe, 'h', 0x00, 0xe9, 0x00, 'l', 0x00, 'l', 0x00, 'o', 0x00}
+
+	out, enc, err := utfbom.DecodeToUTF8(input)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(enc, string(out))
+
+	// output:
+	// UTF16LittleEndian héllo
+}
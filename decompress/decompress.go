@@ -0,0 +1,108 @@
+// Package decompress removes the Byte Order Mark (BOM) of compressed streams, such as rotated logs and exports.
+//
+// It lives apart from package utfbom so that the zstd decoder is only linked into programs which use it.
+package decompress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/slash3b/utfbom"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// NewReader returns a utfbom.Reader detecting and removing the BOM of the decompressed payload of rd.
+// Gzip and zstd streams are told apart by their magic bytes and decompressed on the fly,
+// other streams are read as is, so BOMs of exported logs are dealt with whether they are compressed or not.
+//
+// Options apply the same way as for utfbom.NewReader. Errors of the wrapped reader and decompression errors,
+// such as a corrupt stream, are returned by Read as a utfbom.ReadError with the "decompress" operation,
+// whose offset counts decompressed bytes, and so match utfbom.ErrRead.
+// Passing a nil reader will cause a panic on the first Read call.
+func NewReader(rd io.Reader, opts ...utfbom.Option) *utfbom.Reader {
+	return utfbom.NewReader(&decompressor{rd: rd}, opts...)
+}
+
+// decompressor decompresses the stream it wraps according to its magic bytes, sniffed on the first Read.
+type decompressor struct {
+	rd    io.Reader
+	dec   io.Reader // nil until the first Read
+	close func()    // releases the zstd decoder once the stream is over
+	off   int64     // decompressed bytes read so far
+	err   error     // sticky error of setting up decompression or of the end of the stream
+}
+
+// Read implements the io.Reader interface.
+// Once the stream is over, every later Read returns the same error, io.EOF at the end of a complete stream.
+func (d *decompressor) Read(p []byte) (int, error) {
+	if d.dec == nil && d.err == nil {
+		d.dec, d.err = d.open()
+		if d.err != nil {
+			d.err = d.wrap(d.err)
+		}
+	}
+
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	n, err := d.dec.Read(p)
+	d.off += int64(n)
+
+	if err == nil {
+		return n, nil
+	}
+
+	// the decoder is done with, it must not be read from again
+	d.err = d.wrap(err)
+
+	if d.close != nil {
+		d.close()
+		d.close = nil
+	}
+
+	return n, d.err
+}
+
+// wrap returns err as a utfbom.ReadError, except for io.EOF and errors already holding a ReadError.
+func (d *decompressor) wrap(err error) error {
+	var rerr *utfbom.ReadError
+	if errors.Is(err, io.EOF) || errors.As(err, &rerr) {
+		return err
+	}
+
+	return &utfbom.ReadError{Op: "decompress", Offset: d.off, Err: err}
+}
+
+// open sets up the reader of the decompressed stream.
+func (d *decompressor) open() (io.Reader, error) {
+	br := bufio.NewReader(d.rd)
+
+	// a short stream is neither gzip nor zstd, the error shows up again on the next read
+	head, _ := br.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(head, zstdMagic):
+		// a single decoder works synchronously, without goroutines to leak
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+
+		d.close = zr.Close
+
+		return zr, nil
+	default:
+		return br, nil
+	}
+}
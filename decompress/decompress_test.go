@@ -0,0 +1,186 @@
+package decompress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+	"github.com/slash3b/utfbom/decompress"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(s))
+	be.Err(t, err, nil)
+	be.Err(t, zw.Close(), nil)
+
+	return buf.Bytes()
+}
+
+func zstded(t *testing.T, s string) []byte {
+	t.Helper()
+
+	enc, err := zstd.NewWriter(nil)
+	be.Err(t, err, nil)
+
+	defer enc.Close()
+
+	return enc.EncodeAll([]byte(s), nil)
+}
+
+func TestNewReader(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("log line\n", 10000)
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected string
+		enc      utfbom.Encoding
+	}{
+		{"gzip", gzipped(t, "\ufeffa,b"), "a,b", utfbom.UTF8},
+		{"gzip_without_bom", gzipped(t, "a,b"), "a,b", utfbom.Unknown},
+		{"gzip_long", gzipped(t, "\ufeff"+long), long, utfbom.UTF8},
+		{"zstd", zstded(t, "\xff\xfea\x00"), "a\x00", utfbom.UTF16LittleEndian},
+		{"zstd_long", zstded(t, "\ufeff"+long), long, utfbom.UTF8},
+		{"plain", []byte("\ufeffa,b"), "a,b", utfbom.UTF8},
+		{"short", []byte{0x1f}, "\x1f", utfbom.Unknown},
+		{"empty", nil, "", utfbom.Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd := decompress.NewReader(bytes.NewReader(tc.input))
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+			be.Equal(t, rd.Enc, tc.enc)
+		})
+	}
+}
+
+func TestNewReader_Options(t *testing.T) {
+	t.Parallel()
+
+	rd := decompress.NewReader(bytes.NewReader(gzipped(t, "\ufeffa,b")), utfbom.WithPolicy(utfbom.IgnoreBOM))
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\ufeffa,b")
+}
+
+func TestNewReader_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input []byte
+		err   error
+	}{
+		{"gzip_header", []byte{0x1f, 0x8b, 0, 0, 0, 0, 0, 0, 0, 0}, gzip.ErrHeader},
+		{"gzip_truncated", gzipped(t, "\ufeffa,b")[:15], io.ErrUnexpectedEOF},
+		{"zstd_truncated", zstded(t, "\ufeffa,b")[:6], io.ErrUnexpectedEOF},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := io.ReadAll(decompress.NewReader(bytes.NewReader(tc.input)))
+			be.True(t, errors.Is(err, utfbom.ErrRead))
+			be.True(t, errors.Is(err, tc.err))
+
+			// the error of the decompressor isn't wrapped again while detecting the BOM
+			var rerr *utfbom.ReadError
+
+			be.True(t, errors.As(err, &rerr))
+			be.Equal(t, rerr.Op, "decompress")
+			be.True(t, !errors.As(rerr.Err, &rerr))
+		})
+	}
+}
+
+func TestNewReader_ReadAfterEOF(t *testing.T) {
+	t.Parallel()
+
+	for name, input := range map[string][]byte{"gzip": gzipped(t, "\ufeffhello"), "zstd": zstded(t, "\ufeffhello")} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var failed []error
+
+			rd := decompress.NewReader(bytes.NewReader(input), utfbom.WithOnError(func(err error) {
+				failed = append(failed, err)
+			}))
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), "hello")
+
+			for range 2 {
+				n, err := rd.Read(make([]byte, 8))
+				be.Equal(t, n, 0)
+				be.Equal(t, err, io.EOF)
+			}
+
+			be.Equal(t, len(failed), 0)
+		})
+	}
+}
+
+func TestNewReader_CorruptMidStream(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("log line\n", 100000)
+
+	for name, input := range map[string][]byte{"gzip": gzipped(t, "\ufeff"+long), "zstd": zstded(t, "\ufeff"+long)} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := io.ReadAll(decompress.NewReader(bytes.NewReader(input[:len(input)/2])))
+			be.Err(t, err, utfbom.ErrRead)
+			be.Err(t, err, io.ErrUnexpectedEOF)
+
+			var rerr *utfbom.ReadError
+
+			be.True(t, errors.As(err, &rerr))
+			be.Equal(t, rerr.Op, "decompress")
+			be.Equal(t, rerr.Offset, int64(len("\ufeff")+len(out)))
+			be.True(t, len(out) > 0)
+		})
+	}
+}
+
+func ExampleNewReader() {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte("\ufeffexported log\n"))
+	_ = zw.Close()
+
+	rd := decompress.NewReader(&buf)
+
+	out, err := io.ReadAll(rd)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s %q\n", rd.Enc, out)
+	// output:
+	// UTF8 "exported log\n"
+}
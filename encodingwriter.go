@@ -0,0 +1,246 @@
+package utfbom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var _ io.WriteCloser = (*EncodingWriter)(nil)
+
+// errWriterClosed is returned by EncodingWriter.Write after Close.
+var errWriterClosed = errors.New("utfbom: write to closed EncodingWriter")
+
+// EncodingWriter converts UTF-8 text written to it into UTF-16 or UTF-32
+// and writes the result, prefixed with the Byte Order Mark (BOM), to an io.Writer object.
+// It is the writing counterpart of UTF8Reader.
+//
+// Runes split across Write calls are put together, so call Close after the last Write
+// to write out the trailing incomplete rune, if any, as utf8.RuneError (U+FFFD).
+// Invalid UTF-8 sequences are converted to utf8.RuneError as well, except for UTF-8 output,
+// which is copied as is, same as with EncodeFromUTF8.
+// Same as Writer, the BOM is written lazily, right before the first payload byte.
+//
+// EncodingWriter is not safe for concurrent use.
+type EncodingWriter struct {
+	wr       io.Writer
+	enc      Encoding
+	order    binary.AppendByteOrder // nil for UTF8
+	bom      []byte                 // BOM bytes that still have to be written
+	strip    bool                   // a leading UTF-8 BOM of the input is yet to be removed
+	pending  [utf8.UTFMax]byte      // pending[:npending] is an incomplete rune
+	npending int
+	out      []byte // encoded bytes not written yet
+	err      error  // sticky error
+}
+
+// NewEncodingWriter wraps an outgoing writer with a writer converting UTF-8 text to enc.
+// A UTF-8 BOM at the beginning of the text is removed, and the BOM of enc is written
+// under the UseBOM and ExpectBOM policies, UseBOM being the default.
+// Under IgnoreBOM, a BOM at the beginning of the text is converted along with the rest of it,
+// and no BOM is added.
+//
+// Unknown encoding passes all writes through unchanged. Encodings other than UTF-8, UTF-16 and UTF-32
+// make Write fail with ErrUnsupportedEncoding.
+func NewEncodingWriter(wr io.Writer, enc Encoding, opts ...Option) *EncodingWriter {
+	o := newOptions(opts)
+
+	w := &EncodingWriter{
+		wr:    wr,
+		enc:   enc,
+		strip: o.policy != IgnoreBOM,
+	}
+
+	w.order, _ = byteOrder(enc).(binary.AppendByteOrder)
+
+	if o.policy.writesBOM() {
+		w.bom = enc.Bytes()
+	}
+
+	if enc != Unknown && enc != UTF8 && !enc.IsUTF16() && !enc.IsUTF32() {
+		w.err = fmt.Errorf("%w: %s", ErrUnsupportedEncoding, enc)
+	}
+
+	return w
+}
+
+// Write implements the io.Writer interface.
+// It converts p to the target encoding and writes the result to the underlying Writer,
+// except for a trailing incomplete rune, which is held back until the next call.
+// The returned byte count is the number of UTF-8 bytes consumed from p.
+func (w *EncodingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if w.enc == Unknown {
+		return w.wr.Write(p)
+	}
+
+	n := len(p)
+
+	// complete the pending rune, or the leading BOM, byte by byte
+	for (w.npending > 0 || w.strip) && len(p) > 0 {
+		w.pending[w.npending] = p[0]
+		w.npending++
+		p = p[1:]
+
+		w.resolvePending(false)
+	}
+
+	if w.npending > 0 || w.strip {
+		return n, w.flush()
+	}
+
+	// hold back a trailing incomplete rune
+	end := len(p)
+
+	for i := len(p) - 1; i >= 0 && i >= len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				end = i
+			}
+
+			break
+		}
+	}
+
+	for i := 0; i < end; {
+		var size int
+
+		w.out, size = w.appendRune(w.out, p[i:end])
+		i += size
+
+		if len(w.out) >= defaultBufSize {
+			err := w.flush()
+			if err != nil {
+				return n - len(p) + i, err
+			}
+		}
+	}
+
+	w.npending = copy(w.pending[:], p[end:])
+
+	err := w.flush()
+	if err != nil {
+		return n - len(p) + end, err
+	}
+
+	return n, nil
+}
+
+// resolvePending encodes the pending bytes once they form a rune, or all of them if atEOF is set.
+func (w *EncodingWriter) resolvePending(atEOF bool) {
+	pending := w.pending[:w.npending]
+
+	if w.strip {
+		if !atEOF && len(pending) < len(zwnbsp) && string(pending) == zwnbsp[:len(pending)] {
+			return
+		}
+
+		w.strip = false
+
+		if string(pending) == zwnbsp {
+			w.npending = 0
+
+			return
+		}
+	}
+
+	for len(pending) > 0 && (atEOF || utf8.FullRune(pending)) {
+		var size int
+
+		w.out, size = w.appendRune(w.out, pending)
+		pending = pending[size:]
+	}
+
+	w.npending = copy(w.pending[:], pending)
+}
+
+// appendRune appends the first rune of p in the target encoding to b
+// and returns the extended buffer along with the size of the rune in p.
+// UTF-8 is copied as is.
+func (w *EncodingWriter) appendRune(b, p []byte) ([]byte, int) {
+	r, size := utf8.DecodeRune(p)
+
+	switch w.enc.Family() {
+	case FamilyUTF16:
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			b = w.order.AppendUint16(b, uint16(r1))
+
+			return w.order.AppendUint16(b, uint16(r2)), size
+		}
+
+		return w.order.AppendUint16(b, uint16(r)), size
+	case FamilyUTF32:
+		return w.order.AppendUint32(b, uint32(r)), size
+	default:
+		return append(b, p[:size]...), size
+	}
+}
+
+// flush writes the pending BOM, if any, and the encoded bytes.
+func (w *EncodingWriter) flush() error {
+	if len(w.out) == 0 {
+		return nil
+	}
+
+	if len(w.bom) != 0 {
+		n, err := w.wr.Write(w.bom)
+		w.bom = w.bom[n:]
+
+		if err == nil && len(w.bom) != 0 {
+			err = io.ErrShortWrite
+		}
+
+		if err != nil {
+			w.err = errors.Join(ErrWrite, err)
+
+			return w.err
+		}
+	}
+
+	n, err := w.wr.Write(w.out)
+	if err == nil && n < len(w.out) {
+		err = io.ErrShortWrite
+	}
+
+	w.out = w.out[:0]
+
+	if err != nil {
+		w.err = err
+	}
+
+	return err
+}
+
+// Close writes out the held back incomplete rune, if any, as utf8.RuneError.
+// It does not close the underlying Writer. Writing after Close fails.
+func (w *EncodingWriter) Close() error {
+	if errors.Is(w.err, errWriterClosed) {
+		return nil
+	}
+
+	if w.err != nil {
+		return w.err
+	}
+
+	w.resolvePending(true)
+
+	err := w.flush()
+	if err != nil {
+		return err
+	}
+
+	w.err = errWriterClosed
+
+	return nil
+}
+
+// Unwrap returns the writer wrapped by w, see Writer.Unwrap.
+func (w *EncodingWriter) Unwrap() io.Writer {
+	return w.wr
+}
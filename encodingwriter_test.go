@@ -0,0 +1,175 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestEncodingWriter(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	inputs := []string{
+		"",
+		"h",
+		multilingual,
+		"\ufeff" + multilingual,
+		"\ufeff",
+		"\xef\xbb",
+		"\xef\xbbx",
+		"a\xffb\xe4\xb8",
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			for _, input := range inputs {
+				for _, policy := range []utfbom.BOMPolicy{utfbom.UseBOM, utfbom.IgnoreBOM} {
+					expected, err := utfbom.EncodeFromUTF8([]byte(input), enc, policy)
+					be.Err(t, err, nil)
+
+					if len(expected) == len(enc.Bytes()) && policy == utfbom.UseBOM {
+						// the BOM is written before the first payload byte only
+						expected = nil
+					}
+
+					// every split of the input into two writes
+					for i := range len(input) + 1 {
+						var out bytes.Buffer
+
+						w := utfbom.NewEncodingWriter(&out, enc, utfbom.WithPolicy(policy))
+
+						n, err := w.Write([]byte(input[:i]))
+						be.Err(t, err, nil)
+						be.Equal(t, n, i)
+
+						n, err = w.Write([]byte(input[i:]))
+						be.Err(t, err, nil)
+						be.Equal(t, n, len(input)-i)
+
+						be.Err(t, w.Close(), nil)
+						be.Equal(t, out.Bytes(), expected)
+					}
+
+					// one byte at a time
+					var out bytes.Buffer
+
+					w := utfbom.NewEncodingWriter(&out, enc, utfbom.WithPolicy(policy))
+
+					for i := range len(input) {
+						_, err := w.Write([]byte{input[i]})
+						be.Err(t, err, nil)
+					}
+
+					be.Err(t, w.Close(), nil)
+					be.Equal(t, out.Bytes(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodingWriter_LargeWrite(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat(multilingual, 1000)
+
+	var out bytes.Buffer
+
+	w := utfbom.NewEncodingWriter(&out, utfbom.UTF16LittleEndian)
+
+	n, err := io.Copy(w, strings.NewReader(input))
+	be.Err(t, err, nil)
+	be.Equal(t, n, int64(len(input)))
+	be.Err(t, w.Close(), nil)
+
+	decoded, enc, err := utfbom.DecodeToUTF8(out.Bytes(), utfbom.RejectInvalid())
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF16LittleEndian)
+	be.Equal(t, string(decoded), input)
+}
+
+func TestEncodingWriter_Unknown(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	w := utfbom.NewEncodingWriter(&out, utfbom.Unknown)
+
+	_, err := w.Write([]byte("\ufeffa\xff"))
+	be.Err(t, err, nil)
+	be.Err(t, w.Close(), nil)
+	be.Equal(t, out.String(), "\ufeffa\xff")
+}
+
+func TestEncodingWriter_Errors(t *testing.T) {
+	t.Parallel()
+
+	w := utfbom.NewEncodingWriter(io.Discard, utfbom.UTF7)
+
+	_, err := w.Write([]byte("hello"))
+	be.True(t, errors.Is(err, utfbom.ErrUnsupportedEncoding))
+
+	errDisk := errors.New("disk full")
+
+	w = utfbom.NewEncodingWriter(errWriter{errDisk}, utfbom.UTF16LittleEndian)
+
+	_, err = w.Write([]byte("hello"))
+	be.True(t, errors.Is(err, utfbom.ErrWrite))
+	be.True(t, errors.Is(err, errDisk))
+
+	// the error is sticky
+	be.True(t, errors.Is(w.Close(), errDisk))
+
+	w = utfbom.NewEncodingWriter(io.Discard, utfbom.UTF16LittleEndian)
+	be.Err(t, w.Close(), nil)
+	be.Err(t, w.Close(), nil)
+
+	_, err = w.Write([]byte("hello"))
+	be.True(t, err != nil)
+}
+
+func ExampleNewEncodingWriter() {
+	w := utfbom.NewEncodingWriter(hexDumper{os.Stdout}, utfbom.UTF16LittleEndian)
+
+	// "é" is split across writes
+	_, _ = w.Write([]byte("caf\xc3"))
+	_, _ = w.Write([]byte("\xa9\n"))
+
+	err := w.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	// output:
+	// ff fe
+	// 63 00 61 00 66 00
+	// e9 00 0a 00
+}
+
+// hexDumper prints every write as a line of hex bytes.
+type hexDumper struct {
+	w io.Writer
+}
+
+func (d hexDumper) Write(p []byte) (int, error) {
+	_, err := fmt.Fprintf(d.w, "% x\n", p)
+
+	return len(p), err
+}
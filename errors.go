@@ -0,0 +1,70 @@
+package utfbom
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReadError records a failure of the reader wrapped by the package, as opposed to
+// a policy violation, reported with ErrBOMExpected or ErrBOMForbidden,
+// or a corrupt payload, reported with a TranscodeError.
+//
+// errors.Is(err, ErrRead) holds for every ReadError, and the error of the wrapped reader
+// is available to errors.Is and errors.As as well.
+type ReadError struct {
+	// Op is the operation that failed: "detect" while reading the beginning of the stream,
+	// "read" past it, "discard" while skipping the BOM, "peek" or "seek",
+	// or "decompress" for the readers of package decompress.
+	Op string
+	// Offset is the number of bytes of the stream read before the failure, BOM included,
+	// or the offset sought for "seek".
+	Offset int64
+	// Err is the error of the wrapped reader.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("utfbom: %s at offset %d: %v", e.Op, e.Offset, e.Err)
+}
+
+// Unwrap returns the error of the wrapped reader.
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrRead, so code checking for ErrRead keeps working.
+func (e *ReadError) Is(target error) bool {
+	return target == ErrRead
+}
+
+// newReadError returns a ReadError for err, or err itself if it already holds one,
+// as when the wrapped reader is one of the package, so that errors aren't wrapped twice.
+func newReadError(op string, offset int64, err error) error {
+	var rerr *ReadError
+	if errors.As(err, &rerr) {
+		return err
+	}
+
+	return &ReadError{Op: op, Offset: offset, Err: err}
+}
+
+// TranscodeError reports a payload that is not valid in its encoding,
+// found under RejectInvalid or WithValidateUTF8 and by Validate.
+// errors.Is(err, ErrInvalidSequence) holds for every TranscodeError.
+type TranscodeError struct {
+	// Offset is the byte offset of the first invalid sequence, BOM included.
+	Offset int64
+	// Encoding is the encoding the payload is invalid in.
+	Encoding Encoding
+}
+
+// Error implements the error interface.
+func (e *TranscodeError) Error() string {
+	return fmt.Sprintf("%v: %s at offset %d", ErrInvalidSequence, e.Encoding, e.Offset)
+}
+
+// Is reports whether target is ErrInvalidSequence.
+func (e *TranscodeError) Is(target error) bool {
+	return target == ErrInvalidSequence
+}
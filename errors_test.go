@@ -0,0 +1,127 @@
+package utfbom_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+// brokenSeeker fails to seek.
+type brokenSeeker struct {
+	io.Reader
+}
+
+func (brokenSeeker) Seek(int64, int) (int64, error) {
+	return 0, errBrokenSeek
+}
+
+var errBrokenSeek = errors.New("broken seek")
+
+func TestReadError(t *testing.T) {
+	t.Parallel()
+
+	errBroken := errors.New("broken")
+
+	testCases := []struct {
+		name     string
+		call     func() error
+		expected utfbom.ReadError
+	}{
+		{"reader_detect", func() error {
+			_, err := io.ReadAll(utfbom.NewReader(io.MultiReader(strings.NewReader("\xef\xbb"), iotest.ErrReader(errBroken))))
+
+			return err
+		}, utfbom.ReadError{Op: "detect", Offset: 2, Err: errBroken}},
+		{"read_all", func() error {
+			_, _, err := utfbom.ReadAll(io.MultiReader(strings.NewReader("\ufeffhello"), iotest.ErrReader(errBroken)))
+
+			return err
+		}, utfbom.ReadError{Op: "read", Offset: 8, Err: errBroken}},
+		{"copy", func() error {
+			_, _, err := utfbom.Copy(io.Discard, iotest.ErrReader(errBroken))
+
+			return err
+		}, utfbom.ReadError{Op: "detect", Offset: 0, Err: errBroken}},
+		{"validate", func() error {
+			_, err := utfbom.Validate(io.MultiReader(strings.NewReader("\ufeffhello"), iotest.ErrReader(errBroken)))
+
+			return err
+		}, utfbom.ReadError{Op: "read", Offset: 8, Err: errBroken}},
+		{"seek", func() error {
+			_, err := utfbom.NewReader(brokenSeeker{strings.NewReader("hello")}).Seek(3, io.SeekStart)
+
+			return err
+		}, utfbom.ReadError{Op: "seek", Offset: 3, Err: errBrokenSeek}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.call()
+			be.Err(t, err, utfbom.ErrRead)
+			be.Err(t, err, tc.expected.Err)
+
+			var re *utfbom.ReadError
+
+			be.True(t, errors.As(err, &re))
+			be.Equal(t, *re, tc.expected)
+		})
+	}
+}
+
+func TestReadError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &utfbom.ReadError{Op: "detect", Offset: 2, Err: io.ErrClosedPipe}
+	be.Equal(t, err.Error(), "utfbom: detect at offset 2: io: read/write on closed pipe")
+	be.True(t, !errors.Is(err, utfbom.ErrWrite))
+}
+
+func TestTranscodeError(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := utfbom.DecodeToUTF8([]byte("\xfe\xff\xd8\x00"), utfbom.RejectInvalid())
+	be.Err(t, err, utfbom.ErrInvalidSequence)
+	be.Err(t, err, "utfbom: invalid sequence: UTF16BigEndian at offset 2")
+
+	var te *utfbom.TranscodeError
+
+	be.True(t, errors.As(err, &te))
+	be.Equal(t, *te, utfbom.TranscodeError{Offset: 2, Encoding: utfbom.UTF16BigEndian})
+	be.True(t, !errors.Is(err, utfbom.ErrRead))
+
+	_, err = io.ReadAll(utfbom.NewUTF8Reader(strings.NewReader("\xff\xfeh\x00\x00\xdc"), utfbom.RejectInvalid()))
+	be.True(t, errors.As(err, &te))
+	be.Equal(t, *te, utfbom.TranscodeError{Offset: 4, Encoding: utfbom.UTF16LittleEndian})
+}
+
+func ExampleReadError() {
+	rd := utfbom.NewReader(strings.NewReader("\ufeffid,name\n"), utfbom.Forbid())
+
+	_, err := io.ReadAll(rd)
+
+	var (
+		re *utfbom.ReadError
+		te *utfbom.TranscodeError
+	)
+
+	switch {
+	case errors.As(err, &re):
+		fmt.Println("I/O failed at byte", re.Offset)
+	case errors.As(err, &te):
+		fmt.Println("corrupt payload at byte", te.Offset)
+	case errors.Is(err, utfbom.ErrBOMForbidden), errors.Is(err, utfbom.ErrBOMExpected):
+		fmt.Println("policy violation:", err)
+	default:
+		fmt.Println(err)
+	}
+	// output:
+	// policy violation: utfbom: BOM is forbidden: UTF8
+}
@@ -0,0 +1,103 @@
+package utfbom
+
+// Endianness is the byte order of an encoding made of multibyte code units.
+type Endianness int
+
+const (
+	// EndiannessNA means the byte order is not applicable: the encoding is byte oriented or unknown.
+	EndiannessNA Endianness = iota
+
+	// BigEndian means the most significant byte of a code unit comes first.
+	BigEndian
+
+	// LittleEndian means the least significant byte of a code unit comes first.
+	LittleEndian
+)
+
+// String returns the human-readable name of the byte order.
+func (e Endianness) String() string {
+	switch e {
+	case BigEndian:
+		return "BigEndian"
+	case LittleEndian:
+		return "LittleEndian"
+	default:
+		return "NA"
+	}
+}
+
+// Family groups the encodings sharing a code unit size and differing by byte order only.
+type Family int
+
+const (
+	// FamilyUnknown is the family of Unknown and registered encodings.
+	FamilyUnknown Family = iota
+
+	// FamilyUTF8 is the family of UTF8.
+	FamilyUTF8
+
+	// FamilyUTF16 is the family of UTF16BigEndian and UTF16LittleEndian.
+	FamilyUTF16
+
+	// FamilyUTF32 is the family of UTF32BigEndian and UTF32LittleEndian.
+	FamilyUTF32
+
+	// FamilyOther is the family of the remaining built-in encodings, such as UTF7 or GB18030.
+	FamilyOther
+)
+
+// String returns the human-readable name of the family.
+func (f Family) String() string {
+	switch f {
+	case FamilyUTF8:
+		return "UTF8"
+	case FamilyUTF16:
+		return "UTF16"
+	case FamilyUTF32:
+		return "UTF32"
+	case FamilyOther:
+		return "Other"
+	default:
+		return "Unknown"
+	}
+}
+
+// Family returns the family of the encoding.
+func (e Encoding) Family() Family {
+	switch e {
+	case Unknown:
+		return FamilyUnknown
+	case UTF8:
+		return FamilyUTF8
+	case UTF16BigEndian, UTF16LittleEndian:
+		return FamilyUTF16
+	case UTF32BigEndian, UTF32LittleEndian:
+		return FamilyUTF32
+	case UTF7, UTF1, UTFEBCDIC, SCSU, BOCU1, GB18030:
+		return FamilyOther
+	default:
+		return FamilyUnknown
+	}
+}
+
+// Endianness returns the byte order of UTF-16 and UTF-32 encodings, EndiannessNA for others.
+func (e Encoding) Endianness() Endianness {
+	switch e {
+	case UTF16BigEndian, UTF32BigEndian:
+		return BigEndian
+	case UTF16LittleEndian, UTF32LittleEndian:
+		return LittleEndian
+	default:
+		return EndiannessNA
+	}
+}
+
+// IsUTF16 reports whether the encoding is UTF-16 of either byte order.
+func (e Encoding) IsUTF16() bool {
+	return e.Family() == FamilyUTF16
+}
+
+// IsUTF32 reports whether the encoding is UTF-32 of either byte order.
+func (e Encoding) IsUTF32() bool {
+	return e.Family() == FamilyUTF32
+}
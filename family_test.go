@@ -0,0 +1,71 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestEncoding_Family(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		enc        utfbom.Encoding
+		family     utfbom.Family
+		endianness utfbom.Endianness
+	}{
+		{utfbom.Unknown, utfbom.FamilyUnknown, utfbom.EndiannessNA},
+		{utfbom.UTF8, utfbom.FamilyUTF8, utfbom.EndiannessNA},
+		{utfbom.UTF16BigEndian, utfbom.FamilyUTF16, utfbom.BigEndian},
+		{utfbom.UTF16LittleEndian, utfbom.FamilyUTF16, utfbom.LittleEndian},
+		{utfbom.UTF32BigEndian, utfbom.FamilyUTF32, utfbom.BigEndian},
+		{utfbom.UTF32LittleEndian, utfbom.FamilyUTF32, utfbom.LittleEndian},
+		{utfbom.UTF7, utfbom.FamilyOther, utfbom.EndiannessNA},
+		{utfbom.UTF1, utfbom.FamilyOther, utfbom.EndiannessNA},
+		{utfbom.UTFEBCDIC, utfbom.FamilyOther, utfbom.EndiannessNA},
+		{utfbom.SCSU, utfbom.FamilyOther, utfbom.EndiannessNA},
+		{utfbom.BOCU1, utfbom.FamilyOther, utfbom.EndiannessNA},
+		{utfbom.GB18030, utfbom.FamilyOther, utfbom.EndiannessNA},
+		{utfbom.Encoding(999), utfbom.FamilyUnknown, utfbom.EndiannessNA},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, tc.enc.Family(), tc.family)
+			be.Equal(t, tc.enc.Endianness(), tc.endianness)
+			be.Equal(t, tc.enc.IsUTF16(), tc.family == utfbom.FamilyUTF16)
+			be.Equal(t, tc.enc.IsUTF32(), tc.family == utfbom.FamilyUTF32)
+		})
+	}
+}
+
+func TestFamily_String(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.FamilyUnknown.String(), "Unknown")
+	be.Equal(t, utfbom.FamilyUTF8.String(), "UTF8")
+	be.Equal(t, utfbom.FamilyUTF16.String(), "UTF16")
+	be.Equal(t, utfbom.FamilyUTF32.String(), "UTF32")
+	be.Equal(t, utfbom.FamilyOther.String(), "Other")
+}
+
+func TestEndianness_String(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.EndiannessNA.String(), "NA")
+	be.Equal(t, utfbom.BigEndian.String(), "BigEndian")
+	be.Equal(t, utfbom.LittleEndian.String(), "LittleEndian")
+}
+
+func ExampleEncoding_Family() {
+	enc := utfbom.DetectEncoding([]byte{0xff, 0xfe, 'h', 0x00})
+
+	fmt.Println(enc.Family(), enc.Endianness(), enc.IsUTF16())
+
+	// output:
+	// UTF16 LittleEndian true
+}
@@ -0,0 +1,203 @@
+package utfbom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileOption configures functions that rewrite files on disk.
+type FileOption func(*fileConfig)
+
+type fileConfig struct {
+	modTime bool
+}
+
+// PreserveModTime keeps the modification time of the original file.
+func PreserveModTime() FileOption {
+	return func(c *fileConfig) {
+		c.modTime = true
+	}
+}
+
+// Open opens the named file for reading, detects its Byte Order Mark (BOM) right away
+// and returns a reader positioned after the BOM along with the detected encoding.
+// Closing the returned reader closes the file. Options are applied the same way as for Reader.
+//
+// If detection fails, for instance under the ForbidBOM policy, the file is closed
+// and the error is returned.
+func Open(name string, opts ...Option) (*ReadCloser, Encoding, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, Unknown, err
+	}
+
+	rc := NewReadCloser(f, opts...)
+
+	enc, err := rc.Encoding()
+	if err != nil {
+		_ = f.Close()
+
+		return nil, enc, err
+	}
+
+	return rc, enc, nil
+}
+
+// DetectFile returns the encoding of the Byte Order Mark (BOM) of the named file,
+// or Unknown if it has none. Only the first 4 bytes of the file are read.
+func DetectFile(name string) (Encoding, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return Unknown, err
+	}
+	defer f.Close()
+
+	var buf [maxBOMLen]byte
+
+	n, err := io.ReadFull(f, buf[:])
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Unknown, newReadError("detect", int64(n), err)
+	}
+
+	return DetectEncoding(buf[:n]), nil
+}
+
+// TrimFile removes a leading Byte Order Mark (BOM) from the named file
+// and returns the encoding of the removed BOM.
+//
+// The file is streamed into a temporary file in the same directory which then
+// replaces the original, so a failure never leaves the file half-written.
+// The permission bits of the original are kept, but not its setuid, setgid and sticky bits,
+// as the replacement belongs to the user running the process. A symbolic link is followed,
+// so that its target is rewritten and the link is left in place.
+// A file without a BOM is not touched and Unknown is returned.
+func TrimFile(name string, opts ...FileOption) (Encoding, error) {
+	enc := Unknown
+
+	err := rewriteFile(name, opts, func(src io.Reader) (io.Reader, error) {
+		rd, found, err := Skip(src)
+		if err != nil || found == Unknown {
+			return nil, err
+		}
+
+		enc = found
+
+		return rd, nil
+	})
+	if err != nil {
+		return Unknown, err
+	}
+
+	return enc, nil
+}
+
+// PrependFile adds the Byte Order Mark (BOM) of enc to the beginning of the named file.
+// The file is left untouched if enc is Unknown or if the file already starts with any BOM.
+//
+// Same as TrimFile, the content is streamed into a temporary file which then
+// replaces the original, so files of any size can be processed.
+func PrependFile(name string, enc Encoding, opts ...FileOption) error {
+	if enc == Unknown {
+		return nil
+	}
+
+	return rewriteFile(name, opts, func(src io.Reader) (io.Reader, error) {
+		rd, found, err := Skip(src)
+		if err != nil || found != Unknown {
+			return nil, err
+		}
+
+		return io.MultiReader(bytes.NewReader(enc.Bytes()), rd), nil
+	})
+}
+
+// rewriteFile replaces the named file, or the target of the named symbolic link, with the content returned by prepare.
+// The file is left untouched if prepare returns a nil reader.
+func rewriteFile(name string, opts []FileOption, prepare func(src io.Reader) (io.Reader, error)) (err error) {
+	var cfg fileConfig
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	lfi, err := os.Lstat(name)
+	if err != nil {
+		return err
+	}
+
+	// renaming over a symbolic link would replace the link itself with a regular file
+	if lfi.Mode()&fs.ModeSymlink != 0 {
+		name, err = filepath.EvalSymlinks(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("utfbom: %s is not a regular file", name)
+	}
+
+	content, err := prepare(src)
+	if err != nil || content == nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".utfbom-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = io.Copy(tmp, content)
+	if err != nil {
+		return err
+	}
+
+	// the temporary file is created 0600; the special bits are left out, as the owner of the original isn't kept,
+	// and a setuid file belonging to the user running the process, possibly root, is a privilege escalation
+	err = tmp.Chmod(fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	if cfg.modTime {
+		err = os.Chtimes(tmp.Name(), fi.ModTime(), fi.ModTime())
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
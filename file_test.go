@@ -0,0 +1,263 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func writeTempFile(t *testing.T, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "file.txt")
+	be.Err(t, os.WriteFile(path, content, 0o640), nil)
+
+	return path
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		content  []byte
+		enc      utfbom.Encoding
+		expected string
+	}{
+		{"empty", nil, utfbom.Unknown, ""},
+		{"no_bom", []byte("a,b\n"), utfbom.Unknown, "a,b\n"},
+		{"utf8", []byte("\xef\xbb\xbfa,b\n"), utfbom.UTF8, "a,b\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rc, enc, err := utfbom.Open(writeTempFile(t, tc.content))
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.enc)
+
+			out, err := io.ReadAll(rc)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+
+			be.Err(t, rc.Close(), nil)
+
+			_, err = rc.Read(make([]byte, 1))
+			be.Err(t, err, os.ErrClosed)
+		})
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := utfbom.Open(filepath.Join(t.TempDir(), "missing.txt"))
+	be.Err(t, err, os.ErrNotExist)
+
+	rc, enc, err := utfbom.Open(writeTempFile(t, []byte("\xef\xbb\xbfa")), utfbom.Forbid())
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+	be.Equal(t, enc, utfbom.UTF8)
+	be.Equal(t, rc, nil)
+}
+
+func TestDetectFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		content []byte
+		enc     utfbom.Encoding
+	}{
+		{"empty", nil, utfbom.Unknown},
+		{"short", []byte("a"), utfbom.Unknown},
+		{"no_bom", []byte("hello"), utfbom.Unknown},
+		{"utf8", []byte("\xef\xbb\xbfhello"), utfbom.UTF8},
+		{"utf16be", []byte{0xfe, 0xff}, utfbom.UTF16BigEndian},
+		{"utf32le", []byte{0xff, 0xfe, 0x00, 0x00, 'h', 0x00, 0x00, 0x00}, utfbom.UTF32LittleEndian},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			enc, err := utfbom.DetectFile(writeTempFile(t, tc.content))
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.enc)
+		})
+	}
+}
+
+func TestDetectFile_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	_, err := utfbom.DetectFile(filepath.Join(dir, "missing.txt"))
+	be.Err(t, err, os.ErrNotExist)
+
+	_, err = utfbom.DetectFile(dir)
+	be.Err(t, err, utfbom.ErrRead)
+}
+
+func TestTrimFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		enc      utfbom.Encoding
+		expected []byte
+	}{
+		{"empty", []byte{}, utfbom.Unknown, []byte{}},
+		{"no_bom", []byte("hello"), utfbom.Unknown, []byte("hello")},
+		{"only_bom", utf8BOM, utfbom.UTF8, []byte{}},
+		{"utf8", append(utf8BOM, "hello"...), utfbom.UTF8, []byte("hello")},
+		{"utf16le", append(utf16LEBOM, 'h', 0x00), utfbom.UTF16LittleEndian, []byte{'h', 0x00}},
+		{"utf32be", append(utf32BEBOM, 0x00, 0x00, 0x00, 'h'), utfbom.UTF32BigEndian, []byte{0x00, 0x00, 0x00, 'h'}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeTempFile(t, tc.input)
+
+			enc, err := utfbom.TrimFile(path)
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.enc)
+
+			out, err := os.ReadFile(path)
+			be.Err(t, err, nil)
+			be.Equal(t, out, tc.expected)
+
+			entries, err := os.ReadDir(filepath.Dir(path))
+			be.Err(t, err, nil)
+			be.Equal(t, len(entries), 1)
+		})
+	}
+}
+
+func TestTrimFile_Preserve(t *testing.T) {
+	t.Parallel()
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	path := writeTempFile(t, append(utf8BOM, "hello"...))
+	be.Err(t, os.Chtimes(path, mtime, mtime), nil)
+
+	_, err := utfbom.TrimFile(path, utfbom.PreserveModTime())
+	be.Err(t, err, nil)
+
+	fi, err := os.Stat(path)
+	be.Err(t, err, nil)
+	be.Equal(t, fi.Mode().Perm(), os.FileMode(0o640))
+	be.True(t, fi.ModTime().Equal(mtime))
+}
+
+func TestTrimFile_SpecialBits(t *testing.T) {
+	t.Parallel()
+
+	path := writeTempFile(t, append(utf8BOM, "hello"...))
+
+	be.Err(t, os.Chmod(path, 0o750|os.ModeSetuid|os.ModeSetgid), nil)
+
+	_, err := utfbom.TrimFile(path)
+	be.Err(t, err, nil)
+
+	// the permission bits are kept, the special bits are dropped along with the owner
+	fi, err := os.Stat(path)
+	be.Err(t, err, nil)
+	be.Equal(t, fi.Mode(), os.FileMode(0o750))
+}
+
+func TestTrimFile_Symlink(t *testing.T) {
+	t.Parallel()
+
+	target := writeTempFile(t, append(utf8BOM, "hello"...))
+	link := filepath.Join(t.TempDir(), "link.txt")
+	be.Err(t, os.Symlink(target, link), nil)
+
+	enc, err := utfbom.TrimFile(link)
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF8)
+
+	fi, err := os.Lstat(link)
+	be.Err(t, err, nil)
+	be.True(t, fi.Mode()&os.ModeSymlink != 0)
+
+	out, err := os.ReadFile(target)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "hello")
+
+	entries, err := os.ReadDir(filepath.Dir(target))
+	be.Err(t, err, nil)
+	be.Equal(t, len(entries), 1)
+}
+
+func TestTrimFile_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	_, err := utfbom.TrimFile(filepath.Join(dir, "missing.txt"))
+	be.True(t, os.IsNotExist(err))
+
+	_, err = utfbom.TrimFile(dir)
+	be.Err(t, err, "not a regular file")
+}
+
+func TestPrependFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		enc      utfbom.Encoding
+		expected []byte
+	}{
+		{"empty", []byte{}, utfbom.UTF8, utf8BOM},
+		{"unknown", []byte("hello"), utfbom.Unknown, []byte("hello")},
+		{"utf8", []byte("hello"), utfbom.UTF8, append(utf8BOM, "hello"...)},
+		{"utf16le", []byte{'h', 0x00}, utfbom.UTF16LittleEndian, append(utf16LEBOM, 'h', 0x00)},
+		{"idempotent_when_bom_exists", append(utf8BOM, "hello"...), utfbom.UTF8, append(utf8BOM, "hello"...)},
+		{"idempotent_when_different_bom_exists", append(utf16BEBOM, 0x00, 'h'), utfbom.UTF8, append(utf16BEBOM, 0x00, 'h')},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeTempFile(t, tc.input)
+
+			be.Err(t, utfbom.PrependFile(path, tc.enc), nil)
+
+			out, err := os.ReadFile(path)
+			be.Err(t, err, nil)
+			be.Equal(t, out, tc.expected)
+		})
+	}
+}
+
+func TestPrependFile_LargeFile(t *testing.T) {
+	t.Parallel()
+
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 1<<16)
+	path := writeTempFile(t, payload)
+
+	be.Err(t, utfbom.PrependFile(path, utfbom.UTF8), nil)
+
+	out, err := os.ReadFile(path)
+	be.Err(t, err, nil)
+	be.Equal(t, out, append(utf8BOM, payload...))
+
+	fi, err := os.Stat(path)
+	be.Err(t, err, nil)
+	be.Equal(t, fi.Mode().Perm(), os.FileMode(0o640))
+}
@@ -0,0 +1,70 @@
+package utfbom
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Occurrence is a Byte Order Mark (BOM) or a U+FEFF character found by Find.
+type Occurrence struct {
+	// Offset is the byte offset of the occurrence, 0 for the BOM.
+	Offset int
+	// Encoding is the encoding U+FEFF is encoded in at Offset.
+	Encoding Encoding
+}
+
+// Stray reports whether the occurrence is past the beginning of the buffer,
+// where U+FEFF is a zero width no-break space rather than a BOM, usually left over from concatenated files.
+func (o Occurrence) Stray() bool {
+	return o.Offset != 0
+}
+
+// String describes the occurrence for diagnostics, such as "UTF8 BOM at byte 0" or "stray UTF8 BOM at byte 1042".
+func (o Occurrence) String() string {
+	if o.Stray() {
+		return fmt.Sprintf("stray %s BOM at byte %d", o.Encoding, o.Offset)
+	}
+
+	return fmt.Sprintf("%s BOM at byte %d", o.Encoding, o.Offset)
+}
+
+// Find scans the whole of b for the BOM and for U+FEFF characters past it,
+// and returns their occurrences in order, so that diagnostics can point at the exact byte.
+//
+// The encoding of the BOM tells how U+FEFF is encoded in the rest of b:
+// UTF-16 and UTF-32 occurrences are only found at code unit boundaries,
+// and a buffer without a BOM is scanned as UTF-8.
+// Past the BOM of other encodings, such as registered ones, U+FEFF has no fixed bytes and isn't looked for.
+// Find returns nil if there is no occurrence at all.
+func Find(b []byte) []Occurrence {
+	var found []Occurrence
+
+	enc := DetectEncoding(b)
+	if enc != Unknown {
+		found = append(found, Occurrence{Offset: 0, Encoding: enc})
+	}
+
+	switch {
+	case enc == Unknown || enc == UTF8:
+		for off := enc.Len(); ; off += len(zwnbsp) {
+			i := bytes.Index(b[off:], []byte(zwnbsp))
+			if i < 0 {
+				break
+			}
+
+			off += i
+			found = append(found, Occurrence{Offset: off, Encoding: UTF8})
+		}
+	case enc.IsUTF16() || enc.IsUTF32():
+		unit := enc.bom()
+
+		for off := len(unit); off+len(unit) <= len(b); off += len(unit) {
+			if string(b[off:off+len(unit)]) == unit {
+				found = append(found, Occurrence{Offset: off, Encoding: enc})
+			}
+		}
+	default:
+	}
+
+	return found
+}
@@ -0,0 +1,68 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []utfbom.Occurrence
+	}{
+		{"none", "hello", nil},
+		{"empty", "", nil},
+		{"leading", "\ufeffhello", []utfbom.Occurrence{{Offset: 0, Encoding: utfbom.UTF8}}},
+		{"stray", "a\n\ufeffb", []utfbom.Occurrence{{Offset: 2, Encoding: utfbom.UTF8}}},
+		{"leading_and_stray", "\ufeffa\ufeff\ufeff", []utfbom.Occurrence{
+			{Offset: 0, Encoding: utfbom.UTF8},
+			{Offset: 4, Encoding: utfbom.UTF8},
+			{Offset: 7, Encoding: utfbom.UTF8},
+		}},
+		{"utf16le", "\xff\xfea\x00\xff\xfe", []utfbom.Occurrence{
+			{Offset: 0, Encoding: utfbom.UTF16LittleEndian},
+			{Offset: 4, Encoding: utfbom.UTF16LittleEndian},
+		}},
+		{"utf16le_unaligned", "\xff\xfe\x00\xff\xfe\x00", []utfbom.Occurrence{{Offset: 0, Encoding: utfbom.UTF16LittleEndian}}},
+		{"utf16be_truncated", "\xfe\xff\x00a\xfe", []utfbom.Occurrence{{Offset: 0, Encoding: utfbom.UTF16BigEndian}}},
+		{"utf32be", "\x00\x00\xfe\xff\x00\x00\xfe\xff", []utfbom.Occurrence{
+			{Offset: 0, Encoding: utfbom.UTF32BigEndian},
+			{Offset: 4, Encoding: utfbom.UTF32BigEndian},
+		}},
+		{"utf7_signature_is_no_bom", "+/v8a\ufeff", []utfbom.Occurrence{{Offset: 5, Encoding: utfbom.UTF8}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.Find([]byte(tc.input)), tc.expected)
+		})
+	}
+}
+
+func TestOccurrence_String(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.Occurrence{Offset: 0, Encoding: utfbom.UTF8}.String(), "UTF8 BOM at byte 0")
+	be.Equal(t, utfbom.Occurrence{Offset: 1042, Encoding: utfbom.UTF8}.String(), "stray UTF8 BOM at byte 1042")
+	be.True(t, utfbom.Occurrence{Offset: 1042}.Stray())
+}
+
+func ExampleFind() {
+	// two exports concatenated with cat
+	data := []byte("\ufeffid,name\n1,a\n\ufeffid,name\n2,b\n")
+
+	for _, occ := range utfbom.Find(data) {
+		fmt.Println(occ)
+	}
+	// output:
+	// UTF8 BOM at byte 0
+	// stray UTF8 BOM at byte 15
+}
@@ -2,4 +2,11 @@ module github.com/slash3b/utfbom
 
 go 1.26
 
-require github.com/nalgeon/be v0.2.0
+require (
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/klauspost/compress v1.18.0
+	github.com/nalgeon/be v0.2.0
+	golang.org/x/text v0.40.0
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,266 @@
+package utfbom
+
+import (
+	"cmp"
+	"encoding/binary"
+	"slices"
+	"unicode/utf16"
+)
+
+// heuristicSampleLen is the maximum number of bytes inspected by heuristics.
+const heuristicSampleLen = 4096
+
+// Confidence is the degree of certainty of a detected encoding.
+type Confidence int
+
+const (
+	// NoConfidence means the encoding could not be determined.
+	NoConfidence Confidence = iota
+
+	// LowConfidence means the data is consistent with the encoding,
+	// but the evidence is weak, e.g. the sample is too short.
+	LowConfidence
+
+	// HighConfidence means the data strongly suggests the encoding.
+	HighConfidence
+
+	// Certain means the encoding is identified by its Byte Order Mark (BOM).
+	Certain
+)
+
+// String returns the human-readable name of the confidence level.
+func (c Confidence) String() string {
+	switch c {
+	case LowConfidence:
+		return "LowConfidence"
+	case HighConfidence:
+		return "HighConfidence"
+	case Certain:
+		return "Certain"
+	default:
+		return "NoConfidence"
+	}
+}
+
+// DetectHeuristic detects the encoding of b by its BOM and, if there is none,
+// falls back to statistical analysis of the first 4096 bytes to recognize BOM-less
+// UTF-16 and UTF-32 text.
+//
+// The analysis relies on the null bytes that UTF-16 and UTF-32 produce for
+// Latin-script text, so it reliably recognizes, e.g., Windows-generated UTF-16LE files,
+// but not BOM-less UTF-16 text that consists mostly of non-Latin characters.
+// Unknown with NoConfidence is returned when nothing is recognized.
+func DetectHeuristic(b []byte) (Encoding, Confidence) {
+	enc := DetectEncoding(b)
+	if enc != Unknown {
+		return enc, Certain
+	}
+
+	if len(b) > heuristicSampleLen {
+		b = b[:heuristicSampleLen]
+	}
+
+	enc, c := guessUTF32(b)
+	if c != NoConfidence {
+		return enc, c
+	}
+
+	return guessUTF16(b)
+}
+
+// guessUTF32 recognizes text that consists of valid UTF-32 code points in exactly one byte order.
+func guessUTF32(b []byte) (Encoding, Confidence) {
+	units := len(b) / 4
+
+	le := validUTF32(b, binary.LittleEndian)
+	be := validUTF32(b, binary.BigEndian)
+
+	var enc Encoding
+
+	switch {
+	case le && !be:
+		enc = UTF32LittleEndian
+	case be && !le:
+		enc = UTF32BigEndian
+	default:
+		return Unknown, NoConfidence
+	}
+
+	if units >= 4 && len(b)%4 == 0 {
+		return enc, HighConfidence
+	}
+
+	return enc, LowConfidence
+}
+
+// validUTF32 reports whether b holds at least one code unit,
+// all complete units are valid code points and not all of them are zero.
+func validUTF32(b []byte, order binary.ByteOrder) bool {
+	nonZero := false
+
+	for i := 0; i+4 <= len(b); i += 4 {
+		u := order.Uint32(b[i:])
+		if u > 0x10ffff || utf16.IsSurrogate(rune(u)) {
+			return false
+		}
+
+		nonZero = nonZero || u != 0
+	}
+
+	return nonZero
+}
+
+// guessUTF16 recognizes text where null bytes consistently occupy
+// either even or odd positions, as UTF-16 does for Latin-script text.
+func guessUTF16(b []byte) (Encoding, Confidence) {
+	pairs := len(b) / 2
+	if pairs == 0 {
+		return Unknown, NoConfidence
+	}
+
+	var zeroEven, zeroOdd int
+
+	for i := 0; i+2 <= len(b); i += 2 {
+		if b[i] == 0 {
+			zeroEven++
+		}
+
+		if b[i+1] == 0 {
+			zeroOdd++
+		}
+	}
+
+	var (
+		enc          Encoding
+		order        binary.ByteOrder
+		zeros, other int
+	)
+
+	switch {
+	case zeroOdd > zeroEven:
+		enc, order, zeros, other = UTF16LittleEndian, binary.LittleEndian, zeroOdd, zeroEven
+	case zeroEven > zeroOdd:
+		enc, order, zeros, other = UTF16BigEndian, binary.BigEndian, zeroEven, zeroOdd
+	default:
+		return Unknown, NoConfidence
+	}
+
+	if !validUTF16(b[:pairs*2], order) {
+		return Unknown, NoConfidence
+	}
+
+	switch {
+	case pairs >= 8 && zeros*10 >= pairs*9 && other*10 <= pairs:
+		return enc, HighConfidence
+	case zeros*2 >= pairs:
+		return enc, LowConfidence
+	default:
+		return Unknown, NoConfidence
+	}
+}
+
+// validUTF16 reports whether b has no unpaired surrogates.
+// A high surrogate at the very end is tolerated, since b may be a truncated sample.
+func validUTF16(b []byte, order binary.ByteOrder) bool {
+	for i := 0; i+2 <= len(b); i += 2 {
+		u := rune(order.Uint16(b[i:]))
+
+		switch {
+		case !utf16.IsSurrogate(u):
+		case u >= 0xdc00:
+			return false
+		case i+4 > len(b):
+			return true
+		default:
+			next := rune(order.Uint16(b[i+2:]))
+			if next < 0xdc00 || next > 0xdfff {
+				return false
+			}
+
+			i += 2
+		}
+	}
+
+	return true
+}
+
+// Detection is a plausible interpretation of the beginning of a byte stream.
+type Detection struct {
+	Encoding   Encoding
+	Confidence Confidence
+	// BOMLen is the number of leading bytes forming the BOM, 0 for heuristic detections.
+	BOMLen int
+}
+
+// DetectAll lists every plausible interpretation of the beginning of b,
+// most likely first, so callers can resolve ambiguities with their own policy.
+//
+// A single matching BOM is reported with Certain confidence.
+// Overlapping BOMs, such as 0xff 0xfe 0x00 0x00 that is either the UTF-32LE BOM
+// or the UTF-16LE BOM followed by U+0000, are all reported with confidence
+// depending on how well the rest of b fits each encoding.
+// Without any BOM, the DetectHeuristic result is reported, if any.
+func DetectAll(b []byte) []Detection {
+	var out []Detection
+
+	for _, e := range detectionOrder() {
+		if !e.isExtended() && hasBOM(b, e) {
+			out = append(out, Detection{Encoding: e, Confidence: Certain, BOMLen: e.Len()})
+		}
+	}
+
+	if len(out) > 1 {
+		for i := range out {
+			out[i].Confidence = plausibility(out[i].Encoding, b[out[i].BOMLen:])
+		}
+	}
+
+	if len(out) == 0 {
+		enc, c := DetectHeuristic(b)
+		if c != NoConfidence {
+			out = append(out, Detection{Encoding: enc, Confidence: c, BOMLen: 0})
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b Detection) int {
+		return cmp.Or(cmp.Compare(b.Confidence, a.Confidence), cmp.Compare(b.BOMLen, a.BOMLen))
+	})
+
+	return out
+}
+
+// plausibility estimates how well the payload following a BOM fits enc.
+func plausibility(enc Encoding, payload []byte) Confidence {
+	order := byteOrder(enc)
+
+	switch enc.Family() {
+	case FamilyUTF16:
+		// text rarely starts with U+0000
+		if len(payload) >= 2 && order.Uint16(payload) == 0 {
+			return LowConfidence
+		}
+
+		if !validUTF16(payload[:len(payload)/2*2], order) {
+			return LowConfidence
+		}
+	case FamilyUTF32:
+		if len(payload)%4 != 0 || len(payload) != 0 && !validUTF32(payload, order) {
+			return LowConfidence
+		}
+	default:
+	}
+
+	return HighConfidence
+}
+
+// byteOrder returns the byte order of UTF-16 and UTF-32 encodings, nil for others.
+func byteOrder(enc Encoding) binary.ByteOrder {
+	switch enc.Endianness() {
+	case BigEndian:
+		return binary.BigEndian
+	case LittleEndian:
+		return binary.LittleEndian
+	default:
+		return nil
+	}
+}
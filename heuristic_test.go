@@ -0,0 +1,135 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+// encodeNoBOM returns s encoded as enc without a BOM.
+func encodeNoBOM(enc utfbom.Encoding, s string) []byte {
+	return encode(enc, s)[enc.Len():]
+}
+
+func TestDetectHeuristic(t *testing.T) {
+	t.Parallel()
+
+	const text = "The quick brown fox jumps over the lazy dog"
+
+	testCases := []struct {
+		name       string
+		input      []byte
+		enc        utfbom.Encoding
+		confidence utfbom.Confidence
+	}{
+		{"empty", nil, utfbom.Unknown, utfbom.NoConfidence},
+		{"ascii", []byte(text), utfbom.Unknown, utfbom.NoConfidence},
+		{"utf8_text", []byte(multilingual), utfbom.Unknown, utfbom.NoConfidence},
+		{"utf8_bom", encode(utfbom.UTF8, text), utfbom.UTF8, utfbom.Certain},
+		{"utf16le_bom", encode(utfbom.UTF16LittleEndian, text), utfbom.UTF16LittleEndian, utfbom.Certain},
+		{"utf16le", encodeNoBOM(utfbom.UTF16LittleEndian, text), utfbom.UTF16LittleEndian, utfbom.HighConfidence},
+		{"utf16be", encodeNoBOM(utfbom.UTF16BigEndian, text), utfbom.UTF16BigEndian, utfbom.HighConfidence},
+		{"utf16le_short", encodeNoBOM(utfbom.UTF16LittleEndian, "hi"), utfbom.UTF16LittleEndian, utfbom.LowConfidence},
+		{"utf16le_mixed", encodeNoBOM(utfbom.UTF16LittleEndian, "Grüße, 世界! Zażółć"), utfbom.UTF16LittleEndian, utfbom.LowConfidence},
+		{"utf16le_emoji", encodeNoBOM(utfbom.UTF16LittleEndian, "smile 🙂 please, it is a sunny day"), utfbom.UTF16LittleEndian, utfbom.HighConfidence},
+		{"utf16le_lone_surrogate", []byte{'a', 0x00, 0x00, 0xdc, 'b', 0x00}, utfbom.Unknown, utfbom.NoConfidence},
+		{"utf32le", encodeNoBOM(utfbom.UTF32LittleEndian, text), utfbom.UTF32LittleEndian, utfbom.HighConfidence},
+		{"utf32be", encodeNoBOM(utfbom.UTF32BigEndian, text), utfbom.UTF32BigEndian, utfbom.HighConfidence},
+		{"utf32be_short", encodeNoBOM(utfbom.UTF32BigEndian, "h"), utfbom.UTF32BigEndian, utfbom.LowConfidence},
+		{"zeros", make([]byte, 64), utfbom.Unknown, utfbom.NoConfidence},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			enc, confidence := utfbom.DetectHeuristic(tc.input)
+			be.Equal(t, enc, tc.enc)
+			be.Equal(t, confidence, tc.confidence)
+		})
+	}
+}
+
+func TestConfidence_String(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.NoConfidence.String(), "NoConfidence")
+	be.Equal(t, utfbom.LowConfidence.String(), "LowConfidence")
+	be.Equal(t, utfbom.HighConfidence.String(), "HighConfidence")
+	be.Equal(t, utfbom.Certain.String(), "Certain")
+	be.Equal(t, utfbom.Confidence(999).String(), "NoConfidence")
+}
+
+func ExampleDetectHeuristic() {
+	// "hello" in UTF-16 Little Endian without a BOM, as many Windows tools write it.
+	input := []byte{'h', 0x00, 'e', 0x00, 'l', 0x00, 'l', 0x00, 'o', 0x00}
+
+	fmt.Println(utfbom.DetectEncoding(input))
+	fmt.Println(utfbom.DetectHeuristic(input))
+
+	// output:
+	// Unknown
+	// UTF16LittleEndian LowConfidence
+}
+
+func TestDetectAll(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected []utfbom.Detection
+	}{
+		{
+			name:     "nothing",
+			input:    []byte("hello"),
+			expected: nil,
+		},
+		{
+			name:     "single_bom",
+			input:    encode(utfbom.UTF8, "hello"),
+			expected: []utfbom.Detection{{Encoding: utfbom.UTF8, Confidence: utfbom.Certain, BOMLen: 3}},
+		},
+		{
+			name:  "heuristic",
+			input: encodeNoBOM(utfbom.UTF16LittleEndian, "hello, world"),
+			expected: []utfbom.Detection{
+				{Encoding: utfbom.UTF16LittleEndian, Confidence: utfbom.HighConfidence, BOMLen: 0},
+			},
+		},
+		{
+			name:  "ambiguous_only_bom",
+			input: utf32LEBOM,
+			expected: []utfbom.Detection{
+				{Encoding: utfbom.UTF32LittleEndian, Confidence: utfbom.HighConfidence, BOMLen: 4},
+				{Encoding: utfbom.UTF16LittleEndian, Confidence: utfbom.LowConfidence, BOMLen: 2},
+			},
+		},
+		{
+			name:  "ambiguous_utf32_text",
+			input: encode(utfbom.UTF32LittleEndian, "hey"),
+			expected: []utfbom.Detection{
+				{Encoding: utfbom.UTF32LittleEndian, Confidence: utfbom.HighConfidence, BOMLen: 4},
+				{Encoding: utfbom.UTF16LittleEndian, Confidence: utfbom.LowConfidence, BOMLen: 2},
+			},
+		},
+		{
+			name:  "ambiguous_utf16_text_starting_with_nul",
+			input: encode(utfbom.UTF16LittleEndian, "\x00hey"),
+			expected: []utfbom.Detection{
+				{Encoding: utfbom.UTF32LittleEndian, Confidence: utfbom.LowConfidence, BOMLen: 4},
+				{Encoding: utfbom.UTF16LittleEndian, Confidence: utfbom.LowConfidence, BOMLen: 2},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.DetectAll(tc.input), tc.expected)
+		})
+	}
+}
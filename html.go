@@ -0,0 +1,279 @@
+package utfbom
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	// htmlPrescanLen is the number of bytes examined by the HTML encoding sniffing prescan.
+	htmlPrescanLen = 1024
+
+	// htmlSpace lists the ASCII whitespace bytes of the HTML standard.
+	htmlSpace = "\t\n\f\r "
+)
+
+// DetectHTML detects the encoding of the HTML document b following the encoding sniffing
+// algorithm of the WHATWG HTML standard: the UTF-8, UTF-16BE and UTF-16LE BOMs are checked first,
+// then the charset given by <meta charset> or <meta http-equiv="Content-Type" content="...">
+// within the first 1024 bytes. The first meta element declaring a known charset label wins,
+// those with an unknown label are skipped.
+//
+// As the standard requires, a meta charset of UTF-16 is read as UTF-8,
+// and the UTF-32LE BOM is read as the UTF-16LE one. Charsets other than UTF-8 yield Unknown,
+// as do documents without a BOM or a meta charset, which leaves the rest of the algorithm,
+// such as the transport layer charset or a frequency analysis, to the caller.
+func DetectHTML(b []byte) Encoding {
+	for _, enc := range []Encoding{UTF8, UTF16BigEndian, UTF16LittleEndian} {
+		if bytes.HasPrefix(b, enc.Bytes()) {
+			return enc
+		}
+	}
+
+	return htmlPrescan(b[:min(len(b), htmlPrescanLen)])
+}
+
+// htmlPrescan implements the prescan of a byte stream to determine its encoding,
+// see https://html.spec.whatwg.org/multipage/parsing.html#prescan-a-byte-stream-to-determine-its-encoding.
+func htmlPrescan(b []byte) Encoding {
+	for pos := 0; pos < len(b); pos++ {
+		rest := b[pos:]
+
+		switch {
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			// the dashes of "<!--" and "-->" may overlap
+			end := bytes.Index(rest[2:], []byte("-->"))
+			if end < 0 {
+				return Unknown
+			}
+
+			pos += 2 + end + 2
+		case len(rest) > 5 && bytes.EqualFold(rest[:5], []byte("<meta")) && strings.IndexByte(htmlSpace+"/", rest[5]) >= 0:
+			enc, next, ok := htmlMeta(b, pos+5)
+			if ok {
+				return enc
+			}
+
+			pos = next
+		case isTagStart(rest):
+			end := bytes.IndexAny(rest, htmlSpace+">")
+			if end < 0 {
+				return Unknown
+			}
+
+			pos += end
+
+			for ok := true; ok; {
+				_, _, pos, ok = htmlAttribute(b, pos)
+			}
+		case bytes.HasPrefix(rest, []byte("<!")), bytes.HasPrefix(rest, []byte("</")), bytes.HasPrefix(rest, []byte("<?")):
+			end := bytes.IndexByte(rest, '>')
+			if end < 0 {
+				return Unknown
+			}
+
+			pos += end
+		default:
+		}
+	}
+
+	return Unknown
+}
+
+// isTagStart reports whether b starts with an opening or closing tag.
+func isTagStart(b []byte) bool {
+	switch {
+	case len(b) > 1 && b[0] == '<' && isASCIILetter(b[1]):
+		return true
+	case len(b) > 2 && b[0] == '<' && b[1] == '/' && isASCIILetter(b[2]):
+		return true
+	default:
+		return false
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// htmlMeta processes the attributes of a meta element starting at pos.
+// It returns the encoding declared by the element, if any,
+// along with the position of the last byte examined.
+func htmlMeta(b []byte, pos int) (Encoding, int, bool) {
+	var (
+		seen       []string
+		gotPragma  bool
+		needPragma *bool
+		charset    *string
+	)
+
+	for {
+		name, value, next, ok := htmlAttribute(b, pos)
+		pos = next
+
+		if !ok {
+			break
+		}
+
+		if slices.Contains(seen, name) {
+			continue
+		}
+
+		seen = append(seen, name)
+
+		switch name {
+		case "http-equiv":
+			gotPragma = gotPragma || value == "content-type"
+		case "content":
+			if cs, found := charsetFromContent(value); found && charset == nil {
+				charset, needPragma = &cs, new(true)
+			}
+		case "charset":
+			charset, needPragma = &value, new(false)
+		default:
+		}
+	}
+
+	if needPragma == nil || *needPragma && !gotPragma {
+		return Unknown, pos, false
+	}
+
+	enc, ok := htmlLabel(*charset)
+
+	return enc, pos, ok
+}
+
+// htmlAttribute gets the attribute starting at pos, ASCII letters of its name and value lowercased,
+// see https://html.spec.whatwg.org/multipage/parsing.html#concept-get-attributes-when-sniffing.
+// It returns the position of the byte right after the attribute, or of the byte that ended the search,
+// and false if there is no attribute.
+func htmlAttribute(b []byte, pos int) (string, string, int, bool) {
+	skip := func(chars string) {
+		for pos < len(b) && strings.IndexByte(chars, b[pos]) >= 0 {
+			pos++
+		}
+	}
+
+	skip(htmlSpace + "/")
+
+	if pos >= len(b) || b[pos] == '>' {
+		return "", "", pos, false
+	}
+
+	start := pos
+
+	// a leading '=' is a part of the name
+	for pos++; pos < len(b) && strings.IndexByte(htmlSpace+"/>=", b[pos]) < 0; {
+		pos++
+	}
+
+	name := asciiLower(b[start:pos])
+
+	skip(htmlSpace)
+
+	if pos >= len(b) {
+		return "", "", pos, false
+	}
+
+	if b[pos] != '=' {
+		return name, "", pos, true
+	}
+
+	pos++
+
+	skip(htmlSpace)
+
+	if pos >= len(b) {
+		return "", "", pos, false
+	}
+
+	switch q := b[pos]; q {
+	case '"', '\'':
+		end := bytes.IndexByte(b[pos+1:], q)
+		if end < 0 {
+			return "", "", len(b), false
+		}
+
+		return name, asciiLower(b[pos+1 : pos+1+end]), pos + 1 + end + 1, true
+	case '>':
+		return name, "", pos, true
+	default:
+		end := bytes.IndexAny(b[pos:], htmlSpace+">")
+		if end < 0 {
+			return "", "", len(b), false
+		}
+
+		return name, asciiLower(b[pos : pos+end]), pos + end, true
+	}
+}
+
+// asciiLower returns b as a string with ASCII letters lowercased.
+func asciiLower(b []byte) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+
+		return r
+	}, string(b))
+}
+
+// charsetFromContent extracts the charset from the content attribute of a meta element,
+// see https://html.spec.whatwg.org/multipage/urls-and-fetching.html#algorithm-for-extracting-a-character-encoding-from-a-meta-element.
+func charsetFromContent(s string) (string, bool) {
+	for {
+		i := strings.Index(s, "charset")
+		if i < 0 {
+			return "", false
+		}
+
+		s = strings.TrimLeft(s[i+len("charset"):], htmlSpace)
+
+		rest, ok := strings.CutPrefix(s, "=")
+		if !ok {
+			continue
+		}
+
+		s = strings.TrimLeft(rest, htmlSpace)
+
+		switch {
+		case s == "":
+			return "", false
+		case s[0] == '"' || s[0] == '\'':
+			value, _, found := strings.Cut(s[1:], s[:1])
+
+			return value, found
+		default:
+			if end := strings.IndexAny(s, htmlSpace+";"); end >= 0 {
+				return s[:end], true
+			}
+
+			return s, true
+		}
+	}
+}
+
+// htmlLabel returns the encoding of a charset label as the prescan reads it,
+// and false if the label is not one of the WHATWG Encoding standard, the empty one included,
+// in which case the prescan goes on with the next meta element.
+func htmlLabel(label string) (Encoding, bool) {
+	enc, err := htmlindex.Get(strings.Trim(label, htmlSpace))
+	if err != nil {
+		return Unknown, false
+	}
+
+	name, _ := htmlindex.Name(enc)
+
+	switch name {
+	case "utf-8":
+		return UTF8, true
+	case "utf-16be", "utf-16le":
+		// a document declaring UTF-16 can't be read by the prescan, so it must be UTF-8 in fact
+		return UTF8, true
+	default:
+		return Unknown, true
+	}
+}
@@ -0,0 +1,72 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestDetectHTML(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected utfbom.Encoding
+	}{
+		{"empty", nil, utfbom.Unknown},
+		{"no_meta", []byte("<!doctype html><html><p>hi</p></html>"), utfbom.Unknown},
+		{"utf8_bom", encode(utfbom.UTF8, "<p>hi</p>"), utfbom.UTF8},
+		{"utf16be_bom", encode(utfbom.UTF16BigEndian, "<p>hi</p>"), utfbom.UTF16BigEndian},
+		{"utf16le_bom", encode(utfbom.UTF16LittleEndian, "<p>hi</p>"), utfbom.UTF16LittleEndian},
+		{"utf32le_bom_is_utf16le", encode(utfbom.UTF32LittleEndian, "<p>hi</p>"), utfbom.UTF16LittleEndian},
+		{"bom_wins_over_meta", encode(utfbom.UTF16BigEndian, `<meta charset="utf-8">`), utfbom.UTF16BigEndian},
+		{"meta_charset", []byte(`<html><head><meta charset="utf-8">`), utfbom.UTF8},
+		{"meta_charset_unquoted", []byte(`<META CHARSET=UTF-8>`), utfbom.UTF8},
+		{"meta_charset_slash", []byte(`<meta/charset='utf8'/>`), utfbom.UTF8},
+		{"meta_charset_spaces", []byte("<meta\n  charset = \" utf-8 \" >"), utfbom.UTF8},
+		{"meta_charset_other", []byte(`<meta charset="windows-1252"><meta charset="utf-8">`), utfbom.Unknown},
+		{"meta_charset_utf16_is_utf8", []byte(`<meta charset="utf-16le">`), utfbom.UTF8},
+		{"meta_charset_first_wins", []byte(`<meta charset="utf-8"><meta charset="windows-1252">`), utfbom.UTF8},
+		{"meta_charset_unknown_is_skipped", []byte(`<meta charset="bogus"><meta charset="utf-8">`), utfbom.UTF8},
+		{"content_unknown_is_skipped", []byte(`<meta http-equiv=content-type content="text/html; charset=bogus"><meta charset=utf-8>`), utfbom.UTF8},
+		{"meta_charset_label_alias", []byte(`<meta charset="latin1"><meta charset="utf-8">`), utfbom.Unknown},
+		{"meta_charset_empty_is_skipped", []byte(`<meta charset=""><meta charset="utf-8">`), utfbom.UTF8},
+		{"meta_duplicate_attribute", []byte(`<meta charset="utf-8" charset="latin1">`), utfbom.UTF8},
+		{"http_equiv", []byte(`<meta http-equiv="Content-Type" content="text/html; charset=utf-8">`), utfbom.UTF8},
+		{"http_equiv_reversed", []byte(`<meta content='text/html;charset="UTF-8"' http-equiv=content-type>`), utfbom.UTF8},
+		{"content_without_pragma", []byte(`<meta content="text/html; charset=utf-8">`), utfbom.Unknown},
+		{"content_charset_no_equals", []byte(`<meta http-equiv="content-type" content="charset; charset=utf-8">`), utfbom.UTF8},
+		{"meta_in_comment", []byte(`<!-- <meta charset="utf-8"> -->`), utfbom.Unknown},
+		{"short_comment", []byte(`<!--><meta charset="utf-8">`), utfbom.UTF8},
+		{"meta_in_attribute", []byte(`<div title='<meta charset="utf-8">'>`), utfbom.Unknown},
+		{"meta_after_tags", []byte(`<!doctype html><?pi?></x><html lang=en><head><meta charset=utf-8>`), utfbom.UTF8},
+		{"metadata_element", []byte(`<metadata charset="utf-8">`), utfbom.Unknown},
+		{"unterminated_comment", []byte(`<!-- <meta charset="utf-8">`), utfbom.Unknown},
+		{"beyond_1024_bytes", []byte(strings.Repeat(" ", 1024) + `<meta charset="utf-8">`), utfbom.Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.DetectHTML(tc.input), tc.expected)
+		})
+	}
+}
+
+func ExampleDetectHTML() {
+	page := `<!doctype html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8">
+<title>Grüße</title>`
+
+	fmt.Println(utfbom.DetectHTML([]byte(page)))
+
+	// output:
+	// UTF8
+}
@@ -0,0 +1,140 @@
+package utfbom
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// EncodingHeader is the response header in which Transport records the encoding of the removed BOM.
+const EncodingHeader = "X-Utfbom-Encoding"
+
+// MiddlewareOption configures Middleware.
+type MiddlewareOption func(*middlewareConfig)
+
+type middlewareConfig struct {
+	rejectJSON bool
+}
+
+// RejectJSON makes Middleware answer requests having a JSON body that starts with a BOM
+// with 400 Bad Request instead of removing the BOM, as RFC 8259 forbids JSON text to carry one.
+// A body is JSON if its Content-Type is application/json or ends with +json.
+func RejectJSON() MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.rejectJSON = true
+	}
+}
+
+// Middleware returns a handler removing the Byte Order Mark (BOM) from request bodies before passing
+// the requests to next. The BOM is detected before next is called: for a body starting with a BOM,
+// ContentLength and the Content-Length header of the request passed to next are reduced by the BOM length.
+// Closing the body passed to next closes the original body.
+//
+// Requests whose body can't be read are answered with 400 Bad Request, next is not called then.
+func Middleware(next http.Handler, opts ...MiddlewareOption) http.Handler {
+	var c middlewareConfig
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		var ropts []Option
+		if c.rejectJSON && isJSON(r.Header.Get("Content-Type")) {
+			ropts = append(ropts, Forbid())
+		}
+
+		body := NewReadCloser(r.Body, ropts...)
+
+		_, err := body.Encoding()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.Body = body
+
+		if n := int64(len(body.BOM())); n != 0 && r.ContentLength > 0 {
+			r2.ContentLength = r.ContentLength - n
+			r2.Header = r.Header.Clone()
+
+			if r2.Header.Get("Content-Length") != "" {
+				r2.Header.Set("Content-Length", strconv.FormatInt(r2.ContentLength, 10))
+			}
+		}
+
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// Transport returns a RoundTripper removing the Byte Order Mark (BOM) from response bodies,
+// so that decoders reading them never see it. Requests are sent by rt, or by http.DefaultTransport if rt is nil.
+//
+// The BOM is detected before RoundTrip returns. For a body starting with a BOM,
+// the encoding is recorded in the EncodingHeader header of the response,
+// and ContentLength and the Content-Length header are reduced by the BOM length.
+// If the beginning of the body can't be read, the body is closed and the error is returned.
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return roundTripper{rt}
+}
+
+type roundTripper struct {
+	rt http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil || resp.Body == nil || resp.Body == http.NoBody {
+		return resp, err
+	}
+
+	body := NewReadCloser(resp.Body)
+
+	enc, err := body.Encoding()
+	if err != nil {
+		_ = resp.Body.Close()
+
+		return nil, err
+	}
+
+	resp.Body = body
+
+	if n := int64(len(body.BOM())); n != 0 {
+		resp.Header.Set(EncodingHeader, enc.String())
+
+		if resp.ContentLength > 0 {
+			resp.ContentLength -= n
+		}
+
+		if resp.Header.Get("Content-Length") != "" {
+			resp.Header.Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+		}
+	}
+
+	return resp, nil
+}
+
+// isJSON reports whether the media type of contentType is a JSON one.
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
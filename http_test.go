@@ -0,0 +1,201 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+// echoHandler answers with the request body and its declared length.
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	fmt.Fprintf(w, "%d %s %q", r.ContentLength, r.Header.Get("Content-Length"), body)
+})
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		body        []byte
+		contentType string
+		opts        []utfbom.MiddlewareOption
+		status      int
+		expected    string
+	}{
+		{"no_bom", []byte("hello"), "text/plain", nil, http.StatusOK, `5 5 "hello"`},
+		{"utf8", encode(utfbom.UTF8, "hello"), "text/plain", nil, http.StatusOK, `5 5 "hello"`},
+		{"utf16le", encode(utfbom.UTF16LittleEndian, "h"), "text/plain", nil, http.StatusOK, `2 2 "h\x00"`},
+		{"json", encode(utfbom.UTF8, "{}"), "application/json", nil, http.StatusOK, `2 2 "{}"`},
+		{"json_rejected", encode(utfbom.UTF8, "{}"), "application/json; charset=utf-8", []utfbom.MiddlewareOption{utfbom.RejectJSON()}, http.StatusBadRequest, "utfbom: BOM is forbidden: UTF8\n"},
+		{"json_suffix_rejected", encode(utfbom.UTF8, "{}"), "application/problem+json", []utfbom.MiddlewareOption{utfbom.RejectJSON()}, http.StatusBadRequest, "utfbom: BOM is forbidden: UTF8\n"},
+		{"json_no_bom", []byte("{}"), "application/json", []utfbom.MiddlewareOption{utfbom.RejectJSON()}, http.StatusOK, `2 2 "{}"`},
+		{"not_json", encode(utfbom.UTF8, "a,b"), "text/csv", []utfbom.MiddlewareOption{utfbom.RejectJSON()}, http.StatusOK, `3 3 "a,b"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			req.Header.Set("Content-Length", fmt.Sprint(len(tc.body)))
+
+			rec := httptest.NewRecorder()
+			utfbom.Middleware(echoHandler, tc.opts...).ServeHTTP(rec, req)
+
+			be.Equal(t, rec.Code, tc.status)
+			be.Equal(t, rec.Body.String(), tc.expected)
+			be.Equal(t, req.ContentLength, int64(len(tc.body)))
+		})
+	}
+}
+
+func TestMiddleware_NoBody(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	utfbom.Middleware(echoHandler).ServeHTTP(rec, req)
+
+	be.Equal(t, rec.Code, http.StatusOK)
+	be.Equal(t, rec.Body.String(), `0  ""`)
+}
+
+func TestMiddleware_ReadError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/", iotest.ErrReader(errors.New("boom")))
+	rec := httptest.NewRecorder()
+	utfbom.Middleware(echoHandler).ServeHTTP(rec, req)
+
+	be.Equal(t, rec.Code, http.StatusBadRequest)
+	be.True(t, strings.Contains(rec.Body.String(), "boom"))
+}
+
+func TestMiddleware_Close(t *testing.T) {
+	t.Parallel()
+
+	body := &closeRecorder{Reader: bytes.NewReader(encode(utfbom.UTF8, "x"))}
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Body = body
+
+	utfbom.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		be.Err(t, r.Body.Close(), nil)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	be.Equal(t, body.closed, 1)
+}
+
+func ExampleMiddleware() {
+	handler := utfbom.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%d bytes: %q", r.ContentLength, body)
+	}))
+
+	// a PowerShell client sending a BOM-prefixed body
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("\ufeffhello"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	fmt.Println(rec.Body.String())
+
+	// output:
+	// 5 bytes: "hello"
+}
+
+// roundTripFunc is an http.RoundTripper calling the function itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransport(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		body     []byte
+		expected []byte
+		header   string
+	}{
+		{"no_bom", []byte(`{"a":1}`), []byte(`{"a":1}`), ""},
+		{"utf8", encode(utfbom.UTF8, `{"a":1}`), []byte(`{"a":1}`), "UTF8"},
+		{"utf16be", encode(utfbom.UTF16BigEndian, "a"), []byte{0x00, 'a'}, "UTF16BigEndian"},
+		{"empty", []byte{}, []byte{}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(tc.body)
+			}))
+			defer srv.Close()
+
+			client := &http.Client{Transport: utfbom.Transport(srv.Client().Transport)}
+
+			resp, err := client.Get(srv.URL)
+			be.Err(t, err, nil)
+
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			be.Err(t, err, nil)
+			be.Equal(t, body, tc.expected)
+			be.Equal(t, resp.Header.Get(utfbom.EncodingHeader), tc.header)
+			be.Equal(t, resp.ContentLength, int64(len(tc.expected)))
+			be.Equal(t, resp.Header.Get("Content-Length"), fmt.Sprint(len(tc.expected)))
+		})
+	}
+}
+
+func TestTransport_Errors(t *testing.T) {
+	t.Parallel()
+
+	rt := utfbom.Transport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("dial")
+	}))
+
+	_, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "/", nil))
+	be.Err(t, err, "dial")
+
+	body := &closeRecorder{Reader: iotest.ErrReader(errors.New("boom"))}
+	rt = utfbom.Transport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{Body: body, Header: http.Header{}}, nil
+	}))
+
+	resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "/", nil))
+	be.Err(t, err, utfbom.ErrRead)
+	be.True(t, resp == nil)
+	be.Equal(t, body.closed, 1)
+}
+
+func TestTransport_NoBody(t *testing.T) {
+	t.Parallel()
+
+	rt := utfbom.Transport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody, Header: http.Header{}}, nil
+	}))
+
+	resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "/", nil))
+	be.Err(t, err, nil)
+	be.Equal(t, resp.Body, io.ReadCloser(http.NoBody))
+}
@@ -0,0 +1,163 @@
+package utfbom
+
+import (
+	"bytes"
+	"io"
+)
+
+// zwnbsp is U+FEFF encoded in UTF-8. Past the beginning of a stream
+// it is a zero-width no-break space, usually left over from concatenated BOM-prefixed files.
+const zwnbsp = "\xef\xbb\xbf"
+
+// StripInterior removes every U+FEFF (zero width no-break space) encoded in UTF-8 from b,
+// except a leading one, which is a BOM and is left for Trim to deal with.
+// Unicode recommends treating such interior characters as removable,
+// they typically appear when BOM-prefixed files are concatenated.
+//
+// StripInterior works in place: it returns b shortened to the remaining bytes
+// together with the number of removed characters.
+func StripInterior(b []byte) ([]byte, int) {
+	return removeZWNBSP(b, true)
+}
+
+// removeZWNBSP removes U+FEFF encoded in UTF-8 from b in place, except a leading one if keepLeading is set.
+func removeZWNBSP(b []byte, keepLeading bool) ([]byte, int) {
+	start := 0
+	if keepLeading && bytes.HasPrefix(b, []byte(zwnbsp)) {
+		start = len(zwnbsp)
+	}
+
+	i := bytes.Index(b[start:], []byte(zwnbsp))
+	if i < 0 {
+		return b, 0
+	}
+
+	w := start + i
+	count := 0
+
+	for r := w; r < len(b); {
+		if bytes.HasPrefix(b[r:], []byte(zwnbsp)) {
+			r += len(zwnbsp)
+			count++
+
+			continue
+		}
+
+		b[w] = b[r]
+		w++
+		r++
+	}
+
+	return b[:w], count
+}
+
+// partialZWNBSP returns the length of the incomplete U+FEFF at the end of b, if any.
+func partialZWNBSP(b []byte) int {
+	for n := len(zwnbsp) - 1; n > 0; n-- {
+		if bytes.HasSuffix(b, []byte(zwnbsp[:n])) {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// interiorScrubber removes U+FEFF encoded in UTF-8 from the stream it wraps.
+// Characters split across reads are removed as well, at the price of holding
+// back up to two bytes until the next read tells what they are.
+type interiorScrubber struct {
+	rd          io.Reader
+	buf         []byte
+	r, w        int  // buf[r:w] is not returned to the caller yet
+	keepLeading bool // keep U+FEFF at the very beginning of the stream
+	err         error
+}
+
+// Read implements the io.Reader interface.
+func (s *interiorScrubber) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		ready := s.w
+		if s.err == nil {
+			ready -= partialZWNBSP(s.buf[s.r:s.w])
+		}
+
+		if s.r < ready {
+			n := copy(p, s.buf[s.r:ready])
+			s.r += n
+
+			return n, nil
+		}
+
+		if s.err != nil {
+			return 0, s.err
+		}
+
+		s.fill()
+	}
+}
+
+// fill reads the next chunk after the held back bytes and removes U+FEFF from them.
+func (s *interiorScrubber) fill() {
+	if s.buf == nil {
+		s.buf = make([]byte, defaultBufSize)
+	}
+
+	s.w = copy(s.buf, s.buf[s.r:s.w])
+	s.r = 0
+
+	n, err := s.rd.Read(s.buf[s.w:])
+	s.err = err
+
+	b := s.buf[:s.w+n]
+	// the leading U+FEFF can be told apart once it is complete or can't be anymore
+	resolved := err != nil || len(b) >= len(zwnbsp) || !bytes.HasPrefix([]byte(zwnbsp), b)
+
+	out, _ := removeZWNBSP(b, s.keepLeading)
+	s.w = len(out)
+
+	if resolved {
+		s.keepLeading = false
+	}
+}
+
+// scrubs reports whether interior U+FEFF is removed from the payload by Reader.
+func (r *Reader) scrubs() bool {
+	return r.opts.scrub && r.Enc.AnyOf(Unknown, UTF8)
+}
+
+// scrub returns the scrubber of the payload, setting it up on the first call after NewReader or Reset.
+func (r *Reader) scrub() *interiorScrubber {
+	if r.scrubber.rd == nil {
+		r.scrubber.rd = r.payload()
+		r.scrubber.keepLeading = r.Enc == UTF8 && (!r.opts.strips(r.Enc) || r.unread)
+
+		if r.scrubber.buf == nil {
+			r.scrubber.buf = r.opts.buf
+		}
+	}
+
+	return &r.scrubber
+}
+
+// payload returns the reader of the payload left after detection, validated under WithValidateUTF8.
+func (r *Reader) payload() io.Reader {
+	if r.validates() {
+		return r.validator()
+	}
+
+	return payloadReader{r}
+}
+
+// payloadReader reads the payload left after detection by Reader.
+type payloadReader struct {
+	r *Reader
+}
+
+// Read implements the io.Reader interface.
+func (p payloadReader) Read(buf []byte) (int, error) {
+	return p.r.readPayload(buf)
+}
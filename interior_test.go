@@ -0,0 +1,136 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestStripInterior(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		count    int
+	}{
+		{"empty", "", "", 0},
+		{"nothing_to_strip", "hello", "hello", 0},
+		{"leading_is_kept", "\ufeffhello", "\ufeffhello", 0},
+		{"interior", "hel\ufefflo", "hello", 1},
+		{"trailing", "hello\ufeff", "hello", 1},
+		{"concatenated", "\ufeffone\n\ufefftwo\n\ufeffthree", "\ufeffone\ntwo\nthree", 2},
+		{"adjacent", "\ufeff\ufeff\ufeffhi", "\ufeffhi", 2},
+		{"incomplete", "hi\xef\xbb", "hi\xef\xbb", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, n := utfbom.StripInterior([]byte(tc.input))
+			be.Equal(t, string(out), tc.expected)
+			be.Equal(t, n, tc.count)
+		})
+	}
+}
+
+func TestScrubInterior(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		opts     []utfbom.Option
+		expected string
+	}{
+		{"no_bom", "one\ufefftwo", nil, "onetwo"},
+		{"utf8", "\ufeffone\n\ufefftwo\n\ufeff", nil, "one\ntwo\n"},
+		{"double_bom", "\ufeff\ufeffone", nil, "one"},
+		{"leading_kept_by_passthrough", "\ufeffone\ufefftwo", []utfbom.Option{utfbom.Passthrough()}, "\ufeffonetwo"},
+		{"incomplete_at_eof", "one\xef\xbb", nil, "one\xef\xbb"},
+		{"partial_lookalike", "one\xef\xbbtwo", nil, "one\xef\xbbtwo"},
+	}
+
+	readers := map[string]func(string) io.Reader{
+		"whole":    func(s string) io.Reader { return strings.NewReader(s) },
+		"one_byte": func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		"half":     func(s string) io.Reader { return iotest.HalfReader(strings.NewReader(s)) },
+	}
+
+	for _, tc := range testCases {
+		for name, newReader := range readers {
+			t.Run(tc.name+"_"+name, func(t *testing.T) {
+				t.Parallel()
+
+				opts := append([]utfbom.Option{utfbom.ScrubInterior()}, tc.opts...)
+
+				out, err := io.ReadAll(utfbom.NewReader(newReader(tc.input), opts...))
+				be.Err(t, err, nil)
+				be.Equal(t, string(out), tc.expected)
+
+				var buf bytes.Buffer
+
+				_, err = utfbom.NewReader(newReader(tc.input), opts...).WriteTo(&buf)
+				be.Err(t, err, nil)
+				be.Equal(t, buf.String(), tc.expected)
+
+				out, err = io.ReadAll(iotest.OneByteReader(utfbom.NewReader(newReader(tc.input), opts...)))
+				be.Err(t, err, nil)
+				be.Equal(t, string(out), tc.expected)
+			})
+		}
+	}
+}
+
+func TestScrubInterior_UTF8Reader(t *testing.T) {
+	t.Parallel()
+
+	input := encode(utfbom.UTF16LittleEndian, "one\n\ufefftwo")
+
+	out, err := io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(input), utfbom.ScrubInterior()))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "one\ntwo")
+
+	out, err = io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(input), utfbom.ScrubInterior(), utfbom.Passthrough()))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\ufeffone\ntwo")
+}
+
+func TestScrubInterior_Disabled(t *testing.T) {
+	t.Parallel()
+
+	out, err := io.ReadAll(utfbom.NewReader(strings.NewReader("\ufeffone\ufefftwo")))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "one\ufefftwo")
+}
+
+func ExampleScrubInterior() {
+	// two JSON documents, each saved with a BOM, concatenated into one stream
+	input := "\ufeff{\"a\":1}\n\ufeff{\"a\":2}\n"
+
+	dec := json.NewDecoder(utfbom.NewReader(strings.NewReader(input), utfbom.ScrubInterior()))
+
+	for dec.More() {
+		var v struct{ A int }
+
+		err := dec.Decode(&v)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println(v.A)
+	}
+
+	// output:
+	// 1
+	// 2
+}
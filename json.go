@@ -0,0 +1,73 @@
+package utfbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NewJSONDecoder returns a json.Decoder reading rd with its Byte Order Mark (BOM) handled according to policy.
+// The BOM is detected right away, so policy violations are reported before any decoding:
+//   - UseBOM is lenient and removes a BOM, as RFC 8259 allows parsers to do;
+//   - ForbidBOM is strict and fails with ErrBOMForbidden if a BOM is present, as RFC 8259 forbids
+//     generating JSON text with one;
+//   - ExpectBOM fails with ErrBOMExpected if no BOM is present;
+//   - IgnoreBOM leaves a BOM in the text, for the json.Decoder to reject it.
+//
+// UTF-16 and UTF-32 text, allowed by the older RFC 7159, is decoded into UTF-8.
+// Without a BOM it is recognized by the pattern of null bytes in the first two characters,
+// which are always ASCII in JSON text, as described in RFC 4627, section 3.
+// Text of encodings other than UTF-8, UTF-16 and UTF-32 fails with ErrUnsupportedEncoding.
+func NewJSONDecoder(rd io.Reader, policy BOMPolicy) (*json.Decoder, error) {
+	br := bufio.NewReader(rd)
+	u := NewUTF8Reader(br, WithPolicy(policy))
+
+	enc, err := u.rd.Encoding()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case enc == Unknown:
+		// nothing is consumed from br when there is no BOM
+		head, _ := br.Peek(maxBOMLen)
+
+		enc = jsonEncoding(head)
+		if enc != Unknown {
+			u = NewUTF8Reader(io.MultiReader(bytes.NewReader(enc.Bytes()), br))
+		}
+	case enc != UTF8 && !enc.IsUTF16() && !enc.IsUTF32():
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, enc)
+	default:
+	}
+
+	return json.NewDecoder(u), nil
+}
+
+// jsonEncoding recognizes BOM-less UTF-16 and UTF-32 JSON text by its null bytes, see RFC 4627, section 3.
+// It returns Unknown for UTF-8 text.
+func jsonEncoding(b []byte) Encoding {
+	if len(b) >= 4 {
+		switch {
+		case b[0] == 0 && b[1] == 0 && b[2] == 0 && b[3] != 0:
+			return UTF32BigEndian
+		case b[0] != 0 && b[1] == 0 && b[2] == 0 && b[3] == 0:
+			return UTF32LittleEndian
+		default:
+		}
+	}
+
+	if len(b) >= 2 {
+		switch {
+		case b[0] == 0 && b[1] != 0:
+			return UTF16BigEndian
+		case b[0] != 0 && b[1] == 0:
+			return UTF16LittleEndian
+		default:
+		}
+	}
+
+	return Unknown
+}
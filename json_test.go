@@ -0,0 +1,124 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestNewJSONDecoder(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	inputs := []struct {
+		name     string
+		text     string
+		expected any
+	}{
+		{"object", ` {"name": "Zażółć 🙂"}`, map[string]any{"name": "Zażółć 🙂"}},
+		{"string", `"é"`, "é"},
+		{"number", `7`, float64(7)},
+	}
+
+	for _, enc := range encodings {
+		for _, in := range inputs {
+			t.Run(enc.String()+"/"+in.name, func(t *testing.T) {
+				t.Parallel()
+
+				for _, input := range [][]byte{encode(enc, in.text), encodeNoBOM(enc, in.text)} {
+					dec, err := utfbom.NewJSONDecoder(iotest.OneByteReader(bytes.NewReader(input)), utfbom.UseBOM)
+					be.Err(t, err, nil)
+
+					var got any
+
+					be.Err(t, dec.Decode(&got), nil)
+					be.Equal(t, got, in.expected)
+				}
+			})
+		}
+	}
+}
+
+func TestNewJSONDecoder_Policies(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		input  []byte
+		policy utfbom.BOMPolicy
+		err    error
+	}{
+		{"strict_no_bom", []byte(`{}`), utfbom.ForbidBOM, nil},
+		{"strict_utf8_bom", encode(utfbom.UTF8, `{}`), utfbom.ForbidBOM, utfbom.ErrBOMForbidden},
+		{"strict_utf16_no_bom", encodeNoBOM(utfbom.UTF16LittleEndian, `{}`), utfbom.ForbidBOM, nil},
+		{"expect_bom", encode(utfbom.UTF16BigEndian, `{}`), utfbom.ExpectBOM, nil},
+		{"expect_no_bom", []byte(`{}`), utfbom.ExpectBOM, utfbom.ErrBOMExpected},
+		{"extended_signature_ignored", append(utfbom.GB18030.Bytes(), `{}`...), utfbom.UseBOM, nil},
+		{"empty", nil, utfbom.UseBOM, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dec, err := utfbom.NewJSONDecoder(bytes.NewReader(tc.input), tc.policy)
+			be.Err(t, err, tc.err)
+			be.Equal(t, dec == nil, tc.err != nil)
+		})
+	}
+}
+
+func TestNewJSONDecoder_IgnoreBOM(t *testing.T) {
+	t.Parallel()
+
+	dec, err := utfbom.NewJSONDecoder(bytes.NewReader(encode(utfbom.UTF8, `{}`)), utfbom.IgnoreBOM)
+	be.Err(t, err, nil)
+
+	var got any
+
+	be.Err(t, dec.Decode(&got), "invalid character")
+}
+
+func TestNewJSONDecoder_ReadError(t *testing.T) {
+	t.Parallel()
+
+	_, err := utfbom.NewJSONDecoder(iotest.ErrReader(errors.New("boom")), utfbom.UseBOM)
+	be.Err(t, err, utfbom.ErrRead)
+}
+
+func ExampleNewJSONDecoder() {
+	// a .NET service response with a UTF-8 BOM
+	input := "\ufeff" + `{"city": "Köln"}`
+
+	_, err := utfbom.NewJSONDecoder(strings.NewReader(input), utfbom.ForbidBOM)
+	fmt.Println("strict:", err)
+
+	dec, err := utfbom.NewJSONDecoder(strings.NewReader(input), utfbom.UseBOM)
+	if err != nil {
+		panic(err)
+	}
+
+	var v struct {
+		City string `json:"city"`
+	}
+
+	err = dec.Decode(&v)
+	fmt.Println("lenient:", v.City, err)
+
+	// output:
+	// strict: utfbom: BOM is forbidden: UTF8
+	// lenient: Köln <nil>
+}
@@ -0,0 +1,115 @@
+package utfbom
+
+import (
+	"bytes"
+	"io"
+)
+
+var _ io.Reader = (*LineSanitizer)(nil)
+
+// LineSanitizer removes the UTF-8 BOM from the beginning of every line of the stream it wraps, the first one included.
+// Workflows appending to a file chunk by chunk, such as Excel exports or PowerShell's Out-File -Append,
+// prefix each chunk with a BOM, which then lands at the beginning of arbitrary lines.
+//
+// Lines end with '\n', so "\r\n" line endings are handled as well. Several BOMs in a row at the beginning
+// of a line are all removed, U+FEFF elsewhere in a line is left alone, see ScrubInterior for that.
+// A BOM split across reads is removed as well, at the price of holding back up to two bytes
+// at the beginning of a line until the next read tells what they are.
+//
+// LineSanitizer is not safe for concurrent use.
+type LineSanitizer struct {
+	rd      io.Reader
+	buf     []byte // read buffer
+	out     []byte // sanitized bytes
+	pos     int    // read position in out
+	match   int    // BOM bytes held back at the beginning of the current line, -1 past it
+	removed int
+	err     error // sticky error of the underlying reader
+}
+
+// NewLineSanitizer wraps an incoming reader.
+// Passing a nil reader will cause a panic on the first Read call.
+func NewLineSanitizer(rd io.Reader) *LineSanitizer {
+	return &LineSanitizer{rd: rd}
+}
+
+// Read implements the io.Reader interface.
+// Errors of the underlying reader are returned as is, once the bytes read before them are returned.
+func (s *LineSanitizer) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for s.pos == len(s.out) {
+		if s.err != nil {
+			return 0, s.err
+		}
+
+		s.fill()
+	}
+
+	n := copy(p, s.out[s.pos:])
+	s.pos += n
+
+	return n, nil
+}
+
+// Removed returns the number of BOMs removed so far.
+func (s *LineSanitizer) Removed() int {
+	return s.removed
+}
+
+// fill reads the next chunk and removes the BOMs at the beginning of its lines.
+func (s *LineSanitizer) fill() {
+	if s.buf == nil {
+		s.buf = make([]byte, defaultBufSize)
+	}
+
+	n, err := s.rd.Read(s.buf)
+	s.err = err
+
+	s.out = s.sanitize(s.out[:0], s.buf[:n])
+	s.pos = 0
+
+	if err != nil && s.match > 0 {
+		// the stream ended with an incomplete BOM, which is part of the payload after all
+		s.out = append(s.out, zwnbsp[:s.match]...)
+		s.match = -1
+	}
+}
+
+// sanitize appends b to dst without the BOMs at the beginning of lines.
+func (s *LineSanitizer) sanitize(dst, b []byte) []byte {
+	for len(b) != 0 {
+		if s.match < 0 {
+			i := bytes.IndexByte(b, '\n')
+			if i < 0 {
+				return append(dst, b...)
+			}
+
+			dst = append(dst, b[:i+1]...)
+			b = b[i+1:]
+			s.match = 0
+
+			continue
+		}
+
+		if b[0] == zwnbsp[s.match] {
+			b = b[1:]
+
+			s.match++
+			if s.match == len(zwnbsp) {
+				s.removed++
+				s.match = 0
+			}
+
+			continue
+		}
+
+		// the line doesn't start with a BOM, b[0] is looked at again past its beginning
+		dst = append(dst, zwnbsp[:s.match]...)
+		s.match = -1
+	}
+
+	return dst
+}
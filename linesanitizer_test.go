@@ -0,0 +1,91 @@
+package utfbom_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestLineSanitizer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		removed  int
+	}{
+		{"every_line", "\ufeffa\n\ufeffb\n\ufeffc", "a\nb\nc", 3},
+		{"crlf", "a\r\n\ufeffb\r\n", "a\r\nb\r\n", 1},
+		{"repeated", "\ufeff\ufeffa\n", "a\n", 2},
+		{"interior_kept", "a\ufeffb\n", "a\ufeffb\n", 0},
+		{"empty_lines", "\ufeff\n\ufeff\n", "\n\n", 2},
+		{"partial_bom", "\xef\xbba\n\xef", "\xef\xbba\n\xef", 0},
+		{"partial_then_bom", "\xef\ufeffa", "\xef\ufeffa", 0},
+		{"no_bom", "a\nb\n", "a\nb\n", 0},
+		{"empty", "", "", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := utfbom.NewLineSanitizer(strings.NewReader(tc.input))
+
+			out, err := io.ReadAll(s)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+			be.Equal(t, s.Removed(), tc.removed)
+
+			// BOMs split across reads are removed all the same
+			s = utfbom.NewLineSanitizer(iotest.OneByteReader(strings.NewReader(tc.input)))
+
+			out, err = io.ReadAll(iotest.OneByteReader(s))
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+			be.Equal(t, s.Removed(), tc.removed)
+		})
+	}
+}
+
+func TestLineSanitizer_Error(t *testing.T) {
+	t.Parallel()
+
+	errBroken := errors.New("broken")
+
+	s := utfbom.NewLineSanitizer(io.MultiReader(strings.NewReader("\ufeffa\n\xef\xbb"), iotest.ErrReader(errBroken)))
+
+	out, err := io.ReadAll(s)
+	be.Err(t, err, errBroken)
+	be.Equal(t, string(out), "a\n\xef\xbb")
+	be.Equal(t, s.Removed(), 1)
+
+	err = iotest.TestReader(utfbom.NewLineSanitizer(strings.NewReader("a\n\ufeffb\n")), []byte("a\nb\n"))
+	be.Err(t, err, nil)
+}
+
+func ExampleNewLineSanitizer() {
+	// a log built with repeated Out-File -Append -Encoding utf8
+	log := strings.NewReader("\ufeffstarted\n\ufeffrunning\n\ufeffdone\n")
+
+	s := utfbom.NewLineSanitizer(log)
+
+	_, err := io.Copy(os.Stdout, s)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(s.Removed(), "BOMs removed")
+	// output:
+	// started
+	// running
+	// done
+	// 3 BOMs removed
+}
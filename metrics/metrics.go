@@ -0,0 +1,130 @@
+// Package metrics counts the Byte Order Marks detected by utfbom readers process-wide.
+//
+// Readers made by the constructors of the package, or given the options returned by Options,
+// report every detection and every error to the current Recorder.
+// The default one counts with expvar, and is published by the caller, so that importing
+// the package claims no expvar name:
+//
+//	expvar.Publish("utfbom", metrics.Default) // {"detected": {"UTF8": 12, "Unknown": 3480}, "errors": 2}
+//
+// Use SetRecorder to send the counts elsewhere, such as to Prometheus counters.
+package metrics
+
+import (
+	"expvar"
+	"io"
+	"slices"
+	"sync/atomic"
+
+	"github.com/slash3b/utfbom"
+)
+
+// Recorder receives the detections and errors of the readers of the package.
+// Its methods are called by the goroutines reading, so they must be safe for concurrent use.
+type Recorder interface {
+	// Detected is called once per stream with the encoding of its BOM, Unknown if there is none.
+	Detected(enc utfbom.Encoding)
+	// Failed is called once per stream with the first error other than io.EOF, see utfbom.WithOnError.
+	Failed(err error)
+}
+
+// Expvar is a Recorder counting into an expvar.Map: the detections per encoding name
+// in the "detected" map and the errors in the "errors" integer.
+// Expvar is an expvar.Var itself, showing the map it counts into.
+type Expvar struct {
+	m        *expvar.Map
+	detected *expvar.Map
+	errors   *expvar.Int
+}
+
+// NewExpvar returns a Recorder counting into m, which may or may not be published.
+func NewExpvar(m *expvar.Map) *Expvar {
+	e := &Expvar{
+		m:        m,
+		detected: new(expvar.Map).Init(),
+		errors:   new(expvar.Int),
+	}
+
+	m.Set("detected", e.detected)
+	m.Set("errors", e.errors)
+
+	return e
+}
+
+// Detected implements the Recorder interface.
+func (e *Expvar) Detected(enc utfbom.Encoding) {
+	e.detected.Add(enc.String(), 1)
+}
+
+// Failed implements the Recorder interface.
+func (e *Expvar) Failed(error) {
+	e.errors.Add(1)
+}
+
+// String implements the expvar.Var interface.
+func (e *Expvar) String() string {
+	return e.m.String()
+}
+
+// Count returns the number of detections of enc.
+func (e *Expvar) Count(enc utfbom.Encoding) int64 {
+	v, ok := e.detected.Get(enc.String()).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+
+	return v.Value()
+}
+
+// Errors returns the number of errors.
+func (e *Expvar) Errors() int64 {
+	return e.errors.Value()
+}
+
+// Default is the Recorder readers report to unless SetRecorder is called.
+// It isn't published: call expvar.Publish("utfbom", metrics.Default) to serve the counts on /debug/vars.
+var Default = NewExpvar(new(expvar.Map).Init())
+
+// holder lets atomic.Pointer hold any Recorder.
+type holder struct {
+	Recorder
+}
+
+var current atomic.Pointer[holder]
+
+func init() {
+	current.Store(&holder{Default})
+}
+
+// SetRecorder makes the readers of the package report to r from now on, readers made earlier included.
+// A nil r restores Default.
+func SetRecorder(r Recorder) {
+	if r == nil {
+		r = Default
+	}
+
+	current.Store(&holder{r})
+}
+
+// Options returns the utfbom.WithOnDetect and utfbom.WithOnError options reporting to the current Recorder.
+// The hooks add up, so the hooks of the caller, given before or after them, are called as well.
+func Options() []utfbom.Option {
+	return []utfbom.Option{
+		utfbom.WithOnDetect(func(enc utfbom.Encoding) {
+			current.Load().Detected(enc)
+		}),
+		utfbom.WithOnError(func(err error) {
+			current.Load().Failed(err)
+		}),
+	}
+}
+
+// NewReader is utfbom.NewReader reporting to the current Recorder, in addition to the hooks set by opts.
+func NewReader(rd io.Reader, opts ...utfbom.Option) *utfbom.Reader {
+	return utfbom.NewReader(rd, slices.Concat(opts, Options())...)
+}
+
+// NewUTF8Reader is utfbom.NewUTF8Reader reporting to the current Recorder, in addition to the hooks set by opts.
+func NewUTF8Reader(rd io.Reader, opts ...utfbom.Option) *utfbom.UTF8Reader {
+	return utfbom.NewUTF8Reader(rd, slices.Concat(opts, Options())...)
+}
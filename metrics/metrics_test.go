@@ -0,0 +1,163 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+	"github.com/slash3b/utfbom/metrics"
+)
+
+// recorder keeps what it receives.
+type recorder struct {
+	mu       sync.Mutex
+	detected []utfbom.Encoding
+	failed   []error
+}
+
+func (r *recorder) Detected(enc utfbom.Encoding) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.detected = append(r.detected, enc)
+}
+
+func (r *recorder) Failed(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failed = append(r.failed, err)
+}
+
+func TestExpvar(t *testing.T) {
+	t.Parallel()
+
+	m := new(expvar.Map).Init()
+	e := metrics.NewExpvar(m)
+
+	e.Detected(utfbom.UTF8)
+	e.Detected(utfbom.UTF8)
+	e.Detected(utfbom.Unknown)
+	e.Failed(errors.New("broken"))
+
+	be.Equal(t, e.Count(utfbom.UTF8), int64(2))
+	be.Equal(t, e.Count(utfbom.Unknown), int64(1))
+	be.Equal(t, e.Count(utfbom.UTF16BigEndian), int64(0))
+	be.Equal(t, e.Errors(), int64(1))
+
+	var v struct {
+		Detected map[string]int64 `json:"detected"`
+		Errors   int64            `json:"errors"`
+	}
+
+	be.Err(t, json.Unmarshal([]byte(m.String()), &v), nil)
+	be.Equal(t, v.Detected, map[string]int64{"UTF8": 2, "Unknown": 1})
+	be.Equal(t, v.Errors, int64(1))
+}
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	// importing the package publishes nothing, the name is left to the caller
+	be.True(t, expvar.Get("utfbom") == nil)
+
+	expvar.Publish("utfbom_test", metrics.Default)
+	be.True(t, strings.Contains(expvar.Get("utfbom_test").String(), `"detected"`))
+}
+
+// TestSetRecorder changes the process-wide recorder, so it doesn't run in parallel.
+func TestSetRecorder(t *testing.T) {
+	rec := &recorder{}
+
+	metrics.SetRecorder(rec)
+	t.Cleanup(func() { metrics.SetRecorder(nil) })
+
+	_, err := io.ReadAll(metrics.NewReader(strings.NewReader("\ufeffa")))
+	be.Err(t, err, nil)
+
+	_, err = io.ReadAll(metrics.NewUTF8Reader(strings.NewReader("\xff\xfea\x00")))
+	be.Err(t, err, nil)
+
+	_, err = io.ReadAll(metrics.NewReader(strings.NewReader("\ufeffa"), utfbom.Forbid()))
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+
+	errBroken := errors.New("broken")
+
+	_, err = io.ReadAll(metrics.NewReader(io.MultiReader(strings.NewReader("a"), iotest.ErrReader(errBroken))))
+	be.Err(t, err, errBroken)
+
+	be.Equal(t, rec.detected, []utfbom.Encoding{utfbom.UTF8, utfbom.UTF16LittleEndian, utfbom.UTF8, utfbom.Unknown})
+	be.Equal(t, len(rec.failed), 2)
+	be.Err(t, rec.failed[0], utfbom.ErrBOMForbidden)
+	be.Err(t, rec.failed[1], errBroken)
+
+	// readers made with Options report to the recorder set later
+	rd := utfbom.NewRuneReader(strings.NewReader("\ufeffa"), metrics.Options()...)
+
+	later := &recorder{}
+	metrics.SetRecorder(later)
+
+	_, _, err = rd.ReadRune()
+	be.Err(t, err, nil)
+	be.Equal(t, later.detected, []utfbom.Encoding{utfbom.UTF8})
+}
+
+// TestNewReader_KeepsHooks changes the process-wide recorder, so it doesn't run in parallel.
+func TestNewReader_KeepsHooks(t *testing.T) {
+	rec := &recorder{}
+
+	metrics.SetRecorder(rec)
+	t.Cleanup(func() { metrics.SetRecorder(nil) })
+
+	var (
+		detected []utfbom.Encoding
+		failed   []error
+	)
+
+	_, err := io.ReadAll(metrics.NewReader(strings.NewReader("\ufeffa"), utfbom.Forbid(),
+		utfbom.WithOnDetect(func(enc utfbom.Encoding) { detected = append(detected, enc) }),
+		utfbom.WithOnError(func(err error) { failed = append(failed, err) }),
+	))
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+
+	be.Equal(t, detected, []utfbom.Encoding{utfbom.UTF8})
+	be.Equal(t, rec.detected, []utfbom.Encoding{utfbom.UTF8})
+	be.Equal(t, failed, []error{err})
+	be.Equal(t, rec.failed, []error{err})
+}
+
+func TestNewReader_KeepsOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := make([]utfbom.Option, 1, 4)
+	opts[0] = utfbom.Passthrough()
+
+	out, err := io.ReadAll(metrics.NewReader(strings.NewReader("\ufeffa"), opts...))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\ufeffa")
+
+	// the options of the caller are left alone
+	be.Equal(t, len(opts), 1)
+	be.True(t, opts[:cap(opts)][1] == nil)
+}
+
+func ExampleNewReader() {
+	body := strings.NewReader("\ufeff{\"id\":1}")
+
+	payload, err := io.ReadAll(metrics.NewReader(body))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(payload), metrics.Default.Count(utfbom.UTF8) > 0)
+	// output:
+	// {"id":1} true
+}
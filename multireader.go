@@ -0,0 +1,54 @@
+package utfbom
+
+import (
+	"io"
+)
+
+var _ io.Reader = (*MultiReader)(nil)
+
+// MultiReader is the logical concatenation of several readers,
+// same as the one returned by io.MultiReader, except that the
+// Byte Order Mark (BOM) is removed from the beginning of every one of them.
+//
+// MultiReader is not safe for concurrent use.
+type MultiReader struct {
+	first *Reader
+	rd    io.Reader
+	// Enc is the encoding of the BOM removed from the first reader,
+	// it will be available after first read
+	Enc Encoding
+}
+
+// NewMultiReader returns a MultiReader reading the given readers sequentially,
+// with the BOM of every reader removed. It is handy for stitching together
+// files or log segments that each carry their own BOM.
+// Passing a nil reader will cause a panic once it is reached.
+func NewMultiReader(rs ...io.Reader) *MultiReader {
+	m := &MultiReader{
+		Enc: Unknown,
+	}
+
+	readers := make([]io.Reader, len(rs))
+	for i, rd := range rs {
+		readers[i] = NewReader(rd)
+	}
+
+	if len(readers) != 0 {
+		m.first = readers[0].(*Reader)
+	}
+
+	m.rd = io.MultiReader(readers...)
+
+	return m
+}
+
+// Read implements the io.Reader interface.
+func (m *MultiReader) Read(buf []byte) (int, error) {
+	n, err := m.rd.Read(buf)
+
+	if m.first != nil {
+		m.Enc = m.first.Enc
+	}
+
+	return n, err
+}
@@ -0,0 +1,82 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestMultiReader(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		parts    []string
+		enc      utfbom.Encoding
+		expected string
+	}{
+		{"none", nil, utfbom.Unknown, ""},
+		{"single", []string{"\ufeffone"}, utfbom.UTF8, "one"},
+		{"every_part_has_bom", []string{"\ufeffone\n", "\ufefftwo\n", "\ufeffthree\n"}, utfbom.UTF8, "one\ntwo\nthree\n"},
+		{"first_part_without_bom", []string{"one\n", "\ufefftwo\n"}, utfbom.Unknown, "one\ntwo\n"},
+		{"empty_parts", []string{"", "\ufeff", "\ufeffone", ""}, utfbom.Unknown, "one"},
+		{"interior_bom_kept", []string{"\ufeffone\ufeff", "two"}, utfbom.UTF8, "one\ufefftwo"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parts := make([]io.Reader, len(tc.parts))
+			for i, p := range tc.parts {
+				parts[i] = iotest.OneByteReader(strings.NewReader(p))
+			}
+
+			rd := utfbom.NewMultiReader(parts...)
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+			be.Equal(t, rd.Enc, tc.enc)
+		})
+	}
+}
+
+func TestMultiReader_Error(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewMultiReader(strings.NewReader("\ufeffone"), iotest.ErrReader(errors.New("disk failure")))
+
+	out, err := io.ReadAll(rd)
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.Equal(t, string(out), "one")
+}
+
+func ExampleNewMultiReader() {
+	segments := []io.Reader{
+		bytes.NewReader([]byte("\xef\xbb\xbf2024-01-01 started\n")),
+		bytes.NewReader([]byte("\xef\xbb\xbf2024-01-02 stopped\n")),
+	}
+
+	rd := utfbom.NewMultiReader(segments...)
+
+	out, err := io.ReadAll(rd)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Print(string(out))
+	fmt.Println("detected encoding:", rd.Enc)
+
+	// output:
+	// 2024-01-01 started
+	// 2024-01-02 stopped
+	// detected encoding: UTF8
+}
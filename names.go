@@ -0,0 +1,179 @@
+package utfbom
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	_ encoding.TextMarshaler   = Unknown
+	_ encoding.TextUnmarshaler = (*Encoding)(nil)
+	_ flag.Value               = (*Encoding)(nil)
+)
+
+// ErrUnknownEncoding is returned when an encoding name can't be recognized.
+var ErrUnknownEncoding = errors.New("utfbom: unknown encoding")
+
+// aliases maps normalized common names and IANA charset labels to encodings,
+// in addition to the names returned by String.
+var aliases = map[string]Encoding{
+	"utf8sig":   UTF8, // Python codec writing and stripping the BOM
+	"csutf8":    UTF8,
+	"utf16be":   UTF16BigEndian,
+	"csutf16be": UTF16BigEndian,
+	"utf16le":   UTF16LittleEndian,
+	"csutf16le": UTF16LittleEndian,
+	"utf32be":   UTF32BigEndian,
+	"csutf32be": UTF32BigEndian,
+	"utf32le":   UTF32LittleEndian,
+	"csutf32le": UTF32LittleEndian,
+	"csutf7":    UTF7,
+	"csscsu":    SCSU,
+	"csbocu1":   BOCU1,
+	"csgb18030": GB18030,
+}
+
+// ParseEncoding returns the encoding called s.
+// It accepts the names returned by String, common names such as "UTF-8" or "utf-16be",
+// the Python "utf-8-sig" codec name and IANA charset labels such as "csUTF16LE".
+// The other Python codec aliases, such as "U8", are accepted by ParsePythonCodec only.
+// Case, dashes, underscores and spaces are ignored.
+//
+// "UTF-16" and "UTF-32" without a byte order are rejected, since the BOM to use can't be told from them.
+// It returns an error wrapping ErrUnknownEncoding if s is not recognized.
+func ParseEncoding(s string) (Encoding, error) {
+	enc, ok := lookupName(s)
+	if !ok {
+		return Unknown, fmt.Errorf("%w: %q", ErrUnknownEncoding, s)
+	}
+
+	return enc, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The encoding is marshaled as its String name.
+func (e Encoding) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It accepts the names recognized by ParseEncoding. Empty text is unmarshaled as Unknown.
+func (e *Encoding) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*e = Unknown
+
+		return nil
+	}
+
+	enc, err := ParseEncoding(string(text))
+	if err != nil {
+		return err
+	}
+
+	*e = enc
+
+	return nil
+}
+
+// Set implements the flag.Value interface, so an Encoding can be declared with flag.Var.
+// It accepts the names recognized by ParseEncoding,
+// the error for an unrecognized name lists the valid ones.
+func (e *Encoding) Set(s string) error {
+	enc, err := ParseEncoding(s)
+	if err != nil {
+		return fmt.Errorf("%w, valid values are %s", err, strings.Join(validNames(), ", "))
+	}
+
+	*e = enc
+
+	return nil
+}
+
+// validNames returns the names of all encodings having a BOM, built-in ones first.
+func validNames() []string {
+	encs := slices.Sorted(slices.Values(detectionOrder()))
+	names := make([]string, len(encs))
+
+	for i, enc := range encs {
+		names[i] = enc.String()
+	}
+
+	return names
+}
+
+// CharsetName returns the IANA charset name of the encoding, such as "UTF-8" or "UTF-16LE",
+// suitable for the charset parameter of a Content-Type header.
+// It returns an empty string for Unknown, UTF1, UTFEBCDIC and registered encodings,
+// which have no registered charset name.
+func (e Encoding) CharsetName() string {
+	switch e {
+	default:
+		return ""
+	case UTF8:
+		return "UTF-8"
+	case UTF16BigEndian:
+		return "UTF-16BE"
+	case UTF16LittleEndian:
+		return "UTF-16LE"
+	case UTF32BigEndian:
+		return "UTF-32BE"
+	case UTF32LittleEndian:
+		return "UTF-32LE"
+	case UTF7:
+		return "UTF-7"
+	case SCSU:
+		return "SCSU"
+	case BOCU1:
+		return "BOCU-1"
+	case GB18030:
+		return "GB18030"
+	}
+}
+
+// FromCharsetName returns the encoding of an IANA charset name or alias, such as "utf-8" or "csUTF16LE",
+// the reverse of CharsetName. Case is ignored.
+// It returns Unknown if there is no match or the charset doesn't tell the byte order, like "UTF-16".
+func FromCharsetName(name string) Encoding {
+	enc, ok := lookupName(name)
+	if !ok || enc.CharsetName() == "" {
+		return Unknown
+	}
+
+	return enc
+}
+
+// lookupName returns the encoding called name, ignoring case, dashes, underscores and spaces.
+func lookupName(name string) (Encoding, bool) {
+	key := normalizeName(name)
+	if key == "unknown" {
+		return Unknown, true
+	}
+
+	if enc, ok := aliases[key]; ok {
+		return enc, true
+	}
+
+	for _, enc := range detectionOrder() {
+		if normalizeName(enc.String()) == key {
+			return enc, true
+		}
+	}
+
+	return Unknown, false
+}
+
+// normalizeName lowercases name and drops the separators people put into encoding names.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', ' ':
+			return -1
+		default:
+			return r
+		}
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
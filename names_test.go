@@ -0,0 +1,295 @@
+package utfbom_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestParseEncoding(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expected utfbom.Encoding
+	}{
+		{"Unknown", utfbom.Unknown},
+		{"UTF-8", utfbom.UTF8},
+		{"utf-8-sig", utfbom.UTF8},
+		{"utf_8_sig", utfbom.UTF8},
+		{"csUTF8", utfbom.UTF8},
+		{"utf-16be", utfbom.UTF16BigEndian},
+		{"csUTF16BE", utfbom.UTF16BigEndian},
+		{"UTF-16LE", utfbom.UTF16LittleEndian},
+		{"UTF-32BE", utfbom.UTF32BigEndian},
+		{"UTF-32LE", utfbom.UTF32LittleEndian},
+		{"csUTF32LE", utfbom.UTF32LittleEndian},
+		{"UTF-7", utfbom.UTF7},
+		{"csSCSU", utfbom.SCSU},
+		{"BOCU-1", utfbom.BOCU1},
+		{"csBOCU-1", utfbom.BOCU1},
+		{"GB18030", utfbom.GB18030},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			enc, err := utfbom.ParseEncoding(tc.name)
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.expected)
+		})
+	}
+}
+
+func TestParseEncoding_Unknown(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", " ", "UTF-16", "utf-32", "latin1", "utf-8-bom"} {
+		enc, err := utfbom.ParseEncoding(name)
+		be.True(t, errors.Is(err, utfbom.ErrUnknownEncoding))
+		be.Equal(t, enc, utfbom.Unknown)
+	}
+}
+
+func ExampleParseEncoding() {
+	enc, err := utfbom.ParseEncoding("utf-16le")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s %x\n", enc, enc.Bytes())
+
+	_, err = utfbom.ParseEncoding("latin1")
+	fmt.Println(err)
+
+	// output:
+	// UTF16LittleEndian fffe
+	// utfbom: unknown encoding: "latin1"
+}
+
+func TestEncoding_Set(t *testing.T) {
+	t.Parallel()
+
+	enc := utfbom.UTF8
+
+	err := enc.Set("latin1")
+	be.True(t, errors.Is(err, utfbom.ErrUnknownEncoding))
+	be.Equal(t, enc, utfbom.UTF8)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	fs.Var(&enc, "encoding", "output encoding")
+
+	err = fs.Parse([]string{"-encoding", "utf-16le"})
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF16LittleEndian)
+
+	err = fs.Parse([]string{"-encoding", "latin1"})
+	be.True(t, strings.Contains(err.Error(), `unknown encoding: "latin1"`))
+	be.True(t, strings.Contains(err.Error(), "valid values are UTF8, UTF16BigEndian, UTF16LittleEndian"))
+	be.Equal(t, enc, utfbom.UTF16LittleEndian)
+}
+
+func ExampleEncoding_Set() {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+
+	enc := utfbom.UTF8
+	fs.Var(&enc, "encoding", "output encoding")
+
+	err := fs.Parse([]string{"-encoding", "UTF-32BE"})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(enc)
+
+	// output:
+	// UTF32BigEndian
+}
+
+func TestEncoding_CharsetName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		enc      utfbom.Encoding
+		expected string
+	}{
+		{utfbom.Unknown, ""},
+		{utfbom.UTF8, "UTF-8"},
+		{utfbom.UTF16BigEndian, "UTF-16BE"},
+		{utfbom.UTF16LittleEndian, "UTF-16LE"},
+		{utfbom.UTF32BigEndian, "UTF-32BE"},
+		{utfbom.UTF32LittleEndian, "UTF-32LE"},
+		{utfbom.UTF7, "UTF-7"},
+		{utfbom.UTF1, ""},
+		{utfbom.UTFEBCDIC, ""},
+		{utfbom.SCSU, "SCSU"},
+		{utfbom.BOCU1, "BOCU-1"},
+		{utfbom.GB18030, "GB18030"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, tc.enc.CharsetName(), tc.expected)
+
+			if tc.expected != "" {
+				be.Equal(t, utfbom.FromCharsetName(tc.expected), tc.enc)
+			}
+		})
+	}
+}
+
+func TestFromCharsetName(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expected utfbom.Encoding
+	}{
+		{"", utfbom.Unknown},
+		{"utf-8", utfbom.UTF8},
+		{"csUTF16LE", utfbom.UTF16LittleEndian},
+		{"utf-32be", utfbom.UTF32BigEndian},
+		{"UTF-16", utfbom.Unknown},
+		{"ISO-8859-1", utfbom.Unknown},
+		{"UTF-EBCDIC", utfbom.Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.FromCharsetName(tc.name), tc.expected)
+		})
+	}
+}
+
+func ExampleEncoding_CharsetName() {
+	enc := utfbom.DetectEncoding([]byte{0xff, 0xfe, 'h', 0x00, 'i', 0x00})
+
+	fmt.Println(mime.FormatMediaType("text/plain", map[string]string{"charset": enc.CharsetName()}))
+
+	// output:
+	// text/plain; charset=UTF-16LE
+}
+
+func TestEncoding_MarshalText(t *testing.T) {
+	t.Parallel()
+
+	for enc := utfbom.Unknown; enc <= utfbom.GB18030; enc++ {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			text, err := enc.MarshalText()
+			be.Err(t, err, nil)
+			be.Equal(t, string(text), enc.String())
+
+			var got utfbom.Encoding
+
+			err = got.UnmarshalText(text)
+			be.Err(t, err, nil)
+			be.Equal(t, got, enc)
+		})
+	}
+}
+
+func TestEncoding_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		text     string
+		expected utfbom.Encoding
+	}{
+		{"", utfbom.Unknown},
+		{"unknown", utfbom.Unknown},
+		{"UTF8", utfbom.UTF8},
+		{"utf8", utfbom.UTF8},
+		{"utf-8", utfbom.UTF8},
+		{" UTF-8 ", utfbom.UTF8},
+		{"utf16bigendian", utfbom.UTF16BigEndian},
+		{"UTF-16BE", utfbom.UTF16BigEndian},
+		{"utf-16le", utfbom.UTF16LittleEndian},
+		{"utf_16_le", utfbom.UTF16LittleEndian},
+		{"UTF-32BE", utfbom.UTF32BigEndian},
+		{"utf-32le", utfbom.UTF32LittleEndian},
+		{"utf-7", utfbom.UTF7},
+		{"UTF-EBCDIC", utfbom.UTFEBCDIC},
+		{"bocu-1", utfbom.BOCU1},
+		{"gb18030", utfbom.GB18030},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			t.Parallel()
+
+			enc := utfbom.UTF32LittleEndian
+
+			err := enc.UnmarshalText([]byte(tc.text))
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.expected)
+		})
+	}
+}
+
+func TestEncoding_UnmarshalText_Unknown(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{"utf-16", "latin1", "utf8x"} {
+		enc := utfbom.UTF8
+
+		err := enc.UnmarshalText([]byte(text))
+		be.True(t, errors.Is(err, utfbom.ErrUnknownEncoding))
+		be.Equal(t, enc, utfbom.UTF8)
+	}
+}
+
+func TestEncoding_JSON(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Input  utfbom.Encoding            `json:"input"`
+		Output map[string]utfbom.Encoding `json:"output"`
+	}
+
+	var cfg config
+
+	err := json.Unmarshal([]byte(`{"input":"utf-16le","output":{"excel":"UTF-8"}}`), &cfg)
+	be.Err(t, err, nil)
+	be.Equal(t, cfg.Input, utfbom.UTF16LittleEndian)
+	be.Equal(t, cfg.Output["excel"], utfbom.UTF8)
+
+	out, err := json.Marshal(cfg)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), `{"input":"UTF16LittleEndian","output":{"excel":"UTF8"}}`)
+
+	err = json.Unmarshal([]byte(`{"input":"ebcdic"}`), &cfg)
+	be.True(t, errors.Is(err, utfbom.ErrUnknownEncoding))
+}
+
+func ExampleEncoding_UnmarshalText() {
+	var cfg struct {
+		Encoding utfbom.Encoding `json:"encoding"`
+	}
+
+	err := json.Unmarshal([]byte(`{"encoding": "utf-16le"}`), &cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(cfg.Encoding)
+
+	// output:
+	// UTF16LittleEndian
+}
@@ -0,0 +1,305 @@
+package utfbom
+
+import (
+	"fmt"
+)
+
+// BOMPolicy describes how the Byte Order Mark (BOM) is treated by readers and writers.
+// It is modeled after the BOM policies of golang.org/x/text/encoding/unicode.
+type BOMPolicy int
+
+const (
+	// IgnoreBOM treats the BOM as part of the payload:
+	// readers detect but keep it, writers never add one.
+	IgnoreBOM BOMPolicy = iota
+
+	// UseBOM removes the BOM when reading and adds one when writing.
+	// It is the default policy.
+	UseBOM
+
+	// ExpectBOM is like UseBOM, but readers fail with ErrBOMExpected if the BOM is missing.
+	ExpectBOM
+
+	// ForbidBOM makes readers fail with ErrBOMForbidden if a BOM is present,
+	// writers never add one.
+	ForbidBOM
+)
+
+// String returns the human-readable name of the policy.
+func (p BOMPolicy) String() string {
+	switch p {
+	case IgnoreBOM:
+		return "IgnoreBOM"
+	case UseBOM:
+		return "UseBOM"
+	case ExpectBOM:
+		return "ExpectBOM"
+	case ForbidBOM:
+		return "ForbidBOM"
+	default:
+		return "Unknown"
+	}
+}
+
+// writesBOM reports whether writers following the policy add a BOM.
+func (p BOMPolicy) writesBOM() bool {
+	return p == UseBOM || p == ExpectBOM
+}
+
+// Option configures the behavior of readers and writers provided by the package.
+type Option func(*options)
+
+type options struct {
+	policy    BOMPolicy
+	encodings []Encoding     // encodings accepted by ExpectBOM, any if empty
+	prefer    Encoding       // encoding winning ambiguous BOMs, see DetectEncodingPreferring
+	scrub     bool           // remove interior U+FEFF, see ScrubInterior
+	buf       []byte         // caller-owned working memory, see WithBuffer
+	reject    bool           // fail on invalid sequences, see RejectInvalid
+	lazy      bool           // detect on the first read only, see WithEagerDetection
+	dedup     bool           // drop the BOM repeated by the payload, see DedupBOM
+	validate  bool           // check UTF-8 payloads, see WithValidateUTF8
+	extended  bool           // detect the extended signatures, see WithExtendedBOMs
+	onDetect  func(Encoding) // observes detections, see WithOnDetect
+	onError   func(error)    // observes failures, see WithOnError
+}
+
+func newOptions(opts []Option) options {
+	var o options
+
+	o.set(opts)
+
+	return o
+}
+
+// set resets o to the defaults and applies opts.
+// Readers call it on their own field, so that the options don't escape to the heap separately.
+func (o *options) set(opts []Option) {
+	*o = options{
+		policy: UseBOM,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// check returns an error if the detected encoding violates the policy.
+func (o options) check(enc Encoding) error {
+	switch {
+	case o.policy == ForbidBOM && enc != Unknown:
+		return fmt.Errorf("%w: %s", ErrBOMForbidden, enc)
+	case o.policy == ExpectBOM && (enc == Unknown || len(o.encodings) != 0 && !enc.AnyOf(o.encodings...)):
+		return fmt.Errorf("%w: got %s", ErrBOMExpected, enc)
+	default:
+		return nil
+	}
+}
+
+// detected calls the hooks set by WithOnDetect, if any.
+func (o options) detected(enc Encoding) {
+	if o.onDetect != nil {
+		o.onDetect(enc)
+	}
+}
+
+// strips reports whether the BOM of enc is removed from the stream.
+func (o options) strips(enc Encoding) bool {
+	return enc != Unknown && o.policy != IgnoreBOM
+}
+
+// WithPolicy sets the BOM policy.
+func WithPolicy(p BOMPolicy) Option {
+	return func(o *options) {
+		o.policy = p
+		o.encodings = nil
+	}
+}
+
+// Passthrough keeps the Byte Order Mark (BOM) in the stream.
+// The BOM is still detected and reported, but it is delivered to the caller
+// along with the rest of the payload.
+// It is a shorthand for WithPolicy(IgnoreBOM).
+func Passthrough() Option {
+	return WithPolicy(IgnoreBOM)
+}
+
+// Forbid makes reading fail with ErrBOMForbidden when the stream starts with a BOM.
+// It is useful for formats that must not carry a BOM, such as JSON (RFC 8259).
+// It is a shorthand for WithPolicy(ForbidBOM).
+func Forbid() Option {
+	return WithPolicy(ForbidBOM)
+}
+
+// Expect makes reading fail with ErrBOMExpected unless the stream starts with the BOM
+// of one of the given encodings. With no encodings given, any BOM is accepted.
+// It is WithPolicy(ExpectBOM) narrowed down to the given encodings.
+func Expect(encs ...Encoding) Option {
+	return func(o *options) {
+		o.policy = ExpectBOM
+		o.encodings = encs
+	}
+}
+
+// Prefer makes detection report enc whenever the stream starts with its BOM,
+// even if the stream also starts with the longer BOM of another encoding.
+// Use Prefer(UTF16LittleEndian) for UTF-16 Little Endian payloads
+// that may start with U+0000, see DetectEncodingPreferring.
+func Prefer(enc Encoding) Option {
+	return func(o *options) {
+		o.prefer = enc
+	}
+}
+
+// ScrubInterior makes readers remove U+FEFF (zero width no-break space) characters
+// appearing mid-stream, which happens when BOM-prefixed files are concatenated,
+// same as StripInterior does for byte slices.
+// A BOM kept at the beginning of the stream under the IgnoreBOM policy is left alone.
+//
+// Reader scrubs UTF-8 payloads and payloads without a BOM,
+// UTF8Reader scrubs the decoded UTF-16 and UTF-32 payloads as well.
+// To catch characters split across reads, Reader holds back up to two trailing bytes
+// until the next read of the wrapped reader tells what they are.
+func ScrubInterior() Option {
+	return func(o *options) {
+		o.scrub = true
+	}
+}
+
+// RejectInvalid makes decoding fail with ErrInvalidSequence on a payload that is not valid
+// in its encoding, such as UTF-16 with unpaired surrogates, instead of replacing
+// the invalid sequences with utf8.RuneError (U+FFFD). It is honored by DecodeToUTF8
+// and by UTF8Reader, which checks UTF-16 and UTF-32 payloads as they stream through
+// and fails once the text preceding the invalid sequence has been read.
+// The error tells the byte offset of the sequence in the stream, BOM included.
+func RejectInvalid() Option {
+	return func(o *options) {
+		o.reject = true
+	}
+}
+
+// WithValidateUTF8 makes readers check that a payload tagged with a UTF-8 BOM is valid UTF-8,
+// since a BOM is often taken as a promise of it. Payloads without a BOM are not checked.
+//
+// Reader fails with ErrInvalidSequence once the payload preceding the invalid sequence has been read,
+// the error tells the byte offset of the sequence in the stream, BOM included.
+// UTF8Reader replaces invalid sequences with utf8.RuneError (U+FFFD), same as in UTF-16 and UTF-32 payloads,
+// or fails the same way as Reader under RejectInvalid.
+// For byte slices, DecodeToUTF8 validates UTF-8 payloads under RejectInvalid.
+func WithValidateUTF8() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+// WithEagerDetection controls how many bytes readers wait for to detect the BOM.
+// Eager detection, the default, keeps reading until the bytes read either make up a BOM or can't start one,
+// so a stream starting with 0xef 0xbb waits for its third byte.
+//
+// WithEagerDetection(false) makes readers detect the BOM in whatever the first successful read
+// of the wrapped reader returns, so wrapping an interactive input such as os.Stdin
+// never delays the first characters typed. The price is that a BOM split across
+// the first two reads of the wrapped reader is not recognized and is passed on as payload.
+func WithEagerDetection(eager bool) Option {
+	return func(o *options) {
+		o.lazy = !eager
+	}
+}
+
+// DedupBOM makes Writer drop a copy of its own BOM found at the beginning of the payload,
+// so that io.Copy(NewWriter(dst, UTF8, DedupBOM()), src) writes a single BOM
+// whether src carries one or not.
+//
+// Leading payload bytes matching the BOM are held back until they either complete it
+// or stop matching it, in which case they are written out after the BOM.
+// A payload made up of a part of the BOM only is dropped, as it is not valid text in any encoding
+// Writer adds a BOM for. Writers adding no BOM pass the payload through unchanged.
+func DedupBOM() Option {
+	return func(o *options) {
+		o.dedup = true
+	}
+}
+
+// WithExtendedBOMs makes readers and writers detect the signatures of UTF-7 and the other rare encodings
+// listed by DetectEncodingExtended, which are left alone by default as plain ASCII and base64 text may start with them.
+func WithExtendedBOMs() Option {
+	return func(o *options) {
+		o.extended = true
+	}
+}
+
+// WithOnDetect makes readers call fn with the encoding of the BOM, Unknown if there is none,
+// once per stream, as soon as it is detected. fn is called before the policy is checked,
+// so payloads rejected under ExpectBOM or ForbidBOM are observed as well.
+//
+// It lets services count the payloads still arriving with a BOM, say with a Prometheus counter,
+// without looking at Reader.Enc after every read. fn is called by the goroutine reading from the reader,
+// and again for the next stream after Reader.Reset.
+//
+// Hooks add up rather than replace one another: with several WithOnDetect options,
+// the functions are called in the order the options are given. A nil fn is ignored.
+func WithOnDetect(fn func(Encoding)) Option {
+	return func(o *options) {
+		prev := o.onDetect
+
+		switch {
+		case fn == nil:
+		case prev == nil:
+			o.onDetect = fn
+		default:
+			o.onDetect = func(enc Encoding) {
+				prev(enc)
+				fn(enc)
+			}
+		}
+	}
+}
+
+// WithOnError makes readers call fn with the first error other than io.EOF
+// returned by Read or WriteTo, such as an error wrapping ErrRead or ErrBOMForbidden,
+// once per stream, so that failures can be logged or counted where the reader is set up
+// rather than wherever it is read. Same as with WithOnDetect, fn is called again after Reader.Reset,
+// and several WithOnError options call their functions in the order given.
+func WithOnError(fn func(error)) Option {
+	return func(o *options) {
+		prev := o.onError
+
+		switch {
+		case fn == nil:
+		case prev == nil:
+			o.onError = fn
+		default:
+			o.onError = func(err error) {
+				prev(err)
+				fn(err)
+			}
+		}
+	}
+}
+
+// minBufferLen is the length of the shortest buffer accepted by WithBuffer.
+const minBufferLen = 16
+
+// WithBuffer hands buf to readers and writers as their working memory,
+// analogous to bufio.NewReaderSize, but with full control over the allocation.
+// The buffer of ScrubInterior and the buffers UTF8Reader decodes UTF-16 and UTF-32 payloads with
+// are carved out of buf instead of being allocated, 4096 bytes each, on first use.
+// Reader.WriteTo and Writer.ReadFrom copy through buf instead of a buffer allocated by io.Copy,
+// except for Reader.WriteTo under ScrubInterior.
+//
+// With WithBuffer, Read and Write calls allocate nothing: the only allocations are
+// the Reader, UTF8Reader or Writer made by its constructor.
+// Note that Reader allocates no buffer at all unless ScrubInterior is used.
+//
+// buf must neither be used by the caller nor be shared with another reader or writer
+// while the one it is given to is in use.
+// WithBuffer panics if buf is shorter than 16 bytes.
+func WithBuffer(buf []byte) Option {
+	if len(buf) < minBufferLen {
+		panic(fmt.Sprintf("utfbom: WithBuffer buffer must be at least %d bytes long, got %d", minBufferLen, len(buf)))
+	}
+
+	return func(o *options) {
+		o.buf = buf
+	}
+}
@@ -0,0 +1,578 @@
+package utfbom_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestPassthrough(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input []byte
+		enc   utfbom.Encoding
+	}{
+		{"empty", nil, utfbom.Unknown},
+		{"no_bom", []byte("hello"), utfbom.Unknown},
+		{"utf8", append(utf8BOM, "hello"...), utfbom.UTF8},
+		{"utf16le", append(utf16LEBOM, 'h', 0x00), utfbom.UTF16LittleEndian},
+		{"utf32be", append(utf32BEBOM, 0x00, 0x00, 0x00, 'h'), utfbom.UTF32BigEndian},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd := utfbom.NewReader(bytes.NewReader(tc.input), utfbom.Passthrough())
+			be.Err(t, iotest.TestReader(rd, tc.input), nil)
+			be.Equal(t, rd.Enc, tc.enc)
+		})
+	}
+}
+
+func ExamplePassthrough() {
+	rd := utfbom.NewReader(strings.NewReader("\ufeffhello"), utfbom.Passthrough())
+
+	out, err := io.ReadAll(rd)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("detected encoding:", rd.Enc)
+	fmt.Printf("%q\n", out)
+
+	// output:
+	// detected encoding: UTF8
+	// "\ufeffhello"
+}
+
+func TestForbid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input []byte
+		err   error
+	}{
+		{"empty", nil, nil},
+		{"no_bom", []byte(`{"a":1}`), nil},
+		{"incomplete_bom", []byte{0xef, 0xbb}, nil},
+		{"utf8", append(utf8BOM, `{"a":1}`...), utfbom.ErrBOMForbidden},
+		{"utf16le", append(utf16LEBOM, '{', 0x00), utfbom.ErrBOMForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd := utfbom.NewReader(bytes.NewReader(tc.input), utfbom.Forbid())
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, tc.err)
+
+			if tc.err == nil {
+				be.Equal(t, out, tc.input)
+			}
+		})
+	}
+}
+
+func TestForbid_ErrorIsSticky(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("\ufeff{}"), utfbom.Forbid())
+
+	buf := make([]byte, 10)
+	for range 3 {
+		n, err := rd.Read(buf)
+		be.Equal(t, n, 0)
+		be.Err(t, err, utfbom.ErrBOMForbidden)
+		be.Err(t, err, "utfbom: BOM is forbidden: UTF8")
+	}
+
+	enc, err := rd.Encoding()
+	be.Equal(t, enc, utfbom.UTF8)
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+}
+
+func TestExpect(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   []byte
+		encs    []utfbom.Encoding
+		payload []byte
+		err     error
+	}{
+		{"empty", nil, nil, nil, utfbom.ErrBOMExpected},
+		{"no_bom", []byte("hello"), nil, nil, utfbom.ErrBOMExpected},
+		{"any_bom", append(utf8BOM, "hello"...), nil, []byte("hello"), nil},
+		{"expected_bom", append(utf16LEBOM, 'h', 0x00), []utfbom.Encoding{utfbom.UTF16LittleEndian}, []byte{'h', 0x00}, nil},
+		{"one_of_expected_boms", append(utf16BEBOM, 0x00, 'h'), []utfbom.Encoding{utfbom.UTF16LittleEndian, utfbom.UTF16BigEndian}, []byte{0x00, 'h'}, nil},
+		{"unexpected_bom", append(utf8BOM, "hello"...), []utfbom.Encoding{utfbom.UTF16LittleEndian}, nil, utfbom.ErrBOMExpected},
+		{"utf32le_is_not_utf16le", append(utf32LEBOM, 'h', 0x00, 0x00, 0x00), []utfbom.Encoding{utfbom.UTF16LittleEndian}, nil, utfbom.ErrBOMExpected},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd := utfbom.NewReader(bytes.NewReader(tc.input), utfbom.Expect(tc.encs...))
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, tc.err)
+
+			if tc.err == nil {
+				be.Equal(t, out, tc.payload)
+			}
+		})
+	}
+}
+
+func TestExpect_ErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("hello"), utfbom.Expect(utfbom.UTF16LittleEndian))
+
+	_, err := rd.Encoding()
+	be.Err(t, err, "utfbom: BOM is expected: got Unknown")
+}
+
+func TestBOMPolicy_String(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.IgnoreBOM.String(), "IgnoreBOM")
+	be.Equal(t, utfbom.UseBOM.String(), "UseBOM")
+	be.Equal(t, utfbom.ExpectBOM.String(), "ExpectBOM")
+	be.Equal(t, utfbom.ForbidBOM.String(), "ForbidBOM")
+	be.Equal(t, utfbom.BOMPolicy(999).String(), "Unknown")
+}
+
+func TestWithPolicy(t *testing.T) {
+	t.Parallel()
+
+	withBOM := append(utf16LEBOM, 'h', 0x00)
+	withoutBOM := []byte{'h', 0x00}
+
+	testCases := []struct {
+		name   string
+		policy utfbom.BOMPolicy
+		input  []byte
+
+		read   []byte // Reader output
+		trim   []byte // TrimWriter output
+		err    error  // Reader and TrimWriter error
+		writer []byte // Writer output for withoutBOM input
+	}{
+		{"ignore_with_bom", utfbom.IgnoreBOM, withBOM, withBOM, withBOM, nil, withoutBOM},
+		{"ignore_without_bom", utfbom.IgnoreBOM, withoutBOM, withoutBOM, withoutBOM, nil, withoutBOM},
+		{"use_with_bom", utfbom.UseBOM, withBOM, withoutBOM, withoutBOM, nil, withBOM},
+		{"use_without_bom", utfbom.UseBOM, withoutBOM, withoutBOM, withoutBOM, nil, withBOM},
+		{"expect_with_bom", utfbom.ExpectBOM, withBOM, withoutBOM, withoutBOM, nil, withBOM},
+		{"expect_without_bom", utfbom.ExpectBOM, withoutBOM, nil, nil, utfbom.ErrBOMExpected, withBOM},
+		{"forbid_with_bom", utfbom.ForbidBOM, withBOM, nil, nil, utfbom.ErrBOMForbidden, withoutBOM},
+		{"forbid_without_bom", utfbom.ForbidBOM, withoutBOM, withoutBOM, withoutBOM, nil, withoutBOM},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opt := utfbom.WithPolicy(tc.policy)
+
+			out, err := io.ReadAll(utfbom.NewReader(bytes.NewReader(tc.input), opt))
+			be.Err(t, err, tc.err)
+
+			if tc.err == nil {
+				be.Equal(t, out, tc.read)
+			}
+
+			var trimmed bytes.Buffer
+
+			tw := utfbom.NewTrimWriter(&trimmed, opt)
+			_, err = tw.Write(tc.input)
+			be.Err(t, err, tc.err)
+			be.Err(t, tw.Flush(), tc.err)
+
+			if tc.err == nil {
+				be.Equal(t, trimmed.Bytes(), tc.trim)
+			}
+
+			var written bytes.Buffer
+
+			_, err = utfbom.NewWriter(&written, utfbom.UTF16LittleEndian, opt).Write(withoutBOM)
+			be.Err(t, err, nil)
+			be.Equal(t, written.Bytes(), tc.writer)
+		})
+	}
+}
+
+func TestUTF8Reader_IgnoreBOM(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewUTF8Reader(bytes.NewReader(encode(utfbom.UTF16BigEndian, "hey")), utfbom.Passthrough())
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\ufeffhey")
+	be.Equal(t, rd.Enc, utfbom.UTF16BigEndian)
+}
+
+func TestPrefer(t *testing.T) {
+	t.Parallel()
+
+	// "\x00hi" in UTF-16 Little Endian, its BOM and first code unit look like the UTF-32LE BOM.
+	input := []byte{0xff, 0xfe, 0x00, 0x00, 'h', 0x00, 'i', 0x00}
+	expected := []byte{0x00, 0x00, 'h', 0x00, 'i', 0x00}
+
+	rd := utfbom.NewReader(bytes.NewReader(input), utfbom.Prefer(utfbom.UTF16LittleEndian))
+	be.Err(t, iotest.TestReader(rd, expected), nil)
+	be.Equal(t, rd.Enc, utfbom.UTF16LittleEndian)
+
+	out, err := io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(input), utfbom.Prefer(utfbom.UTF16LittleEndian)))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\x00hi")
+
+	var buf bytes.Buffer
+
+	w := utfbom.NewTrimWriter(&buf, utfbom.Prefer(utfbom.UTF16LittleEndian))
+	_, err = w.Write(input)
+	be.Err(t, err, nil)
+	be.Equal(t, w.Enc, utfbom.UTF16LittleEndian)
+	be.Equal(t, buf.Bytes(), expected)
+}
+
+func TestWithBuffer(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat(multilingual+"\ufeff", 10)
+	scrubbed := strings.Repeat(multilingual, 10)
+
+	for _, size := range []int{16, 17, 31, 64, 4096} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			t.Parallel()
+
+			buf := make([]byte, size)
+
+			for _, enc := range []utfbom.Encoding{utfbom.UTF8, utfbom.UTF16LittleEndian, utfbom.UTF32BigEndian} {
+				rd := utfbom.NewUTF8Reader(bytes.NewReader(encode(enc, input)), utfbom.WithBuffer(buf), utfbom.ScrubInterior())
+
+				out, err := io.ReadAll(rd)
+				be.Err(t, err, nil)
+				be.Equal(t, string(out), scrubbed)
+			}
+
+			var out bytes.Buffer
+
+			n, err := utfbom.NewWriter(&out, utfbom.UTF8, utfbom.WithBuffer(buf)).ReadFrom(iotest.HalfReader(strings.NewReader(input)))
+			be.Err(t, err, nil)
+			be.Equal(t, n, int64(len(input)))
+			be.Equal(t, out.String(), "\ufeff"+input)
+		})
+	}
+}
+
+func TestWithBuffer_Allocations(t *testing.T) {
+	src := bytes.NewReader(nil)
+	input := encode(utfbom.UTF16LittleEndian, strings.Repeat(multilingual, 100))
+	opts := []utfbom.Option{utfbom.WithBuffer(make([]byte, 512)), utfbom.ScrubInterior()}
+	buf := make([]byte, 100)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		src.Reset(input)
+
+		rd := utfbom.NewUTF8Reader(src, opts...)
+
+		for {
+			_, err := rd.Read(buf)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	// UTF8Reader and the Reader it wraps
+	be.True(t, allocs <= 2)
+}
+
+func TestWithBuffer_AllocationsInvalidUTF8(t *testing.T) {
+	src := bytes.NewReader(nil)
+	// every byte is replaced with the three bytes of U+FFFD
+	input := append([]byte("\ufeff"), bytes.Repeat([]byte{0xff}, 1000)...)
+	opts := []utfbom.Option{utfbom.WithBuffer(make([]byte, 512)), utfbom.WithValidateUTF8()}
+	buf := make([]byte, 100)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		src.Reset(input)
+
+		rd := utfbom.NewUTF8Reader(src, opts...)
+
+		for {
+			_, err := rd.Read(buf)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	// UTF8Reader and the Reader it wraps
+	be.True(t, allocs <= 2)
+
+	for _, size := range []int{16, 17, 512} {
+		out, err := io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(input), utfbom.WithBuffer(make([]byte, size)), utfbom.WithValidateUTF8()))
+		be.Err(t, err, nil)
+		be.Equal(t, string(out), strings.Repeat("\ufffd", 1000))
+	}
+}
+
+func TestWithBuffer_TooShort(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		be.True(t, recover() != nil)
+	}()
+
+	utfbom.WithBuffer(make([]byte, 15))
+}
+
+func TestWithEagerDetection(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		chunks   []string
+		eager    bool
+		enc      utfbom.Encoding
+		expected string
+	}{
+		{"lazy_not_a_bom", []string{"+", "/v8"}, false, utfbom.Unknown, "+"},
+		{"lazy_whole_bom", []string{"\xef\xbb\xbfx", "y"}, false, utfbom.UTF8, "x"},
+		{"lazy_split_bom", []string{"\xef\xbb", "\xbfx"}, false, utfbom.Unknown, "\xef\xbb"},
+		{"eager_split_bom", []string{"\xef\xbb", "\xbfx"}, true, utfbom.UTF8, "x"},
+	}
+
+	wrappers := map[string]func(io.Reader) io.Reader{
+		"plain": func(rd io.Reader) io.Reader { return rd },
+		"bufio": func(rd io.Reader) io.Reader { return bufio.NewReader(rd) },
+	}
+
+	for _, tc := range testCases {
+		for name, wrap := range wrappers {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				t.Parallel()
+
+				// the chunks after the first are read only by eager detection or by the second Read
+				rd := utfbom.NewReader(wrap(&dribbleReader{t: t, chunks: tc.chunks}), utfbom.WithEagerDetection(tc.eager))
+				buf := make([]byte, 512)
+
+				n, err := rd.Read(buf)
+				be.Err(t, err, nil)
+				be.Equal(t, string(buf[:n]), tc.expected)
+				be.Equal(t, rd.Enc, tc.enc)
+			})
+		}
+	}
+}
+
+func TestWithExtendedBOMs(t *testing.T) {
+	t.Parallel()
+
+	const input = "+/v8AAAA"
+
+	// base64 starting like the UTF-7 signature is left alone by default
+	out, err := io.ReadAll(utfbom.NewReader(strings.NewReader(input)))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), input)
+
+	var buf bytes.Buffer
+
+	_, enc, err := utfbom.Copy(&buf, strings.NewReader(input))
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.Unknown)
+	be.Equal(t, buf.String(), input)
+
+	rd := utfbom.NewReader(strings.NewReader(input), utfbom.WithExtendedBOMs())
+
+	out, err = io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "AAAA")
+	be.Equal(t, rd.Enc, utfbom.UTF7)
+
+	var w bytes.Buffer
+
+	tw := utfbom.NewTrimWriter(&w, utfbom.WithExtendedBOMs())
+	_, err = io.WriteString(tw, input)
+	be.Err(t, err, nil)
+	be.Err(t, tw.Flush(), nil)
+	be.Equal(t, w.String(), "AAAA")
+}
+
+func TestWithOnDetect(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input io.Reader
+		opts  []utfbom.Option
+		enc   utfbom.Encoding
+	}{
+		{"bom", strings.NewReader("\ufeffhello"), nil, utfbom.UTF8},
+		{"no_bom", strings.NewReader("hello"), nil, utfbom.Unknown},
+		{"empty", strings.NewReader(""), nil, utfbom.Unknown},
+		{"peeker", bufio.NewReader(strings.NewReader("\xff\xfeh\x00")), nil, utfbom.UTF16LittleEndian},
+		{"rejected", strings.NewReader("\ufeffhello"), []utfbom.Option{utfbom.Forbid()}, utfbom.UTF8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []utfbom.Encoding
+
+			opts := append([]utfbom.Option{utfbom.WithOnDetect(func(enc utfbom.Encoding) {
+				got = append(got, enc)
+			})}, tc.opts...)
+
+			_, _ = io.ReadAll(iotest.OneByteReader(utfbom.NewReader(tc.input, opts...)))
+			be.Equal(t, got, []utfbom.Encoding{tc.enc})
+		})
+	}
+}
+
+func TestWithOnDetect_Reset(t *testing.T) {
+	t.Parallel()
+
+	var got []utfbom.Encoding
+
+	rd := utfbom.NewReader(strings.NewReader("\ufeffa"), utfbom.WithOnDetect(func(enc utfbom.Encoding) {
+		got = append(got, enc)
+	}))
+
+	_, err := io.Copy(io.Discard, rd)
+	be.Err(t, err, nil)
+
+	rd.Reset(strings.NewReader("b"))
+
+	_, err = io.Copy(io.Discard, rd)
+	be.Err(t, err, nil)
+
+	be.Equal(t, got, []utfbom.Encoding{utfbom.UTF8, utfbom.Unknown})
+}
+
+func TestWithOnDetect_Chained(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+
+	rd := utfbom.NewReader(iotest.ErrReader(errors.New("broken")),
+		utfbom.WithOnDetect(func(enc utfbom.Encoding) { got = append(got, "first "+enc.String()) }),
+		utfbom.WithOnError(func(error) { got = append(got, "first error") }),
+		utfbom.WithOnDetect(nil),
+		utfbom.WithOnError(nil),
+		utfbom.WithOnDetect(func(enc utfbom.Encoding) { got = append(got, "second "+enc.String()) }),
+		utfbom.WithOnError(func(error) { got = append(got, "second error") }),
+	)
+
+	_, err := io.ReadAll(rd)
+	be.Err(t, err, utfbom.ErrRead)
+	be.Equal(t, got, []string{"first error", "second error"})
+
+	got = nil
+
+	rd.Reset(strings.NewReader("\ufeffa"))
+
+	_, err = io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, got, []string{"first UTF8", "second UTF8"})
+}
+
+func TestWithOnError(t *testing.T) {
+	t.Parallel()
+
+	errBroken := errors.New("broken")
+
+	testCases := []struct {
+		name     string
+		input    io.Reader
+		opts     []utfbom.Option
+		expected error
+	}{
+		{"forbidden", strings.NewReader("\ufeffhello"), []utfbom.Option{utfbom.Forbid()}, utfbom.ErrBOMForbidden},
+		{"detection", iotest.ErrReader(errBroken), nil, utfbom.ErrRead},
+		{"payload", io.MultiReader(strings.NewReader("hello"), iotest.ErrReader(errBroken)), nil, errBroken},
+		{"none", strings.NewReader("hello"), nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []error
+
+			opts := append([]utfbom.Option{utfbom.WithOnError(func(err error) {
+				got = append(got, err)
+			})}, tc.opts...)
+
+			rd := utfbom.NewReader(tc.input, opts...)
+
+			// sticky errors are passed to the hook once
+			_, err := io.ReadAll(rd)
+			_, _ = rd.Read(make([]byte, 1))
+
+			if tc.expected == nil {
+				be.Err(t, err, nil)
+				be.Equal(t, len(got), 0)
+
+				return
+			}
+
+			be.Err(t, err, tc.expected)
+			be.Equal(t, len(got), 1)
+			be.Err(t, got[0], tc.expected)
+
+			// WriteTo reports its error as well
+			got = nil
+
+			rd.Reset(strings.NewReader("\ufeffhello"))
+
+			_, err = rd.WriteTo(io.Discard)
+			if err != nil {
+				be.Equal(t, got, []error{err})
+			}
+		})
+	}
+}
+
+func ExampleWithOnDetect() {
+	counts := map[utfbom.Encoding]int{}
+	count := utfbom.WithOnDetect(func(enc utfbom.Encoding) {
+		counts[enc]++
+	})
+
+	for _, body := range []string{"\ufeff{}", "{}", "\ufeff[]"} {
+		_, _ = io.Copy(io.Discard, utfbom.NewReader(strings.NewReader(body), count))
+	}
+
+	fmt.Println(counts[utfbom.UTF8], "of 3 requests had a BOM")
+	// output:
+	// 2 of 3 requests had a BOM
+}
@@ -0,0 +1,68 @@
+package utfbom
+
+import (
+	"bytes"
+)
+
+// Shebang starts the interpreter line of scripts, the prelude DetectAfterPrefix allows by default.
+const Shebang = "#!"
+
+// DetectAfterPrefix detects a Byte Order Mark (BOM) at the beginning of b, same as DetectEncoding,
+// or right after the first line of b if that line starts with one of allowed, such as "<?php".
+// With no allowed prefixes given, it looks past a Shebang line, where editors saving scripts
+// insert the BOM on the line following "#!/bin/sh" instead of at the very beginning.
+//
+// Only a UTF-8 BOM is looked for after the first line, which is ASCII.
+// It returns the encoding and the offset of the BOM, which is 0 for a BOM at the beginning of b
+// and for Unknown. A first line without a line feed is the whole of b and is never followed by a BOM.
+func DetectAfterPrefix(b []byte, allowed ...[]byte) (Encoding, int) {
+	if enc := DetectEncoding(b); enc != Unknown {
+		return enc, 0
+	}
+
+	if len(allowed) == 0 {
+		allowed = [][]byte{[]byte(Shebang)}
+	}
+
+	for _, prefix := range allowed {
+		if len(prefix) == 0 || !bytes.HasPrefix(b, prefix) {
+			continue
+		}
+
+		eol := bytes.IndexByte(b, '\n')
+		if eol < 0 {
+			return Unknown, 0
+		}
+
+		// after an ASCII line only a UTF-8 BOM makes sense, bytes such as 0xff 0xfe are payload
+		if bytes.HasPrefix(b[eol+1:], []byte(zwnbsp)) {
+			return UTF8, eol + 1
+		}
+
+		return Unknown, 0
+	}
+
+	return Unknown, 0
+}
+
+// TrimAfterPrefix removes the BOM found by DetectAfterPrefix, keeping the line before it,
+// so the shebang of a script stays the first thing in the file.
+// It returns the encoding of the removed BOM, Unknown if there was none.
+//
+// A BOM at the beginning of b is sliced off without copying, same as with Trim.
+// A BOM after the first line is removed in place, same as with StripInterior:
+// the bytes following it are moved over it and b is returned shortened.
+func TrimAfterPrefix(b []byte, allowed ...[]byte) ([]byte, Encoding) {
+	enc, off := DetectAfterPrefix(b, allowed...)
+
+	switch {
+	case enc == Unknown:
+		return b, Unknown
+	case off == 0:
+		return b[enc.Len():], enc
+	default:
+		n := copy(b[off:], b[off+enc.Len():])
+
+		return b[:off+n], enc
+	}
+}
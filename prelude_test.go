@@ -0,0 +1,67 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestDetectAfterPrefix(t *testing.T) {
+	t.Parallel()
+
+	php := []byte("<?php")
+
+	testCases := []struct {
+		name    string
+		input   string
+		allowed [][]byte
+		enc     utfbom.Encoding
+		offset  int
+		trimmed string
+	}{
+		{"leading_bom", "\ufeff#!/bin/sh\necho", nil, utfbom.UTF8, 0, "#!/bin/sh\necho"},
+		{"after_shebang", "#!/bin/sh\n\ufeffecho", nil, utfbom.UTF8, 10, "#!/bin/sh\necho"},
+		{"after_crlf_shebang", "#!/bin/sh\r\n\ufeffecho", nil, utfbom.UTF8, 11, "#!/bin/sh\r\necho"},
+		{"shebang_without_bom", "#!/bin/sh\necho", nil, utfbom.Unknown, 0, "#!/bin/sh\necho"},
+		{"shebang_only", "#!/bin/sh", nil, utfbom.Unknown, 0, "#!/bin/sh"},
+		{"bom_on_third_line", "#!/bin/sh\n\n\ufeffecho", nil, utfbom.Unknown, 0, "#!/bin/sh\n\n\ufeffecho"},
+		{"no_prelude", "echo\n\ufeffecho", nil, utfbom.Unknown, 0, "echo\n\ufeffecho"},
+		{"bom_at_end", "#!/bin/sh\n\ufeff", nil, utfbom.UTF8, 10, "#!/bin/sh\n"},
+		{"php", "<?php\n\ufeffecho 1;", [][]byte{php}, utfbom.UTF8, 6, "<?php\necho 1;"},
+		{"shebang_not_allowed", "#!/bin/sh\n\ufeffecho", [][]byte{php}, utfbom.Unknown, 0, "#!/bin/sh\n\ufeffecho"},
+		{"several_allowed", "#!/usr/bin/php\n\ufeff<?php", [][]byte{php, []byte(utfbom.Shebang)}, utfbom.UTF8, 15, "#!/usr/bin/php\n<?php"},
+		{"empty_prefix_ignored", "echo\n\ufeffecho", [][]byte{{}}, utfbom.Unknown, 0, "echo\n\ufeffecho"},
+		{"utf16_bytes_after_shebang", "#!/bin/sh\n\xff\xfeecho", nil, utfbom.Unknown, 0, "#!/bin/sh\n\xff\xfeecho"},
+		{"utf7_bytes_after_shebang", "#!/bin/sh\n+/v8AAAA", nil, utfbom.Unknown, 0, "#!/bin/sh\n+/v8AAAA"},
+		{"empty", "", nil, utfbom.Unknown, 0, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			enc, offset := utfbom.DetectAfterPrefix([]byte(tc.input), tc.allowed...)
+			be.Equal(t, enc, tc.enc)
+			be.Equal(t, offset, tc.offset)
+
+			out, enc := utfbom.TrimAfterPrefix([]byte(tc.input), tc.allowed...)
+			be.Equal(t, string(out), tc.trimmed)
+			be.Equal(t, enc, tc.enc)
+		})
+	}
+}
+
+func ExampleTrimAfterPrefix() {
+	script := []byte("#!/usr/bin/env python3\n\ufeffprint('hi')\n")
+
+	script, enc := utfbom.TrimAfterPrefix(script)
+
+	fmt.Println(enc)
+	fmt.Print(string(script))
+	// output:
+	// UTF8
+	// #!/usr/bin/env python3
+	// print('hi')
+}
@@ -0,0 +1,116 @@
+package utfbom
+
+import (
+	"fmt"
+)
+
+// pythonAliases maps the normalized Python codec aliases which aren't common names or IANA charset labels
+// to encodings. They are known to ParsePythonCodec only, so that ParseEncoding doesn't accept them.
+var pythonAliases = map[string]Encoding{
+	"u8":            UTF8,
+	"utf":           UTF8,
+	"cp65001":       UTF8,
+	"u7":            UTF7,
+	"unicode11utf7": UTF7,
+	"gb180302000":   GB18030,
+}
+
+// PythonCodec is an encoding named the way Python codecs name it, so that configuration shared
+// between Python and Go services uses one vocabulary. Python codec names also tell whether the BOM is used:
+// "utf-8" neither writes nor removes one, while "utf-8-sig" writes it when encoding and removes it when decoding.
+type PythonCodec struct {
+	Encoding Encoding
+	// BOM is set for the codecs writing the BOM when encoding and removing it when decoding:
+	// "utf-8-sig", "utf-16" and "utf-32". The codecs with an explicit byte order, such as "utf-16-le",
+	// never write the BOM and decode it as U+FEFF, same as "utf-8".
+	BOM bool
+}
+
+// ParsePythonCodec returns the codec called name, accepting the canonical Python codec names
+// and their aliases, such as "utf-8-sig", "utf_16_le", "U8" or "cp65001".
+// Case, dashes, underscores and spaces are ignored, as Python does.
+//
+// "utf-16" and "utf-32" write the BOM in the native byte order of Python, which is little-endian
+// on all mainstream platforms, so they are parsed as UTF16LittleEndian and UTF32LittleEndian.
+// It returns an error wrapping ErrUnknownEncoding for other names and for encodings Python lacks.
+func ParsePythonCodec(name string) (PythonCodec, error) {
+	key := normalizeName(name)
+
+	switch key {
+	case "utf8sig":
+		return PythonCodec{Encoding: UTF8, BOM: true}, nil
+	case "utf16", "u16":
+		return PythonCodec{Encoding: UTF16LittleEndian, BOM: true}, nil
+	case "utf32", "u32":
+		return PythonCodec{Encoding: UTF32LittleEndian, BOM: true}, nil
+	default:
+	}
+
+	enc, ok := pythonAliases[key]
+	if !ok {
+		enc, ok = lookupName(name)
+	}
+
+	if !ok || enc.PythonCodecName() == "" {
+		return PythonCodec{}, fmt.Errorf("%w: %q", ErrUnknownEncoding, name)
+	}
+
+	return PythonCodec{Encoding: enc}, nil
+}
+
+// String returns the canonical Python name of the codec, such as "utf-8-sig" or "utf-16-le",
+// the one codecs.lookup reports. It returns an empty string for encodings Python lacks.
+func (c PythonCodec) String() string {
+	if !c.BOM {
+		return c.Encoding.PythonCodecName()
+	}
+
+	switch c.Encoding {
+	case UTF8:
+		return "utf-8-sig"
+	case UTF16LittleEndian:
+		return "utf-16"
+	case UTF32LittleEndian:
+		return "utf-32"
+	default:
+		return ""
+	}
+}
+
+// Options returns the options making readers and writers of the package treat the BOM as the codec does:
+// the UseBOM policy for codecs with BOM set, which removes the BOM when reading and writes it when writing,
+// and the IgnoreBOM policy for the others, which leaves it in the payload and never writes it.
+//
+//	w := utfbom.NewWriter(f, codec.Encoding, codec.Options()...)
+func (c PythonCodec) Options() []Option {
+	if c.BOM {
+		return []Option{WithPolicy(UseBOM)}
+	}
+
+	return []Option{WithPolicy(IgnoreBOM)}
+}
+
+// PythonCodecName returns the canonical Python codec name of the encoding, such as "utf-8" or "utf-16-be".
+// These codecs don't use the BOM, see PythonCodec for the ones that do.
+// It returns an empty string for Unknown, registered encodings and the encodings Python lacks,
+// UTF-1, UTF-EBCDIC, SCSU and BOCU-1.
+func (e Encoding) PythonCodecName() string {
+	switch e {
+	default:
+		return ""
+	case UTF8:
+		return "utf-8"
+	case UTF16BigEndian:
+		return "utf-16-be"
+	case UTF16LittleEndian:
+		return "utf-16-le"
+	case UTF32BigEndian:
+		return "utf-32-be"
+	case UTF32LittleEndian:
+		return "utf-32-le"
+	case UTF7:
+		return "utf-7"
+	case GB18030:
+		return "gb18030"
+	}
+}
@@ -0,0 +1,147 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestParsePythonCodec(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expected utfbom.PythonCodec
+		canon    string
+	}{
+		{"utf-8", utfbom.PythonCodec{Encoding: utfbom.UTF8}, "utf-8"},
+		{"UTF8", utfbom.PythonCodec{Encoding: utfbom.UTF8}, "utf-8"},
+		{"U8", utfbom.PythonCodec{Encoding: utfbom.UTF8}, "utf-8"},
+		{"cp65001", utfbom.PythonCodec{Encoding: utfbom.UTF8}, "utf-8"},
+		{"utf-8-sig", utfbom.PythonCodec{Encoding: utfbom.UTF8, BOM: true}, "utf-8-sig"},
+		{"utf_8_sig", utfbom.PythonCodec{Encoding: utfbom.UTF8, BOM: true}, "utf-8-sig"},
+		{"utf-16", utfbom.PythonCodec{Encoding: utfbom.UTF16LittleEndian, BOM: true}, "utf-16"},
+		{"U16", utfbom.PythonCodec{Encoding: utfbom.UTF16LittleEndian, BOM: true}, "utf-16"},
+		{"utf_16_le", utfbom.PythonCodec{Encoding: utfbom.UTF16LittleEndian}, "utf-16-le"},
+		{"UTF-16BE", utfbom.PythonCodec{Encoding: utfbom.UTF16BigEndian}, "utf-16-be"},
+		{"utf_32", utfbom.PythonCodec{Encoding: utfbom.UTF32LittleEndian, BOM: true}, "utf-32"},
+		{"utf-32-be", utfbom.PythonCodec{Encoding: utfbom.UTF32BigEndian}, "utf-32-be"},
+		{"utf_32_le", utfbom.PythonCodec{Encoding: utfbom.UTF32LittleEndian}, "utf-32-le"},
+		{"U7", utfbom.PythonCodec{Encoding: utfbom.UTF7}, "utf-7"},
+		{"unicode-1-1-utf-7", utfbom.PythonCodec{Encoding: utfbom.UTF7}, "utf-7"},
+		{"gb18030-2000", utfbom.PythonCodec{Encoding: utfbom.GB18030}, "gb18030"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			codec, err := utfbom.ParsePythonCodec(tc.name)
+			be.Err(t, err, nil)
+			be.Equal(t, codec, tc.expected)
+			be.Equal(t, codec.String(), tc.canon)
+
+			// the canonical name parses back into the same codec
+			back, err := utfbom.ParsePythonCodec(codec.String())
+			be.Err(t, err, nil)
+			be.Equal(t, back, codec)
+		})
+	}
+}
+
+func TestParsePythonCodec_Unknown(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"latin-1", "scsu", "bocu-1", "utf-ebcdic", "unknown", ""} {
+		_, err := utfbom.ParsePythonCodec(name)
+		be.Err(t, err, utfbom.ErrUnknownEncoding)
+	}
+}
+
+func TestParseEncoding_PythonAliases(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]utfbom.Encoding{
+		"utf-8-sig": utfbom.UTF8,
+		"utf_16_le": utfbom.UTF16LittleEndian,
+		"utf-32-be": utfbom.UTF32BigEndian,
+	} {
+		enc, err := utfbom.ParseEncoding(name)
+		be.Err(t, err, nil)
+		be.Equal(t, enc, expected)
+	}
+
+	// the aliases only Python knows are not common names
+	for _, name := range []string{"U8", "utf", "cp65001", "U7", "unicode-1-1-utf-7", "gb18030-2000"} {
+		_, err := utfbom.ParseEncoding(name)
+		be.Err(t, err, utfbom.ErrUnknownEncoding)
+		be.Equal(t, utfbom.FromCharsetName(name), utfbom.Unknown)
+	}
+}
+
+func TestPythonCodec_String(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.PythonCodec{Encoding: utfbom.UTF16BigEndian, BOM: true}.String(), "")
+	be.Equal(t, utfbom.PythonCodec{Encoding: utfbom.SCSU}.String(), "")
+	be.Equal(t, utfbom.Unknown.PythonCodecName(), "")
+}
+
+func TestPythonCodec_Options(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		codec   string
+		written string
+		read    string
+	}{
+		{"utf-8-sig", "\ufeffa", "a"},
+		{"utf-8", "a", "\ufeffa"},
+		{"utf-16", "\xff\xfea", "a"},
+		{"utf-16-le", "a", "\xff\xfea"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.codec, func(t *testing.T) {
+			t.Parallel()
+
+			codec, err := utfbom.ParsePythonCodec(tc.codec)
+			be.Err(t, err, nil)
+
+			var buf bytes.Buffer
+
+			_, err = utfbom.NewWriter(&buf, codec.Encoding, codec.Options()...).Write([]byte("a"))
+			be.Err(t, err, nil)
+			be.Equal(t, buf.String(), tc.written)
+
+			// decoding keeps the BOM of codecs that don't use it, as Python does
+			out, err := io.ReadAll(utfbom.NewReader(strings.NewReader(string(codec.Encoding.Bytes())+"a"), codec.Options()...))
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.read)
+		})
+	}
+}
+
+func ExampleParsePythonCodec() {
+	// shared with a Python service calling open(path, "w", encoding="utf-8-sig")
+	codec, err := utfbom.ParsePythonCodec("utf-8-sig")
+	if err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+
+	w := utfbom.NewWriter(&buf, codec.Encoding, codec.Options()...)
+	_, _ = w.Write([]byte("a,b\n"))
+
+	fmt.Println(codec)
+	fmt.Printf("%q\n", buf.String())
+	// output:
+	// utf-8-sig
+	// "\ufeffa,b\n"
+}
@@ -0,0 +1,79 @@
+package utfbom
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// ReadAll reads from rd until EOF and returns the data without a leading Byte Order Mark (BOM),
+// along with the encoding of the removed BOM.
+// Read errors are returned as a ReadError together with the data read so far.
+//
+// Unlike trimming the result of io.ReadAll, ReadAll never copies the payload to get rid of the BOM,
+// only the few bytes read along with it are moved.
+// If rd reports its size, like *bytes.Reader, *strings.Reader or *os.File do,
+// the buffer is allocated once with the right capacity.
+func ReadAll(rd io.Reader) ([]byte, Encoding, error) {
+	// one byte more than the expected size, so reaching EOF does not grow the buffer
+	b := make([]byte, 0, max(sizeHint(rd)+1, 512))
+
+	n, err := io.ReadFull(rd, b[:maxBOMLen])
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, Unknown, newReadError("detect", int64(n), err)
+	}
+
+	enc := DetectEncoding(b[:n])
+	b = b[:copy(b[:n], b[enc.Len():n])]
+
+	if n < maxBOMLen {
+		// rd is exhausted
+		return b, enc, nil
+	}
+
+	for {
+		if len(b) == cap(b) {
+			b = append(b, 0)[:len(b)]
+		}
+
+		n, err := rd.Read(b[len(b):cap(b)])
+		b = b[:len(b)+n]
+
+		if errors.Is(err, io.EOF) {
+			return b, enc, nil
+		}
+
+		if err != nil {
+			return b, enc, newReadError("read", int64(enc.Len()+len(b)), err)
+		}
+	}
+}
+
+// sizeHint returns the number of bytes left in rd, if rd tells, or 0.
+func sizeHint(rd io.Reader) int {
+	switch v := rd.(type) {
+	case interface{ Len() int }:
+		return v.Len()
+	case interface{ Stat() (fs.FileInfo, error) }:
+		info, err := v.Stat()
+		if err != nil {
+			return 0
+		}
+
+		size := info.Size()
+
+		// the file may have been read from already
+		if s, ok := rd.(io.Seeker); ok {
+			off, err := s.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return 0
+			}
+
+			size -= off
+		}
+
+		return int(max(size, 0))
+	default:
+		return 0
+	}
+}
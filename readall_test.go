@@ -0,0 +1,116 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestReadAll(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("hello ", 1000)
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		enc      utfbom.Encoding
+	}{
+		{"empty", "", "", utfbom.Unknown},
+		{"short", "hi", "hi", utfbom.Unknown},
+		{"bom_only", "\xef\xbb\xbf", "", utfbom.UTF8},
+		{"utf8", "\xef\xbb\xbfhello", "hello", utfbom.UTF8},
+		{"utf16le", "\xff\xfeh\x00", "h\x00", utfbom.UTF16LittleEndian},
+		{"long_no_bom", long, long, utfbom.Unknown},
+		{"long_utf8", "\xef\xbb\xbf" + long, long, utfbom.UTF8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, src := range []io.Reader{
+				strings.NewReader(tc.input),
+				iotest.OneByteReader(strings.NewReader(tc.input)),
+				bytes.NewBufferString(tc.input),
+			} {
+				out, enc, err := utfbom.ReadAll(src)
+				be.Err(t, err, nil)
+				be.Equal(t, string(out), tc.expected)
+				be.Equal(t, enc, tc.enc)
+				be.True(t, out != nil)
+			}
+		})
+	}
+}
+
+func TestReadAll_File(t *testing.T) {
+	t.Parallel()
+
+	payload := strings.Repeat("hello ", 1000)
+	name := filepath.Join(t.TempDir(), "data.txt")
+	be.Err(t, os.WriteFile(name, []byte("\xef\xbb\xbf"+payload), 0o600), nil)
+
+	f, err := os.Open(name)
+	be.Err(t, err, nil)
+
+	defer f.Close()
+
+	out, enc, err := utfbom.ReadAll(f)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), payload)
+	be.Equal(t, enc, utfbom.UTF8)
+	be.Equal(t, cap(out), len(payload)+4)
+
+	// the hint leaves out the part of the file already read
+	_, err = f.Seek(int64(3+len(payload)/2), io.SeekStart)
+	be.Err(t, err, nil)
+
+	out, enc, err = utfbom.ReadAll(f)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), payload[len(payload)/2:])
+	be.Equal(t, enc, utfbom.Unknown)
+	be.Equal(t, cap(out), len(payload)/2+1)
+}
+
+func TestReadAll_Errors(t *testing.T) {
+	t.Parallel()
+
+	errDisk := errors.New("disk failure")
+
+	_, enc, err := utfbom.ReadAll(iotest.ErrReader(errDisk))
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.True(t, errors.Is(err, errDisk))
+	be.Equal(t, enc, utfbom.Unknown)
+
+	out, enc, err := utfbom.ReadAll(io.MultiReader(strings.NewReader("\xef\xbb\xbfh"), iotest.ErrReader(errDisk)))
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.True(t, errors.Is(err, errDisk))
+	be.Equal(t, enc, utfbom.UTF8)
+	be.Equal(t, string(out), "h")
+}
+
+func TestReadAll_Allocations(t *testing.T) {
+	input := []byte("\xef\xbb\xbf" + strings.Repeat("hello ", 1000))
+	src := bytes.NewReader(nil)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		src.Reset(input)
+
+		_, _, err := utfbom.ReadAll(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	be.Equal(t, allocs, 1.0)
+}
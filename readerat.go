@@ -0,0 +1,44 @@
+package utfbom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var _ io.ReaderAt = (*ReaderAt)(nil)
+
+// ReaderAt implements BOM (Unicode Byte Order Mark) removing for an io.ReaderAt object:
+// all offsets are shifted past the BOM, so offset 0 is the first payload byte.
+//
+// ReaderAt is safe for concurrent use if the wrapped reader is.
+type ReaderAt struct {
+	ra   io.ReaderAt
+	skip int64
+}
+
+// NewReaderAt detects the Byte Order Mark (BOM) at the beginning of ra once
+// and returns a ReaderAt serving the payload that follows it, along with the detected encoding.
+// It suits random-access parsing of BOM-prefixed files, such as zip archives or column stores.
+func NewReaderAt(ra io.ReaderAt) (*ReaderAt, Encoding, error) {
+	var buf [maxBOMLen]byte
+
+	n, err := ra.ReadAt(buf[:], 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, Unknown, newReadError("detect", int64(n), err)
+	}
+
+	enc := DetectEncoding(buf[:n])
+
+	return &ReaderAt{ra: ra, skip: int64(enc.Len())}, enc, nil
+}
+
+// ReadAt implements the io.ReaderAt interface, off is relative to the end of the BOM.
+// A negative off fails with ErrNegativeOffset.
+func (r *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, off)
+	}
+
+	return r.ra.ReadAt(p, off+r.skip)
+}
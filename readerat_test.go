@@ -0,0 +1,99 @@
+package utfbom_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestReaderAt(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		enc     utfbom.Encoding
+		payload string
+	}{
+		{"empty", "", utfbom.Unknown, ""},
+		{"no_bom", "hello", utfbom.Unknown, "hello"},
+		{"bom_only", "\xef\xbb\xbf", utfbom.UTF8, ""},
+		{"utf8", "\xef\xbb\xbfhello", utfbom.UTF8, "hello"},
+		{"utf16be", "\xfe\xff\x00h", utfbom.UTF16BigEndian, "\x00h"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ra, enc, err := utfbom.NewReaderAt(strings.NewReader(tc.input))
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.enc)
+
+			out, err := io.ReadAll(io.NewSectionReader(ra, 0, int64(len(tc.payload))))
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.payload)
+
+			buf := make([]byte, 1)
+
+			if len(tc.payload) > 1 {
+				n, err := ra.ReadAt(buf, 1)
+				be.Err(t, err, nil)
+				be.Equal(t, string(buf[:n]), tc.payload[1:2])
+			}
+
+			_, err = ra.ReadAt(buf, int64(len(tc.payload)))
+			be.Err(t, err, io.EOF)
+
+			_, err = ra.ReadAt(buf, -1)
+			be.Err(t, err, utfbom.ErrNegativeOffset)
+		})
+	}
+}
+
+func TestReaderAt_Error(t *testing.T) {
+	t.Parallel()
+
+	_, enc, err := utfbom.NewReaderAt(errReaderAt{errors.New("disk failure")})
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.Equal(t, enc, utfbom.Unknown)
+}
+
+type errReaderAt struct {
+	err error
+}
+
+func (r errReaderAt) ReadAt([]byte, int64) (int, error) {
+	return 0, r.err
+}
+
+func TestReaderAt_Zip(t *testing.T) {
+	t.Parallel()
+
+	var archive bytes.Buffer
+
+	zw := zip.NewWriter(&archive)
+
+	w, err := zw.Create("hello.txt")
+	be.Err(t, err, nil)
+
+	_, err = w.Write([]byte("hello"))
+	be.Err(t, err, nil)
+	be.Err(t, zw.Close(), nil)
+
+	input := append([]byte("\xef\xbb\xbf"), archive.Bytes()...)
+
+	ra, enc, err := utfbom.NewReaderAt(bytes.NewReader(input))
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF8)
+
+	zr, err := zip.NewReader(ra, int64(len(input)-enc.Len()))
+	be.Err(t, err, nil)
+	be.Equal(t, zr.File[0].Name, "hello.txt")
+}
@@ -0,0 +1,133 @@
+package utfbom
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// signature is a custom leading signature added with Register.
+type signature struct {
+	bom  string
+	name string
+}
+
+// registry is an immutable snapshot of the registered signatures,
+// replaced as a whole on every Register call.
+type registry struct {
+	order []Encoding // detection order across built-in and registered encodings, longest BOM first
+	sigs  map[Encoding]signature
+	lead  [256]bool // first bytes of all BOMs, to reject most inputs without a BOM at once
+}
+
+var (
+	registryMu sync.Mutex // serializes Register calls
+	registered atomic.Pointer[registry]
+	builtins   = newRegistry(builtinOrder, nil)
+)
+
+func newRegistry(order []Encoding, sigs map[Encoding]signature) *registry {
+	r := &registry{order: order, sigs: sigs}
+
+	for _, e := range order {
+		if sig, ok := sigs[e]; ok {
+			r.lead[sig.bom[0]] = true
+		} else {
+			r.lead[builtinBOM(e)[0]] = true
+		}
+	}
+
+	return r
+}
+
+// Register teaches the package about a custom leading signature sig identified by enc and name.
+// Registered signatures are detected by DetectEncoding, reported by String, Len and Bytes,
+// and removed by Trim, Reader and the other BOM consumers the same way as built-in BOMs.
+// Detection matches the longest signature first across built-in and registered entries.
+//
+// To avoid clashes with encodings added to the package later, enc should be chosen
+// well above the built-in ones, for example starting at 1000.
+// Payloads of registered encodings are not decoded by UTF8Reader.
+//
+// Register is meant to be called from init functions and is safe for concurrent use.
+// It panics if sig is empty or longer than 4 bytes, if enc is Unknown, built-in or already registered,
+// if name is empty, or if sig or name is already in use, sig being in use if it equals any signature
+// of a built-in or registered encoding, such as any of the four UTF-7 ones, which would shadow it.
+func Register(sig []byte, enc Encoding, name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if len(sig) == 0 || len(sig) > maxBOMLen {
+		panic(fmt.Sprintf("utfbom: Register signature must be 1 to %d bytes long, got %d", maxBOMLen, len(sig)))
+	}
+
+	if name == "" {
+		panic("utfbom: Register name must not be empty")
+	}
+
+	r := current()
+	order := r.order
+	sigs := maps.Clone(r.sigs)
+
+	if sigs == nil {
+		sigs = map[Encoding]signature{}
+	}
+
+	if enc == Unknown || slices.Contains(order, enc) {
+		panic(fmt.Sprintf("utfbom: Register encoding %d is already in use", enc))
+	}
+
+	for _, e := range order {
+		// an equal signature is tried first and takes over every input starting with sig,
+		// which holds for any of the UTF-7 signatures as well
+		if len(sig) == e.Len() && hasBOM(sig, e) {
+			panic(fmt.Sprintf("utfbom: Register signature %#x is already in use by %s", sig, e))
+		}
+
+		if e.String() == name {
+			panic(fmt.Sprintf("utfbom: Register name %q is already in use", name))
+		}
+	}
+
+	sigs[enc] = signature{bom: string(sig), name: name}
+
+	order = append(slices.Clone(order), enc)
+	slices.SortStableFunc(order, func(a, b Encoding) int {
+		return cmp.Compare(sigLen(b, sigs), sigLen(a, sigs))
+	})
+
+	registered.Store(newRegistry(order, sigs))
+}
+
+// sigLen returns the BOM length of a built-in encoding or of a signature from sigs.
+func sigLen(e Encoding, sigs map[Encoding]signature) int {
+	if sig, ok := sigs[e]; ok {
+		return len(sig.bom)
+	}
+
+	return e.Len()
+}
+
+// current returns the registry in use, the built-in one until Register is called.
+func current() *registry {
+	if r := registered.Load(); r != nil {
+		return r
+	}
+
+	return builtins
+}
+
+// detectionOrder returns the encodings having a BOM, longer BOMs first, see builtinOrder.
+func detectionOrder() []Encoding {
+	return current().order
+}
+
+// lookup returns the registered signature of e.
+func lookup(e Encoding) (signature, bool) {
+	sig, ok := current().sigs[e]
+
+	return sig, ok
+}
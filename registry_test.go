@@ -0,0 +1,118 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+// Registration is global, so every test registers its own encodings and signatures.
+const (
+	encTestSig utfbom.Encoding = 1000 + iota
+	encTestLonger
+	encTestTaken
+	encExample
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	sig := []byte{0x1b, 'T', 'S'}
+	utfbom.Register(sig, encTestSig, "TestSig")
+
+	sig[0] = 0x00 // the registry keeps its own copy
+
+	input := []byte{0x1b, 'T', 'S', 'h', 'i'}
+
+	be.Equal(t, utfbom.DetectEncoding(input), encTestSig)
+	be.Equal(t, encTestSig.String(), "TestSig")
+	be.Equal(t, encTestSig.Len(), 3)
+	be.Equal(t, encTestSig.Bytes(), []byte{0x1b, 'T', 'S'})
+
+	out, enc := utfbom.Trim(input)
+	be.Equal(t, enc, encTestSig)
+	be.Equal(t, out, []byte("hi"))
+
+	rd := utfbom.NewReader(bytes.NewReader(input))
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, out, []byte("hi"))
+	be.Equal(t, rd.Enc, encTestSig)
+
+	_, err = io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(input)))
+	be.Err(t, err, utfbom.ErrUnsupportedEncoding)
+}
+
+func TestRegister_LongestSignatureWins(t *testing.T) {
+	t.Parallel()
+
+	// starts with the UTF-16 Big Endian BOM
+	utfbom.Register([]byte{0xfe, 0xff, 0x1b, 0x1b}, encTestLonger, "TestLonger")
+
+	be.Equal(t, utfbom.DetectEncoding([]byte{0xfe, 0xff, 0x1b, 0x1b, 'h'}), encTestLonger)
+	be.Equal(t, utfbom.DetectEncoding([]byte{0xfe, 0xff, 0x00, 'h'}), utfbom.UTF16BigEndian)
+}
+
+func TestRegister_Panics(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		sig  []byte
+		enc  utfbom.Encoding
+		str  string
+	}{
+		{"empty_signature", nil, 2000, "Empty"},
+		{"long_signature", []byte("12345"), 2001, "Long"},
+		{"empty_name", []byte{0x1b, 0x1b, 0x1b}, 2002, ""},
+		{"unknown_encoding", []byte{0x1b, 0x1b, 0x1c}, utfbom.Unknown, "Zero"},
+		{"builtin_encoding", []byte{0x1b, 0x1b, 0x1d}, utfbom.UTF8, "Builtin"},
+		{"builtin_signature", utf8BOM, 2003, "Duplicate"},
+		{"builtin_alternative_signature", []byte("+/v9"), 2005, "UTF7Alternative"},
+		{"extended_signature", []byte{0x84, 0x31, 0x95, 0x33}, 2006, "GB18030Duplicate"},
+		{"builtin_name", []byte{0x1b, 0x1b, 0x1e}, 2004, "UTF8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				be.True(t, recover() != nil)
+			}()
+
+			utfbom.Register(tc.sig, tc.enc, tc.str)
+		})
+	}
+}
+
+func TestRegister_SignatureInUse(t *testing.T) {
+	t.Parallel()
+
+	utfbom.Register([]byte{0x1b, 'T', 'T'}, encTestTaken, "TestTaken")
+
+	defer func() {
+		be.True(t, recover() != nil)
+		be.Equal(t, utfbom.DetectEncoding("\x1bTThi"), encTestTaken)
+	}()
+
+	utfbom.Register([]byte{0x1b, 'T', 'T'}, 2010, "TestShadowed")
+}
+
+func ExampleRegister() {
+	// a proprietary export format starts every file with "\x1bXP"
+	utfbom.Register([]byte{0x1b, 'X', 'P'}, encExample, "ExportFormat")
+
+	out, enc := utfbom.Trim("\x1bXPpayload")
+
+	fmt.Println("detected encoding:", enc)
+	fmt.Println(out)
+
+	// output:
+	// detected encoding: ExportFormat
+	// payload
+}
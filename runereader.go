@@ -0,0 +1,50 @@
+package utfbom
+
+import (
+	"bufio"
+	"io"
+)
+
+var _ io.RuneScanner = (*RuneReader)(nil)
+
+// RuneReader reads the payload of a stream rune by rune regardless of whether it is
+// UTF-8, UTF-16 or UTF-32 encoded, using the BOM to pick the decoder.
+// Payloads are decoded the same way as by UTF8Reader: payloads without a BOM are taken as UTF-8,
+// invalid sequences are read as utf8.RuneError (U+FFFD)
+// and payloads of other encodings fail with ErrUnsupportedEncoding.
+//
+// RuneReader is not safe for concurrent use.
+type RuneReader struct {
+	rd  *UTF8Reader
+	buf *bufio.Reader
+}
+
+// NewRuneReader wraps an incoming reader.
+// Options are applied the same way as for UTF8Reader.
+// Passing a nil reader will cause a panic on the first ReadRune call.
+func NewRuneReader(rd io.Reader, opts ...Option) *RuneReader {
+	u := NewUTF8Reader(rd, opts...)
+
+	return &RuneReader{
+		rd:  u,
+		buf: bufio.NewReader(u),
+	}
+}
+
+// ReadRune implements the io.RuneReader interface.
+// The returned size is the length of the rune encoded in UTF-8,
+// not the number of bytes it takes in the underlying stream.
+func (r *RuneReader) ReadRune() (rune, int, error) {
+	return r.buf.ReadRune()
+}
+
+// UnreadRune implements the io.RuneScanner interface, see bufio.Reader.UnreadRune.
+func (r *RuneReader) UnreadRune() error {
+	return r.buf.UnreadRune()
+}
+
+// Encoding detects and removes any Byte Order Mark (BOM) unless that is already done
+// and returns the detected encoding, see Reader.Encoding.
+func (r *RuneReader) Encoding() (Encoding, error) {
+	return r.rd.rd.Encoding()
+}
@@ -0,0 +1,130 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+	"unicode/utf8"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestRuneReader(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.Unknown,
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			rd := utfbom.NewRuneReader(iotest.OneByteReader(bytes.NewReader(encode(enc, multilingual))))
+
+			var got []rune
+
+			for {
+				r, size, err := rd.ReadRune()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				be.Err(t, err, nil)
+				be.Equal(t, size, utf8.RuneLen(r))
+
+				got = append(got, r)
+			}
+
+			be.Equal(t, string(got), multilingual)
+
+			detected, err := rd.Encoding()
+			be.Err(t, err, nil)
+			be.Equal(t, detected, enc)
+		})
+	}
+}
+
+func TestRuneReader_UnreadRune(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewRuneReader(bytes.NewReader(encode(utfbom.UTF16LittleEndian, "🙂x")))
+
+	r, _, err := rd.ReadRune()
+	be.Err(t, err, nil)
+	be.Equal(t, r, '🙂')
+
+	be.Err(t, rd.UnreadRune(), nil)
+
+	r, size, err := rd.ReadRune()
+	be.Err(t, err, nil)
+	be.Equal(t, r, '🙂')
+	be.Equal(t, size, 4)
+}
+
+func TestRuneReader_Invalid(t *testing.T) {
+	t.Parallel()
+
+	// unpaired high surrogate followed by 'x'
+	input := append(utfbom.UTF16BigEndian.Bytes(), 0xd8, 0x00, 0x00, 'x')
+	rd := utfbom.NewRuneReader(bytes.NewReader(input))
+
+	r, _, err := rd.ReadRune()
+	be.Err(t, err, nil)
+	be.Equal(t, r, utf8.RuneError)
+
+	r, _, err = rd.ReadRune()
+	be.Err(t, err, nil)
+	be.Equal(t, r, 'x')
+
+	_, _, err = rd.ReadRune()
+	be.Err(t, err, io.EOF)
+}
+
+func TestRuneReader_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewRuneReader(bytes.NewReader(append(utfbom.SCSU.Bytes(), 'x')), utfbom.WithExtendedBOMs())
+
+	_, _, err := rd.ReadRune()
+	be.Err(t, err, utfbom.ErrUnsupportedEncoding)
+
+	enc, err := rd.Encoding()
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.SCSU)
+}
+
+func ExampleNewRuneReader() {
+	// "héj" in UTF-32 Big Endian with a BOM
+	input := []byte{
+		0x00, 0x00, 0xfe, 0xff,
+		0x00, 0x00, 0x00, 'h',
+		0x00, 0x00, 0x00, 0xe9,
+		0x00, 0x00, 0x00, 'j',
+	}
+
+	rd := utfbom.NewRuneReader(bytes.NewReader(input))
+
+	for {
+		r, _, err := rd.ReadRune()
+		if err != nil {
+			break
+		}
+
+		fmt.Printf("%q ", r)
+	}
+
+	fmt.Println()
+
+	// output:
+	// 'h' 'é' 'j'
+}
@@ -0,0 +1,207 @@
+package utfbom
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"runtime"
+	"sync"
+)
+
+// FileReport describes the BOM found at the beginning of a file.
+type FileReport struct {
+	// Path is the slash-separated path of the file within the scanned file system.
+	Path string
+	// Encoding is the encoding of the detected BOM, Unknown if there is none.
+	Encoding Encoding
+	// Size is the file size in bytes.
+	Size int64
+	// Offset is the byte offset at which the BOM starts. It is always 0 for now,
+	// as only the beginning of files is looked at, and is kept for reports pointing past it.
+	Offset int64
+}
+
+// ScanOption configures ScanDir.
+type ScanOption func(*scanConfig)
+
+type scanConfig struct {
+	include     []string
+	exclude     []string
+	concurrency int
+	stats       *Stats
+}
+
+// ScanInclude limits scanning to files whose path or base name matches any of the patterns.
+// Patterns use path.Match syntax.
+func ScanInclude(patterns ...string) ScanOption {
+	return func(c *scanConfig) {
+		c.include = append(c.include, patterns...)
+	}
+}
+
+// ScanExclude skips files and directories whose path or base name matches any of the patterns.
+// Patterns use path.Match syntax.
+func ScanExclude(patterns ...string) ScanOption {
+	return func(c *scanConfig) {
+		c.exclude = append(c.exclude, patterns...)
+	}
+}
+
+// ScanConcurrency sets the maximum number of files inspected at once.
+// Values below 1 are ignored, the default is runtime.GOMAXPROCS(0).
+// Files are inspected as the tree is walked, so large trees don't wait for the walk to finish.
+func ScanConcurrency(n int) ScanOption {
+	return func(c *scanConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+func newScanConfig(opts []ScanOption) scanConfig {
+	cfg := scanConfig{
+		concurrency: runtime.GOMAXPROCS(0),
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// ScanDir walks the file system tree and detects the BOM of every regular file.
+// Reports are returned in lexical path order.
+// Files and directories that fail to be read are left out and the walk goes on,
+// their errors are joined into the returned error.
+func ScanDir(fsys fs.FS, opts ...ScanOption) ([]FileReport, error) {
+	cfg := newScanConfig(opts)
+
+	// files are inspected while the tree is still being walked,
+	// each into its own result so that the order of the walk is kept
+	type result struct {
+		report FileReport
+		err    error
+	}
+
+	var (
+		results []*result
+		wg      sync.WaitGroup
+	)
+
+	sem := make(chan struct{}, cfg.concurrency)
+
+	// the walk function never fails, so that an unreadable directory doesn't end the walk,
+	// its error is collected in walk order along with the errors of the files
+	_ = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			results = append(results, &result{err: err})
+
+			return nil
+		}
+
+		if p != "." && matchAny(cfg.exclude, p) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if cfg.included(p) {
+			res := &result{}
+			results = append(results, res)
+			sem <- struct{}{}
+
+			wg.Go(func() {
+				defer func() { <-sem }()
+
+				res.report, res.err = detectFS(fsys, p)
+			})
+		}
+
+		return nil
+	})
+
+	wg.Wait()
+
+	reports := make([]FileReport, 0, len(results))
+
+	var errs []error
+
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, res.err)
+		} else {
+			reports = append(reports, res.report)
+		}
+	}
+
+	cfg.addStats(reports)
+
+	return reports, errors.Join(errs...)
+}
+
+// included reports whether the file at p is to be inspected according to the include patterns.
+func (c *scanConfig) included(p string) bool {
+	return len(c.include) == 0 || matchAny(c.include, p)
+}
+
+// addStats adds reports to the statistics set with ScanStats, if any.
+func (c *scanConfig) addStats(reports []FileReport) {
+	if c.stats == nil {
+		return
+	}
+
+	for _, r := range reports {
+		c.stats.Add(r)
+	}
+}
+
+func detectFS(fsys fs.FS, p string) (FileReport, error) {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return FileReport{}, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return FileReport{}, err
+	}
+
+	var buf [maxBOMLen]byte
+
+	n, err := io.ReadFull(f, buf[:])
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return FileReport{}, &fs.PathError{Op: "read", Path: p, Err: err}
+	}
+
+	return FileReport{
+		Path:     p,
+		Encoding: DetectEncoding(buf[:n]),
+		Size:     fi.Size(),
+		Offset:   0,
+	}, nil
+}
+
+// matchAny reports whether p or its base name matches any of the patterns.
+// Malformed patterns never match.
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, path.Base(p)); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,176 @@
+package utfbom_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"a.csv":              {Data: append(utf8BOM, "a,b"...)},
+		"b.txt":              {Data: []byte("plain")},
+		"empty.txt":          {Data: nil},
+		"docs/c.txt":         {Data: append(utf16LEBOM, 'c', 0x00)},
+		"docs/d.csv":         {Data: append(utf32BEBOM, 0x00, 0x00, 0x00, 'd')},
+		"vendor/e.csv":       {Data: append(utf8BOM, 'e')},
+		"vendor/deep/f.txt":  {Data: append(utf16BEBOM, 0x00, 'f')},
+		"docs/link":          {Data: []byte("b.txt"), Mode: fs.ModeSymlink},
+		"docs/nested/g.text": {Data: []byte("g")},
+	}
+}
+
+func TestScanDir(t *testing.T) {
+	t.Parallel()
+
+	reports, err := utfbom.ScanDir(testFS())
+	be.Err(t, err, nil)
+	be.Equal(t, reports, []utfbom.FileReport{
+		{Path: "a.csv", Encoding: utfbom.UTF8, Size: 6},
+		{Path: "b.txt", Encoding: utfbom.Unknown, Size: 5},
+		{Path: "docs/c.txt", Encoding: utfbom.UTF16LittleEndian, Size: 4},
+		{Path: "docs/d.csv", Encoding: utfbom.UTF32BigEndian, Size: 8},
+		{Path: "docs/nested/g.text", Encoding: utfbom.Unknown, Size: 1},
+		{Path: "empty.txt", Encoding: utfbom.Unknown, Size: 0},
+		{Path: "vendor/deep/f.txt", Encoding: utfbom.UTF16BigEndian, Size: 4},
+		{Path: "vendor/e.csv", Encoding: utfbom.UTF8, Size: 4},
+	})
+}
+
+func TestScanDir_Options(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []utfbom.ScanOption
+		expected []string
+	}{
+		{
+			name:     "include_base_name",
+			opts:     []utfbom.ScanOption{utfbom.ScanInclude("*.csv")},
+			expected: []string{"a.csv", "docs/d.csv", "vendor/e.csv"},
+		},
+		{
+			name:     "include_path",
+			opts:     []utfbom.ScanOption{utfbom.ScanInclude("docs/*")},
+			expected: []string{"docs/c.txt", "docs/d.csv"},
+		},
+		{
+			name:     "exclude_directory",
+			opts:     []utfbom.ScanOption{utfbom.ScanExclude("vendor", "nested")},
+			expected: []string{"a.csv", "b.txt", "docs/c.txt", "docs/d.csv", "empty.txt"},
+		},
+		{
+			name:     "include_and_exclude",
+			opts:     []utfbom.ScanOption{utfbom.ScanInclude("*.txt"), utfbom.ScanExclude("vendor", "empty.txt")},
+			expected: []string{"b.txt", "docs/c.txt"},
+		},
+		{
+			name:     "sequential",
+			opts:     []utfbom.ScanOption{utfbom.ScanInclude("*.csv"), utfbom.ScanConcurrency(1)},
+			expected: []string{"a.csv", "docs/d.csv", "vendor/e.csv"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reports, err := utfbom.ScanDir(testFS(), tc.opts...)
+			be.Err(t, err, nil)
+
+			paths := make([]string, 0, len(reports))
+			for _, r := range reports {
+				paths = append(paths, r.Path)
+			}
+
+			be.Equal(t, paths, tc.expected)
+		})
+	}
+}
+
+// brokenFS fails to open the file named bad and to read the directory named baddir.
+type brokenFS struct {
+	fstest.MapFS
+}
+
+func (b brokenFS) Open(name string) (fs.File, error) {
+	if name == "bad" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+
+	return b.MapFS.Open(name)
+}
+
+func (b brokenFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == "baddir" {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+
+	return b.MapFS.ReadDir(name)
+}
+
+func TestScanDir_FileErrors(t *testing.T) {
+	t.Parallel()
+
+	fsys := brokenFS{fstest.MapFS{
+		"bad":  {Data: utf8BOM},
+		"good": {Data: utf8BOM},
+	}}
+
+	reports, err := utfbom.ScanDir(fsys)
+	be.True(t, errors.Is(err, fs.ErrPermission))
+	be.Equal(t, reports, []utfbom.FileReport{{Path: "good", Encoding: utfbom.UTF8, Size: 3}})
+}
+
+func TestScanDir_WalkErrors(t *testing.T) {
+	t.Parallel()
+
+	fsys := brokenFS{fstest.MapFS{
+		"a":          {Data: utf8BOM},
+		"baddir/x":   {Data: utf8BOM},
+		"bad":        {Data: utf8BOM},
+		"z/good.txt": {Data: []byte("x")},
+	}}
+
+	reports, err := utfbom.ScanDir(fsys)
+	be.Err(t, err, fs.ErrPermission)
+	be.Err(t, err, "readdir baddir")
+	be.Err(t, err, "open bad")
+	be.Equal(t, reports, []utfbom.FileReport{
+		{Path: "a", Encoding: utfbom.UTF8, Size: 3},
+		{Path: "z/good.txt", Encoding: utfbom.Unknown, Size: 1},
+	})
+}
+
+func TestScanDir_Order(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{}
+
+	var expected []string
+
+	for i := range 200 {
+		p := fmt.Sprintf("dir%d/file%03d", i%7, i)
+		fsys[p] = &fstest.MapFile{Data: utf8BOM}
+		expected = append(expected, p)
+	}
+
+	slices.Sort(expected)
+
+	reports, err := utfbom.ScanDir(fsys, utfbom.ScanConcurrency(8))
+	be.Err(t, err, nil)
+
+	paths := make([]string, 0, len(reports))
+	for _, r := range reports {
+		paths = append(paths, r.Path)
+	}
+
+	be.Equal(t, paths, expected)
+}
@@ -0,0 +1,84 @@
+package utfbom
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// Scanner is a bufio.Scanner reading through a UTF8Reader,
+// so its first token never contains a Byte Order Mark (BOM)
+// and UTF-16 and UTF-32 payloads are scanned as UTF-8.
+//
+// Scanner is not safe for concurrent use.
+type Scanner struct {
+	*bufio.Scanner
+	rd *UTF8Reader
+}
+
+// NewScanner returns a Scanner splitting rd into lines, same as bufio.NewScanner.
+// The split function and buffer can be changed with the embedded bufio.Scanner methods.
+// Options are applied the same way as for UTF8Reader; detection errors are reported by Err.
+// Passing a nil reader will cause a panic on the first Scan call.
+func NewScanner(rd io.Reader, opts ...Option) *Scanner {
+	urd := NewUTF8Reader(rd, opts...)
+
+	return &Scanner{
+		Scanner: bufio.NewScanner(urd),
+		rd:      urd,
+	}
+}
+
+// Encoding returns the detected encoding.
+// It is Unknown until the first Scan call.
+func (s *Scanner) Encoding() Encoding {
+	return s.rd.Enc
+}
+
+// ScanLines is a bufio.SplitFunc like bufio.ScanLines that also removes
+// a UTF-8 Byte Order Mark (BOM) from the beginning of lines.
+// Being stateless, it removes the BOM from any line, not just the first one,
+// which also takes care of concatenated files.
+// Other signatures are left alone, as ordinary lines may start with their bytes, such as base64 lines with "+/v8".
+// Use TrimFirstToken(bufio.ScanLines) to trim the first line only, whatever its BOM.
+func ScanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	token = bytes.TrimPrefix(token, []byte(zwnbsp))
+
+	return advance, token, err
+}
+
+// TrimFirstToken wraps split so that a Byte Order Mark (BOM) at the beginning
+// of the input is removed before the first token is split off.
+// Only the BOM bytes are removed, UTF-16 and UTF-32 payloads are not decoded; use NewScanner for those.
+//
+// The returned function keeps state and must not be shared between scanners.
+func TrimFirstToken(split bufio.SplitFunc) bufio.SplitFunc {
+	done := false
+
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if done {
+			return split(data, atEOF)
+		}
+
+		if !atEOF && isPartialBOM(data) {
+			return 0, nil, nil
+		}
+
+		n := DetectEncoding(data).Len()
+		if n == len(data) && !atEOF {
+			// split functions expect data unless at EOF
+			return 0, nil, nil
+		}
+
+		advance, token, err := split(data[n:], atEOF)
+		if advance == 0 && token == nil && err == nil {
+			// more data is requested, the BOM is removed again on the next call
+			return 0, nil, nil
+		}
+
+		done = true
+
+		return n + advance, token, err
+	}
+}
@@ -0,0 +1,168 @@
+package utfbom_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestScanner(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.Unknown,
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			sc := utfbom.NewScanner(iotest.OneByteReader(bytes.NewReader(encode(enc, "first\nsecond\r\nthird"))))
+			be.Equal(t, sc.Encoding(), utfbom.Unknown)
+
+			var lines []string
+			for sc.Scan() {
+				lines = append(lines, sc.Text())
+			}
+
+			be.Err(t, sc.Err(), nil)
+			be.Equal(t, lines, []string{"first", "second", "third"})
+			be.Equal(t, sc.Encoding(), enc)
+		})
+	}
+}
+
+func TestScanner_Split(t *testing.T) {
+	t.Parallel()
+
+	sc := utfbom.NewScanner(strings.NewReader("\ufeffhello world"))
+	sc.Split(bufio.ScanWords)
+
+	be.True(t, sc.Scan())
+	be.Equal(t, sc.Text(), "hello")
+	be.True(t, sc.Scan())
+	be.Equal(t, sc.Text(), "world")
+	be.True(t, !sc.Scan())
+}
+
+func TestScanner_DetectionError(t *testing.T) {
+	t.Parallel()
+
+	sc := utfbom.NewScanner(strings.NewReader("\ufeffhello"), utfbom.Forbid())
+
+	be.True(t, !sc.Scan())
+	be.Err(t, sc.Err(), utfbom.ErrBOMForbidden)
+}
+
+func TestScanLines(t *testing.T) {
+	t.Parallel()
+
+	sc := bufio.NewScanner(iotest.OneByteReader(strings.NewReader("\ufeffone\ntwo\n\ufeffthree")))
+	sc.Split(utfbom.ScanLines)
+
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+
+	be.Err(t, sc.Err(), nil)
+	be.Equal(t, lines, []string{"one", "two", "three"})
+}
+
+func TestScanLines_OtherSignatures(t *testing.T) {
+	t.Parallel()
+
+	// base64 lines starting with the bytes of the UTF-7 signature, or of the UTF-16 BOM, are kept whole
+	input := "aGVsbG8=\n+/v8AAAA\n\xff\xfeab\n\ufeff+/v9"
+
+	sc := bufio.NewScanner(strings.NewReader(input))
+	sc.Split(utfbom.ScanLines)
+
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+
+	be.Err(t, sc.Err(), nil)
+	be.Equal(t, lines, []string{"aGVsbG8=", "+/v8AAAA", "\xff\xfeab", "+/v9"})
+}
+
+func TestTrimFirstToken(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		split    bufio.SplitFunc
+		expected []string
+	}{
+		{"empty", "", bufio.ScanLines, nil},
+		{"only_bom", "\ufeff", bufio.ScanLines, nil},
+		{"no_bom", "one\ntwo", bufio.ScanLines, []string{"one", "two"}},
+		{"lines", "\ufeffone\n\ufefftwo", bufio.ScanLines, []string{"one", "\ufefftwo"}},
+		{"words", "\ufeff  one two", bufio.ScanWords, []string{"one", "two"}},
+		{"runes", "\ufeffab", bufio.ScanRunes, []string{"a", "b"}},
+		{"incomplete_bom", "\xef\xbb", bufio.ScanBytes, []string{"\xef", "\xbb"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sc := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(tc.input)))
+			sc.Split(utfbom.TrimFirstToken(tc.split))
+
+			var tokens []string
+			for sc.Scan() {
+				tokens = append(tokens, sc.Text())
+			}
+
+			be.Err(t, sc.Err(), nil)
+			be.Equal(t, tokens, tc.expected)
+		})
+	}
+}
+
+func ExampleTrimFirstToken() {
+	sc := bufio.NewScanner(strings.NewReader("\ufeffhello world"))
+	sc.Split(utfbom.TrimFirstToken(bufio.ScanWords))
+
+	for sc.Scan() {
+		fmt.Printf("%q\n", sc.Text())
+	}
+
+	// output:
+	// "hello"
+	// "world"
+}
+
+func ExampleNewScanner() {
+	sc := utfbom.NewScanner(strings.NewReader("\ufeffName,City\nJürgen,Köln\n"))
+
+	for sc.Scan() {
+		fmt.Printf("%q\n", sc.Text())
+	}
+
+	err := sc.Err()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("detected encoding:", sc.Encoding())
+
+	// output:
+	// "Name,City"
+	// "Jürgen,Köln"
+	// detected encoding: UTF8
+}
@@ -0,0 +1,87 @@
+package utfbom
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// defaultSniffLen is the number of payload bytes peeked at by Sniffer by default,
+// the amount http.DetectContentType considers.
+const defaultSniffLen = 512
+
+// ContentMatcher names the content type of the beginning of a payload, such as "application/json",
+// or returns the empty string if it doesn't recognize it. The prefix never contains the BOM,
+// enc is the encoding of the BOM, Unknown if there is none, so UTF-16 payloads can be told apart.
+type ContentMatcher func(prefix []byte, enc Encoding) string
+
+// SniffResult is what Sniffer found at the beginning of a stream.
+type SniffResult struct {
+	// Encoding is the encoding of the BOM, Unknown if there is none.
+	Encoding Encoding
+	// ContentType is the name returned by the content matcher, empty if it recognized nothing.
+	ContentType string
+	// Prefix holds the payload bytes peeked at, without the BOM.
+	// It is shorter than the sniffer size if the stream is,
+	// and it is only valid until the next read of the Reader returned along with it.
+	Prefix []byte
+}
+
+// Sniffer peeks at the beginning of a stream once to report both its BOM and its content type,
+// as told by a caller-supplied matcher, without reading the stream twice or buffering it twice.
+// The zero value reports the BOM only.
+type Sniffer struct {
+	// Size is the number of payload bytes peeked at, 512 if zero.
+	// It is capped at 4096 bytes if the options filter the payload, see ScrubInterior and WithValidateUTF8.
+	Size int
+	// Match names the content type of the peeked bytes, it may be nil.
+	Match ContentMatcher
+	// Options configure the returned Reader the same way as for NewReader,
+	// so its BOM policy tells whether the BOM is removed or kept.
+	Options []Option
+}
+
+// Sniff detects the BOM of rd, peeks at the payload following it and matches its content type.
+// It returns a Reader positioned at the beginning of the stream, or right after the BOM if the policy removes it,
+// so nothing read by Sniff is lost. A stream shorter than the sniffer size is not an error.
+func (s Sniffer) Sniff(rd io.Reader) (SniffResult, *Reader, error) {
+	size := s.Size
+	if size <= 0 {
+		size = defaultSniffLen
+	}
+
+	// the BOM is peeked at along with the payload when the policy keeps it
+	need := size + maxBOMLen
+
+	if br, ok := rd.(*bufio.Reader); !ok || br.Size() < need {
+		rd = bufio.NewReaderSize(rd, need)
+	}
+
+	r := NewReader(rd, s.Options...)
+
+	enc, err := r.Encoding()
+	if err != nil {
+		return SniffResult{}, r, err
+	}
+
+	skip := 0
+	if r.BOM() == nil && enc != Unknown {
+		skip = enc.Len()
+	}
+
+	prefix, err := r.Peek(skip + size)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return SniffResult{}, r, newReadError("peek", int64(enc.Len()-skip+len(prefix)), err)
+	}
+
+	res := SniffResult{
+		Encoding: enc,
+		Prefix:   prefix[min(skip, len(prefix)):],
+	}
+
+	if s.Match != nil {
+		res.ContentType = s.Match(res.Prefix, enc)
+	}
+
+	return res, r, nil
+}
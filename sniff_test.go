@@ -0,0 +1,148 @@
+package utfbom_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+// formatMatcher tells JSON, XML and CSV apart by their first significant byte.
+func formatMatcher(prefix []byte, enc utfbom.Encoding) string {
+	if enc.IsUTF16() {
+		// every other byte is 0 for ASCII text in UTF-16
+		prefix = bytes.ReplaceAll(prefix, []byte{0}, nil)
+	}
+
+	prefix = bytes.TrimSpace(prefix)
+
+	switch {
+	case len(prefix) == 0:
+		return ""
+	case prefix[0] == '{' || prefix[0] == '[':
+		return "json"
+	case prefix[0] == '<':
+		return "xml"
+	case bytes.ContainsRune(prefix, ','):
+		return "csv"
+	default:
+		return ""
+	}
+}
+
+func TestSniffer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		opts    []utfbom.Option
+		enc     utfbom.Encoding
+		content string
+		prefix  string
+		payload string
+	}{
+		{"json_with_bom", "\ufeff{\"a\":1}", nil, utfbom.UTF8, "json", "{\"a\":1}", "{\"a\":1}"},
+		{"csv_without_bom", "a,b\n1,2\n", nil, utfbom.Unknown, "csv", "a,b\n1,2\n", "a,b\n1,2\n"},
+		{"utf16_xml", "\xff\xfe<\x00a\x00/\x00>\x00", nil, utfbom.UTF16LittleEndian, "xml", "<\x00a\x00/\x00>\x00", "<\x00a\x00/\x00>\x00"},
+		{"bom_kept", "\ufeff[1]", []utfbom.Option{utfbom.WithPolicy(utfbom.IgnoreBOM)}, utfbom.UTF8, "json", "[1]", "\ufeff[1]"},
+		{"unrecognized", "\ufeffhello", nil, utfbom.UTF8, "", "hello", "hello"},
+		{"empty", "", nil, utfbom.Unknown, "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := utfbom.Sniffer{Match: formatMatcher, Options: tc.opts}
+
+			res, rd, err := s.Sniff(strings.NewReader(tc.input))
+			be.Err(t, err, nil)
+			be.Equal(t, res.Encoding, tc.enc)
+			be.Equal(t, res.ContentType, tc.content)
+			be.Equal(t, string(res.Prefix), tc.prefix)
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.payload)
+		})
+	}
+}
+
+func TestSniffer_Size(t *testing.T) {
+	t.Parallel()
+
+	input := "\ufeff" + strings.Repeat("x", 10000)
+
+	// one byte at a time, the prefix is still complete
+	res, rd, err := utfbom.Sniffer{Size: 8000}.Sniff(iotest.OneByteReader(strings.NewReader(input)))
+	be.Err(t, err, nil)
+	be.Equal(t, len(res.Prefix), 8000)
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, len(out), 10000)
+
+	res, _, err = utfbom.Sniffer{}.Sniff(strings.NewReader(input))
+	be.Err(t, err, nil)
+	be.Equal(t, len(res.Prefix), 512)
+}
+
+func TestSniffer_BufioReader(t *testing.T) {
+	t.Parallel()
+
+	// a large enough bufio.Reader is peeked at directly
+	br := bufio.NewReaderSize(strings.NewReader("\ufeffa,b"), 1024)
+
+	_, rd, err := utfbom.Sniffer{}.Sniff(br)
+	be.Err(t, err, nil)
+	be.True(t, rd.Unwrap() == io.Reader(br))
+
+	small := bufio.NewReaderSize(strings.NewReader("\ufeffa,b"), 16)
+
+	_, rd, err = utfbom.Sniffer{}.Sniff(small)
+	be.Err(t, err, nil)
+	be.True(t, rd.Unwrap() != io.Reader(small))
+}
+
+func TestSniffer_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := utfbom.Sniffer{Options: []utfbom.Option{utfbom.WithPolicy(utfbom.ExpectBOM)}}.Sniff(strings.NewReader("a,b"))
+	be.Err(t, err, utfbom.ErrBOMExpected)
+
+	errBroken := errors.New("broken")
+
+	_, _, err = utfbom.Sniffer{}.Sniff(io.MultiReader(strings.NewReader("\ufeffa"), iotest.ErrReader(errBroken)))
+	be.Err(t, err, utfbom.ErrRead)
+	be.Err(t, err, errBroken)
+}
+
+func ExampleSniffer() {
+	s := utfbom.Sniffer{
+		Match: func(prefix []byte, _ utfbom.Encoding) string {
+			return http.DetectContentType(prefix)
+		},
+	}
+
+	res, rd, err := s.Sniff(strings.NewReader("\ufeff<!DOCTYPE html><p>hi</p>"))
+	if err != nil {
+		panic(err)
+	}
+
+	payload, _ := io.ReadAll(rd)
+
+	fmt.Println(res.Encoding, res.ContentType)
+	fmt.Printf("%q\n", payload)
+	// output:
+	// UTF8 text/html; charset=utf-8
+	// "<!DOCTYPE html><p>hi</p>"
+}
@@ -0,0 +1,87 @@
+package utfbom
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// Tally counts files and their total size in bytes.
+type Tally struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+func (t *Tally) add(r FileReport) {
+	t.Files++
+	t.Bytes += r.Size
+}
+
+// Stats aggregates file reports, such as those of ScanDir, by encoding and by file name extension,
+// for example to size up a migration from UTF-16 exports to UTF-8.
+// The zero value is ready to use; set Top to also keep the largest files having a BOM.
+//
+// Stats is not safe for concurrent use.
+type Stats struct {
+	// Top is the number of files kept in Largest.
+	Top int `json:"-"`
+	// Total counts all the files.
+	Total Tally `json:"total"`
+	// Encodings counts the files by BOM encoding, files without a BOM are counted under Unknown.
+	Encodings map[Encoding]Tally `json:"encodings"`
+	// Extensions counts the files by lower-cased extension, including the dot, then by BOM encoding.
+	// Files without an extension are counted under the empty string.
+	Extensions map[string]map[Encoding]Tally `json:"extensions"`
+	// Largest holds the Top largest files having a BOM, largest first.
+	// Files of the same size are kept in the order they were added.
+	Largest []FileReport `json:"largest"`
+}
+
+// Add counts r in the statistics.
+func (s *Stats) Add(r FileReport) {
+	if s.Encodings == nil {
+		s.Encodings = map[Encoding]Tally{}
+	}
+
+	if s.Extensions == nil {
+		s.Extensions = map[string]map[Encoding]Tally{}
+	}
+
+	s.Total.add(r)
+
+	t := s.Encodings[r.Encoding]
+	t.add(r)
+	s.Encodings[r.Encoding] = t
+
+	ext := strings.ToLower(path.Ext(r.Path))
+	if s.Extensions[ext] == nil {
+		s.Extensions[ext] = map[Encoding]Tally{}
+	}
+
+	t = s.Extensions[ext][r.Encoding]
+	t.add(r)
+	s.Extensions[ext][r.Encoding] = t
+
+	if r.Encoding == Unknown {
+		return
+	}
+
+	i := slices.IndexFunc(s.Largest, func(l FileReport) bool {
+		return l.Size < r.Size
+	})
+	if i < 0 {
+		i = len(s.Largest)
+	}
+
+	if i < s.Top {
+		s.Largest = slices.Insert(s.Largest, i, r)
+		s.Largest = s.Largest[:min(len(s.Largest), s.Top)]
+	}
+}
+
+// ScanStats adds the reports of ScanDir to s, in the order they are returned.
+func ScanStats(s *Stats) ScanOption {
+	return func(c *scanConfig) {
+		c.stats = s
+	}
+}
@@ -0,0 +1,112 @@
+package utfbom_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	st := utfbom.Stats{Top: 3}
+
+	reports, err := utfbom.ScanDir(testFS(), utfbom.ScanStats(&st))
+	be.Err(t, err, nil)
+	be.Equal(t, len(reports), 8)
+
+	be.Equal(t, st.Total, utfbom.Tally{Files: 8, Bytes: 32})
+	be.Equal(t, st.Encodings, map[utfbom.Encoding]utfbom.Tally{
+		utfbom.Unknown:           {Files: 3, Bytes: 6},
+		utfbom.UTF8:              {Files: 2, Bytes: 10},
+		utfbom.UTF16LittleEndian: {Files: 1, Bytes: 4},
+		utfbom.UTF16BigEndian:    {Files: 1, Bytes: 4},
+		utfbom.UTF32BigEndian:    {Files: 1, Bytes: 8},
+	})
+	be.Equal(t, st.Extensions, map[string]map[utfbom.Encoding]utfbom.Tally{
+		".csv": {
+			utfbom.UTF8:           {Files: 2, Bytes: 10},
+			utfbom.UTF32BigEndian: {Files: 1, Bytes: 8},
+		},
+		".txt": {
+			utfbom.Unknown:           {Files: 2, Bytes: 5},
+			utfbom.UTF16LittleEndian: {Files: 1, Bytes: 4},
+			utfbom.UTF16BigEndian:    {Files: 1, Bytes: 4},
+		},
+		".text": {
+			utfbom.Unknown: {Files: 1, Bytes: 1},
+		},
+	})
+	be.Equal(t, st.Largest, []utfbom.FileReport{
+		{Path: "docs/d.csv", Encoding: utfbom.UTF32BigEndian, Size: 8},
+		{Path: "a.csv", Encoding: utfbom.UTF8, Size: 6},
+		{Path: "docs/c.txt", Encoding: utfbom.UTF16LittleEndian, Size: 4},
+	})
+}
+
+func TestStats_Add(t *testing.T) {
+	t.Parallel()
+
+	// the zero value keeps no largest files
+	var st utfbom.Stats
+
+	st.Add(utfbom.FileReport{Path: "A.CSV", Encoding: utfbom.UTF8, Size: 10})
+	st.Add(utfbom.FileReport{Path: "Makefile", Size: 3})
+
+	be.Equal(t, st.Total, utfbom.Tally{Files: 2, Bytes: 13})
+	be.Equal(t, st.Extensions[".csv"][utfbom.UTF8], utfbom.Tally{Files: 1, Bytes: 10})
+	be.Equal(t, st.Extensions[""][utfbom.Unknown], utfbom.Tally{Files: 1, Bytes: 3})
+	be.Equal(t, len(st.Largest), 0)
+
+	// smaller files don't push larger ones out
+	st.Top = 2
+	st.Add(utfbom.FileReport{Path: "b", Encoding: utfbom.UTF8, Size: 1})
+	st.Add(utfbom.FileReport{Path: "c", Encoding: utfbom.UTF8, Size: 5})
+	st.Add(utfbom.FileReport{Path: "d", Encoding: utfbom.UTF8, Size: 2})
+	st.Add(utfbom.FileReport{Path: "e", Encoding: utfbom.UTF8, Size: 5})
+
+	be.Equal(t, st.Largest, []utfbom.FileReport{
+		{Path: "c", Encoding: utfbom.UTF8, Size: 5},
+		{Path: "e", Encoding: utfbom.UTF8, Size: 5},
+	})
+}
+
+func TestStats_JSON(t *testing.T) {
+	t.Parallel()
+
+	st := utfbom.Stats{Top: 1}
+	st.Add(utfbom.FileReport{Path: "a.csv", Encoding: utfbom.UTF16LittleEndian, Size: 4})
+
+	b, err := json.Marshal(st)
+	be.Err(t, err, nil)
+	be.Equal(t, string(b), `{"total":{"files":1,"bytes":4},`+
+		`"encodings":{"UTF16LittleEndian":{"files":1,"bytes":4}},`+
+		`"extensions":{".csv":{"UTF16LittleEndian":{"files":1,"bytes":4}}},`+
+		`"largest":[{"Path":"a.csv","Encoding":"UTF16LittleEndian","Size":4,"Offset":0}]}`)
+}
+
+func ExampleScanStats() {
+	fsys := fstest.MapFS{
+		"export.csv": {Data: []byte("\xff\xfea\x00")},
+		"notes.txt":  {Data: []byte("plain")},
+	}
+
+	var st utfbom.Stats
+
+	_, err := utfbom.ScanDir(fsys, utfbom.ScanStats(&st))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(st.Total.Files, st.Total.Bytes)
+	fmt.Println(st.Encodings[utfbom.UTF16LittleEndian].Files)
+	fmt.Println(st.Extensions[".txt"][utfbom.Unknown].Bytes)
+	// output:
+	// 2 9
+	// 1
+	// 5
+}
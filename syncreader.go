@@ -0,0 +1,44 @@
+package utfbom
+
+import (
+	"io"
+	"sync"
+)
+
+var _ io.Reader = (*SyncReader)(nil)
+
+// SyncReader is a Reader guarded by a mutex, so it can be read by multiple goroutines,
+// for instance workers draining an HTTP response body in turns.
+// Every Read call is atomic, but the order in which concurrent calls get the payload is unspecified.
+//
+// SyncReader is safe for concurrent use.
+type SyncReader struct {
+	mu sync.Mutex
+	rd *Reader
+}
+
+// NewSyncReader wraps an incoming reader the same way as NewReader does.
+// The wrapped reader must not be read by anyone else.
+// Passing a nil reader will cause a panic on the first Read call.
+func NewSyncReader(rd io.Reader, opts ...Option) *SyncReader {
+	return &SyncReader{
+		rd: NewReader(rd, opts...),
+	}
+}
+
+// Read implements the io.Reader interface, see Reader.Read.
+func (r *SyncReader) Read(buf []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rd.Read(buf)
+}
+
+// Encoding detects and removes any Byte Order Mark (BOM) unless that is already done
+// and returns the detected encoding, see Reader.Encoding.
+func (r *SyncReader) Encoding() (Encoding, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rd.Encoding()
+}
@@ -0,0 +1,67 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestSyncReader(t *testing.T) {
+	t.Parallel()
+
+	payload := strings.Repeat("x", 10000)
+	rd := utfbom.NewSyncReader(iotest.HalfReader(strings.NewReader("\xef\xbb\xbf" + payload)))
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+	)
+
+	for range 8 {
+		wg.Go(func() {
+			buf := make([]byte, 7)
+
+			for {
+				n, err := rd.Read(buf)
+
+				mu.Lock()
+				total += n
+				be.True(t, !bytes.ContainsAny(buf[:n], "\xef\xbb\xbf"))
+				mu.Unlock()
+
+				if errors.Is(err, io.EOF) {
+					return
+				}
+
+				be.Err(t, err, nil)
+			}
+		})
+
+		wg.Go(func() {
+			enc, err := rd.Encoding()
+			be.Err(t, err, nil)
+			be.Equal(t, enc, utfbom.UTF8)
+		})
+	}
+
+	wg.Wait()
+
+	be.Equal(t, total, len(payload))
+}
+
+func TestSyncReader_Options(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewSyncReader(strings.NewReader("\xef\xbb\xbfhello"), utfbom.Forbid())
+
+	_, err := io.ReadAll(rd)
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+}
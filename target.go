@@ -0,0 +1,123 @@
+package utfbom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Target is the encoding text is written in, along with whether it is written with a Byte Order Mark (BOM).
+//
+// An Encoding alone stands for a BOM: detection reports UTF8 for text that merely started with a UTF-8 BOM,
+// while Prepend, NewWriter and the rest of the encode side take UTF8 as a BOM to write,
+// so plain UTF-8 has no value of its own. Target tells the two apart:
+// UTF8.WithBOM() is UTF-8 that must be written with a BOM, UTF8.WithoutBOM() is plain UTF-8.
+//
+// The zero Target is Unknown without a BOM, which writes text unchanged.
+type Target struct {
+	Encoding Encoding
+	BOM      bool
+}
+
+// WithBOM returns the target writing text in e prefixed with the BOM of e.
+func (e Encoding) WithBOM() Target {
+	return Target{Encoding: e, BOM: true}
+}
+
+// WithoutBOM returns the target writing text in e with no BOM.
+func (e Encoding) WithoutBOM() Target {
+	return Target{Encoding: e}
+}
+
+// ParseTarget returns the target called s. It accepts the names returned by String,
+// the names recognized by ParseEncoding for targets without a BOM, and the same names
+// followed by "-BOM" or "-sig" for targets with one, such as "UTF-8-BOM", the .editorconfig charset,
+// or "utf-8-sig", the Python codec. Case, dashes, underscores and spaces are ignored.
+//
+// It returns an error wrapping ErrUnknownEncoding if s is not recognized or names Unknown with a BOM.
+func ParseTarget(s string) (Target, error) {
+	key := normalizeName(s)
+
+	base, bom := strings.CutSuffix(key, "bom")
+	if !bom {
+		base, bom = strings.CutSuffix(key, "sig")
+	}
+
+	enc, ok := lookupName(base)
+	if !ok || bom && enc == Unknown {
+		return Target{}, fmt.Errorf("%w: %q", ErrUnknownEncoding, s)
+	}
+
+	return Target{Encoding: enc, BOM: bom}, nil
+}
+
+// String returns the name of the encoding, followed by "BOM" if the target writes one,
+// such as "UTF8" or "UTF8BOM".
+func (t Target) String() string {
+	if t.BOM && t.Encoding != Unknown {
+		return t.Encoding.String() + "BOM"
+	}
+
+	return t.Encoding.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// The target is marshaled as its String name.
+func (t Target) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It accepts the names recognized by ParseTarget. Empty text is unmarshaled as the zero Target.
+func (t *Target) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = Target{}
+
+		return nil
+	}
+
+	target, err := ParseTarget(string(text))
+	if err != nil {
+		return err
+	}
+
+	*t = target
+
+	return nil
+}
+
+// Bytes returns the BOM written before the text, nil if the target writes none.
+func (t Target) Bytes() []byte {
+	if !t.BOM {
+		return nil
+	}
+
+	return t.Encoding.Bytes()
+}
+
+// Policy returns the BOM policy writing text as the target says:
+// UseBOM if the target writes a BOM, ForbidBOM otherwise.
+func (t Target) Policy() BOMPolicy {
+	if t.BOM {
+		return UseBOM
+	}
+
+	return ForbidBOM
+}
+
+// Options returns the options making writers of the package follow the target, see Policy.
+//
+//	w := utfbom.NewEncodingWriter(f, t.Encoding, t.Options()...)
+//
+// A UTF-8 BOM at the beginning of the text given to NewEncodingWriter is removed either way,
+// so plain output never starts with a BOM, unless the encoding is Unknown, which passes the text through.
+func (t Target) Options() []Option {
+	return []Option{WithPolicy(t.Policy())}
+}
+
+// Encode converts the UTF-8 text s to the target encoding with EncodeFromUTF8,
+// prefixed with the BOM if the target writes one.
+// A UTF-8 BOM at the beginning of s is removed first, so the output of a target without a BOM
+// never starts with one. For Unknown, s is copied unchanged.
+func (t Target) Encode(s []byte) ([]byte, error) {
+	return EncodeFromUTF8(s, t.Encoding, t.Policy())
+}
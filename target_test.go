@@ -0,0 +1,160 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestParseTarget(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expected utfbom.Target
+	}{
+		{"UTF-8", utfbom.UTF8.WithoutBOM()},
+		{"utf8", utfbom.UTF8.WithoutBOM()},
+		{"UTF8BOM", utfbom.UTF8.WithBOM()},
+		{"utf-8-bom", utfbom.UTF8.WithBOM()},
+		{"utf-8-sig", utfbom.UTF8.WithBOM()},
+		{"UTF-16LE", utfbom.UTF16LittleEndian.WithoutBOM()},
+		{"utf_16_le_bom", utfbom.UTF16LittleEndian.WithBOM()},
+		{"UTF32BigEndianBOM", utfbom.UTF32BigEndian.WithBOM()},
+		{"Unknown", utfbom.Target{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			target, err := utfbom.ParseTarget(tc.name)
+			be.Err(t, err, nil)
+			be.Equal(t, target, tc.expected)
+
+			// the String name parses back into the same target
+			back, err := utfbom.ParseTarget(target.String())
+			be.Err(t, err, nil)
+			be.Equal(t, back, target)
+		})
+	}
+}
+
+func TestParseTarget_Unknown(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "bom", "UnknownBOM", "utf-16-bom", "latin1", "utf-8-bom-bom"} {
+		_, err := utfbom.ParseTarget(name)
+		be.Err(t, err, utfbom.ErrUnknownEncoding)
+	}
+}
+
+func TestTarget_String(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.UTF8.WithBOM().String(), "UTF8BOM")
+	be.Equal(t, utfbom.UTF8.WithoutBOM().String(), "UTF8")
+	be.Equal(t, utfbom.Unknown.WithBOM().String(), "Unknown")
+}
+
+func TestTarget_JSON(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Output utfbom.Target `json:"output"`
+	}
+
+	out, err := json.Marshal(config{Output: utfbom.UTF16LittleEndian.WithBOM()})
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), `{"output":"UTF16LittleEndianBOM"}`)
+
+	var cfg config
+
+	err = json.Unmarshal([]byte(`{"output":"utf-8-bom"}`), &cfg)
+	be.Err(t, err, nil)
+	be.Equal(t, cfg.Output, utfbom.UTF8.WithBOM())
+
+	err = json.Unmarshal([]byte(`{"output":""}`), &cfg)
+	be.Err(t, err, nil)
+	be.Equal(t, cfg.Output, utfbom.Target{})
+
+	err = json.Unmarshal([]byte(`{"output":"utf-16"}`), &cfg)
+	be.Err(t, err, utfbom.ErrUnknownEncoding)
+}
+
+func TestTarget_Encode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		target   utfbom.Target
+		input    string
+		expected string
+	}{
+		{"utf8_bom", utfbom.UTF8.WithBOM(), "a", "\ufeffa"},
+		{"utf8_bom_not_duplicated", utfbom.UTF8.WithBOM(), "\ufeffa", "\ufeffa"},
+		{"plain_utf8", utfbom.UTF8.WithoutBOM(), "a", "a"},
+		{"plain_utf8_removes_bom", utfbom.UTF8.WithoutBOM(), "\ufeffa", "a"},
+		{"utf16le_bom", utfbom.UTF16LittleEndian.WithBOM(), "a", "\xff\xfea\x00"},
+		{"plain_utf16be", utfbom.UTF16BigEndian.WithoutBOM(), "\ufeffa", "\x00a"},
+		{"unknown", utfbom.Target{}, "\ufeffa", "\ufeffa"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := tc.target.Encode([]byte(tc.input))
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+
+			// the writers agree with Encode
+			var buf bytes.Buffer
+
+			w := utfbom.NewEncodingWriter(&buf, tc.target.Encoding, tc.target.Options()...)
+			_, err = w.Write([]byte(tc.input))
+			be.Err(t, err, nil)
+			be.Err(t, w.Close(), nil)
+			be.Equal(t, buf.String(), tc.expected)
+		})
+	}
+
+	_, err := utfbom.UTF7.WithBOM().Encode([]byte("a"))
+	be.Err(t, err, utfbom.ErrUnsupportedEncoding)
+}
+
+func TestTarget_Bytes(t *testing.T) {
+	t.Parallel()
+
+	be.Equal(t, utfbom.UTF8.WithBOM().Bytes(), []byte{0xef, 0xbb, 0xbf})
+	be.Equal(t, utfbom.UTF8.WithoutBOM().Bytes(), nil)
+	be.Equal(t, utfbom.Unknown.WithBOM().Bytes(), nil)
+
+	// NewWriter writes the BOM of the target only
+	var buf bytes.Buffer
+
+	_, err := utfbom.NewWriter(&buf, utfbom.UTF8, utfbom.UTF8.WithoutBOM().Options()...).Write([]byte("a"))
+	be.Err(t, err, nil)
+	be.Equal(t, buf.String(), "a")
+}
+
+func ExampleTarget() {
+	// detected from the input, the encoding says nothing about the output
+	_, enc := utfbom.Trim([]byte("\ufeffname,city\n"))
+
+	for _, target := range []utfbom.Target{enc.WithBOM(), enc.WithoutBOM()} {
+		out, err := target.Encode([]byte("a,b\n"))
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("%s %q\n", target, out)
+	}
+	// output:
+	// UTF8BOM "\ufeffa,b\n"
+	// UTF8 "a,b\n"
+}
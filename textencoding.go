@@ -0,0 +1,60 @@
+package utfbom
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// TextEncoding returns the golang.org/x/text encoding matching e.
+//
+// UTF8 maps to unicode.UTF8BOM. UTF-16 and UTF-32 encodings use the UseBOM policy
+// with the byte order of e: decoders strip a leading BOM and encoders write one.
+// For Unknown encoding it returns encoding.Nop.
+func (e Encoding) TextEncoding() encoding.Encoding {
+	switch e {
+	default:
+		return encoding.Nop
+	case UTF8:
+		return unicode.UTF8BOM
+	case UTF16BigEndian:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	case UTF16LittleEndian:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case UTF32BigEndian:
+		return utf32.UTF32(utf32.BigEndian, utf32.UseBOM)
+	case UTF32LittleEndian:
+		return utf32.UTF32(utf32.LittleEndian, utf32.UseBOM)
+	}
+}
+
+// FromTextEncoding returns the Encoding matching a golang.org/x/text encoding.
+// UTF-16 and UTF-32 encodings match regardless of their BOM policy.
+// It returns Unknown if there is no match.
+func FromTextEncoding(te encoding.Encoding) Encoding {
+	if te == unicode.UTF8 || te == unicode.UTF8BOM {
+		return UTF8
+	}
+
+	for _, policy := range []unicode.BOMPolicy{unicode.IgnoreBOM, unicode.UseBOM, unicode.ExpectBOM} {
+		if te == unicode.UTF16(unicode.BigEndian, policy) {
+			return UTF16BigEndian
+		}
+
+		if te == unicode.UTF16(unicode.LittleEndian, policy) {
+			return UTF16LittleEndian
+		}
+	}
+
+	for _, policy := range []utf32.BOMPolicy{utf32.IgnoreBOM, utf32.UseBOM, utf32.ExpectBOM} {
+		if te == utf32.UTF32(utf32.BigEndian, policy) {
+			return UTF32BigEndian
+		}
+
+		if te == utf32.UTF32(utf32.LittleEndian, policy) {
+			return UTF32LittleEndian
+		}
+	}
+
+	return Unknown
+}
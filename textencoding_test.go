@@ -0,0 +1,72 @@
+package utfbom_test
+
+import (
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+func TestEncoding_TextEncoding(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			te := enc.TextEncoding()
+			be.Equal(t, utfbom.FromTextEncoding(te), enc)
+
+			// encoder writes the BOM, decoder strips it
+			encoded, err := te.NewEncoder().Bytes([]byte(multilingual))
+			be.Err(t, err, nil)
+			be.Equal(t, encoded, encode(enc, multilingual))
+
+			decoded, err := te.NewDecoder().Bytes(encoded)
+			be.Err(t, err, nil)
+			be.Equal(t, string(decoded), multilingual)
+		})
+	}
+
+	be.Equal(t, utfbom.Unknown.TextEncoding(), encoding.Nop)
+}
+
+func TestFromTextEncoding(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		te       encoding.Encoding
+		expected utfbom.Encoding
+	}{
+		{"nil", nil, utfbom.Unknown},
+		{"nop", encoding.Nop, utfbom.Unknown},
+		{"windows1252", charmap.Windows1252, utfbom.Unknown},
+		{"utf8", unicode.UTF8, utfbom.UTF8},
+		{"utf8bom", unicode.UTF8BOM, utfbom.UTF8},
+		{"utf16be_ignore", unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), utfbom.UTF16BigEndian},
+		{"utf16le_expect", unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), utfbom.UTF16LittleEndian},
+		{"utf32be_ignore", utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM), utfbom.UTF32BigEndian},
+		{"utf32le_expect", utf32.UTF32(utf32.LittleEndian, utf32.ExpectBOM), utfbom.UTF32LittleEndian},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.FromTextEncoding(tc.te), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package utfbom
+
+import (
+	"golang.org/x/text/transform"
+)
+
+var (
+	_ transform.Transformer = (*removeBOM)(nil)
+	_ transform.Transformer = (*addBOM)(nil)
+)
+
+// RemoveBOMTransformer returns a transform.Transformer that removes
+// a leading Byte Order Mark (BOM) of any supported encoding.
+// It composes with golang.org/x/text decoders via transform.Chain.
+func RemoveBOMTransformer() transform.Transformer {
+	return &removeBOM{}
+}
+
+type removeBOM struct {
+	done bool
+}
+
+// Reset implements the transform.Transformer interface.
+func (t *removeBOM) Reset() {
+	t.done = false
+}
+
+// Transform implements the transform.Transformer interface.
+func (t *removeBOM) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	var nSrc int
+
+	if !t.done {
+		if !atEOF && isPartialBOM(src) {
+			return 0, 0, transform.ErrShortSrc
+		}
+
+		nSrc = DetectEncoding(src).Len()
+		t.done = true
+	}
+
+	nDst := copy(dst, src[nSrc:])
+	nSrc += nDst
+
+	if nSrc < len(src) {
+		return nDst, nSrc, transform.ErrShortDst
+	}
+
+	return nDst, nSrc, nil
+}
+
+// AddBOMTransformer returns a transform.Transformer that adds
+// the Byte Order Mark (BOM) of enc to the beginning of the output.
+// Same as Prepend, it leaves the input unmodified if it already starts with any BOM.
+// For Unknown encoding it returns transform.Nop.
+func AddBOMTransformer(enc Encoding) transform.Transformer {
+	if enc == Unknown {
+		return transform.Nop
+	}
+
+	return &addBOM{bom: enc.Bytes()}
+}
+
+type addBOM struct {
+	bom  []byte
+	done bool
+}
+
+// Reset implements the transform.Transformer interface.
+func (t *addBOM) Reset() {
+	t.done = false
+}
+
+// Transform implements the transform.Transformer interface.
+func (t *addBOM) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	var nDst int
+
+	if !t.done {
+		if !atEOF && isPartialBOM(src) {
+			return 0, 0, transform.ErrShortSrc
+		}
+
+		if DetectEncoding(src) == Unknown {
+			if len(dst) < len(t.bom) {
+				return 0, 0, transform.ErrShortDst
+			}
+
+			nDst = copy(dst, t.bom)
+		}
+
+		t.done = true
+	}
+
+	nSrc := copy(dst[nDst:], src)
+	nDst += nSrc
+
+	if nSrc < len(src) {
+		return nDst, nSrc, transform.ErrShortDst
+	}
+
+	return nDst, nSrc, nil
+}
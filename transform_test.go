@@ -0,0 +1,121 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+func TestRemoveBOMTransformer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected []byte
+	}{
+		{"empty", nil, []byte{}},
+		{"no_bom", []byte("hello"), []byte("hello")},
+		{"incomplete_bom", []byte{0xef, 0xbb}, []byte{0xef, 0xbb}},
+		{"utf8", append(utf8BOM, "hello"...), []byte("hello")},
+		{"utf16be", append(utf16BEBOM, 0x00, 'h'), []byte{0x00, 'h'}},
+		{"utf16le", append(utf16LEBOM, 'h', 0x00), []byte{'h', 0x00}},
+		{"utf32be", append(utf32BEBOM, 0x00, 0x00, 0x00, 'h'), []byte{0x00, 0x00, 0x00, 'h'}},
+		{"utf32le", append(utf32LEBOM, 'h', 0x00, 0x00, 0x00), []byte{'h', 0x00, 0x00, 0x00}},
+		{"only_second_bom_kept", append(append(utf8BOM, utf8BOM...), 'h'), append(utf8BOM, 'h')},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, _, err := transform.Bytes(utfbom.RemoveBOMTransformer(), tc.input)
+			be.Err(t, err, nil)
+			be.Equal(t, out, tc.expected)
+
+			// BOM split across several reads.
+			rd := transform.NewReader(iotest.OneByteReader(bytes.NewReader(tc.input)), utfbom.RemoveBOMTransformer())
+			out, err = io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, out, tc.expected)
+		})
+	}
+}
+
+func TestAddBOMTransformer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		enc      utfbom.Encoding
+		input    []byte
+		expected []byte
+	}{
+		{"unknown", utfbom.Unknown, []byte("hello"), []byte("hello")},
+		{"utf8_empty", utfbom.UTF8, nil, utf8BOM},
+		{"utf8", utfbom.UTF8, []byte("hello"), append(utf8BOM, "hello"...)},
+		{"utf16le", utfbom.UTF16LittleEndian, []byte{'h', 0x00}, append(utf16LEBOM, 'h', 0x00)},
+		{"utf32be", utfbom.UTF32BigEndian, []byte{0x00, 0x00, 0x00, 'h'}, append(utf32BEBOM, 0x00, 0x00, 0x00, 'h')},
+		{"idempotent_when_bom_exists", utfbom.UTF8, append(utf8BOM, 'h'), append(utf8BOM, 'h')},
+		{"idempotent_when_different_bom_exists", utfbom.UTF8, append(utf16BEBOM, 0x00, 'h'), append(utf16BEBOM, 0x00, 'h')},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, _, err := transform.Bytes(utfbom.AddBOMTransformer(tc.enc), tc.input)
+			be.Err(t, err, nil)
+			be.Equal(t, out, tc.expected)
+
+			rd := transform.NewReader(iotest.OneByteReader(bytes.NewReader(tc.input)), utfbom.AddBOMTransformer(tc.enc))
+			out, err = io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, out, tc.expected)
+		})
+	}
+}
+
+func TestTransformers_ShortDst(t *testing.T) {
+	t.Parallel()
+
+	input := append(utf8BOM, "hello"...)
+
+	dst := make([]byte, 2)
+	nDst, nSrc, err := utfbom.RemoveBOMTransformer().Transform(dst, input, true)
+	be.Err(t, err, transform.ErrShortDst)
+	be.Equal(t, nDst, 2)
+	be.Equal(t, nSrc, 5)
+
+	nDst, nSrc, err = utfbom.AddBOMTransformer(utfbom.UTF32BigEndian).Transform(dst, []byte("hello"), true)
+	be.Err(t, err, transform.ErrShortDst)
+	be.Equal(t, nDst, 0)
+	be.Equal(t, nSrc, 0)
+}
+
+func ExampleRemoveBOMTransformer() {
+	// "hey" in UTF-16 Little Endian prefixed with a UTF-8 BOM by a sloppy producer.
+	input := append([]byte{0xef, 0xbb, 0xbf}, 'h', 0x00, 'e', 0x00, 'y', 0x00)
+
+	t := transform.Chain(
+		utfbom.RemoveBOMTransformer(),
+		unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder(),
+	)
+
+	out, _, err := transform.Bytes(t, input)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q\n", out)
+
+	// output:
+	// "hey"
+}
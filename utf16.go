@@ -0,0 +1,40 @@
+package utfbom
+
+const (
+	bomUnit        = 0xfeff // U+FEFF as a UTF-16 code unit
+	swappedBOMUnit = 0xfffe // U+FEFF read in the opposite byte order
+)
+
+// DetectUTF16 detects a Byte Order Mark (BOM) at the beginning of UTF-16 code units,
+// such as the slices returned by Windows system calls and windows.UTF16PtrToString pipelines.
+//
+// The code units are taken as decoded in little-endian order, as on Windows:
+// a leading U+FEFF is reported as UTF16LittleEndian, while a leading 0xfffe,
+// which is a big-endian BOM decoded the wrong way round, is reported as UTF16BigEndian.
+// Unknown is returned for anything else.
+func DetectUTF16(s []uint16) Encoding {
+	if len(s) == 0 {
+		return Unknown
+	}
+
+	switch s[0] {
+	case bomUnit:
+		return UTF16LittleEndian
+	case swappedBOMUnit:
+		return UTF16BigEndian
+	default:
+		return Unknown
+	}
+}
+
+// TrimUTF16 removes a leading Byte Order Mark (BOM) code unit from s without copying it,
+// and returns the encoding it stands for, see DetectUTF16.
+// The code units of a UTF16BigEndian payload are left byte-swapped.
+func TrimUTF16(s []uint16) ([]uint16, Encoding) {
+	enc := DetectUTF16(s)
+	if enc == Unknown {
+		return s, Unknown
+	}
+
+	return s[1:], enc
+}
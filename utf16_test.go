@@ -0,0 +1,62 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestTrimUTF16(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []uint16
+		expected []uint16
+		enc      utfbom.Encoding
+	}{
+		{"little_endian_bom", []uint16{0xfeff, 'h', 'i'}, []uint16{'h', 'i'}, utfbom.UTF16LittleEndian},
+		{"swapped_bom", []uint16{0xfffe, 0x6800}, []uint16{0x6800}, utfbom.UTF16BigEndian},
+		{"bom_only", []uint16{0xfeff}, []uint16{}, utfbom.UTF16LittleEndian},
+		{"interior_bom", []uint16{'h', 0xfeff}, []uint16{'h', 0xfeff}, utfbom.Unknown},
+		{"no_bom", []uint16{'h', 'i'}, []uint16{'h', 'i'}, utfbom.Unknown},
+		{"empty", []uint16{}, []uint16{}, utfbom.Unknown},
+		{"nil", nil, nil, utfbom.Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.DetectUTF16(tc.input), tc.enc)
+
+			out, enc := utfbom.TrimUTF16(tc.input)
+			be.Equal(t, out, tc.expected)
+			be.Equal(t, enc, tc.enc)
+		})
+	}
+}
+
+func TestTrimUTF16_NoCopy(t *testing.T) {
+	t.Parallel()
+
+	s := []uint16{0xfeff, 'a'}
+	out, _ := utfbom.TrimUTF16(s)
+	out[0] = 'b'
+
+	be.Equal(t, s[1], uint16('b'))
+}
+
+func ExampleTrimUTF16() {
+	// as returned by a Windows API reading a text file that starts with a BOM
+	units := utf16.Encode([]rune("\ufeffC:\\Temp"))
+
+	units, enc := utfbom.TrimUTF16(units)
+
+	fmt.Println(enc, string(utf16.Decode(units)))
+	// output:
+	// UTF16LittleEndian C:\Temp
+}
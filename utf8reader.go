@@ -0,0 +1,267 @@
+package utfbom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var _ io.Reader = (*UTF8Reader)(nil)
+
+// ErrUnsupportedEncoding is returned by UTF8Reader for payloads it can't decode,
+// such as UTF-7 or SCSU.
+var ErrUnsupportedEncoding = errors.New("utfbom: encoding is not supported")
+
+const defaultBufSize = 4096
+
+// UTF8Reader implements automatic BOM (Unicode Byte Order Mark) removing
+// and decoding of UTF-16 and UTF-32 payloads into UTF-8 for an io.Reader object.
+//
+// Payloads without a BOM and UTF-8 payloads are passed through unchanged, unless UTF-8 payloads
+// are checked under WithValidateUTF8; reading payloads of any other encoding fails with ErrUnsupportedEncoding.
+// Invalid code units, such as unpaired UTF-16 surrogates, are replaced with utf8.RuneError (U+FFFD),
+// or make Read fail with ErrInvalidSequence under RejectInvalid.
+//
+// UTF8Reader is not safe for concurrent use.
+type UTF8Reader struct {
+	rd      *Reader
+	raw     []byte // bytes read but not decoded yet
+	out     []byte // decoded bytes
+	pos     int    // read position in out
+	err     error  // sticky error of the underlying reader or invalid sequence under RejectInvalid
+	off     int64  // stream offset of raw[0], BOM included
+	decoded bool   // any payload has been decoded
+	valid   bool   // UTF-8 payloads are checked, see WithValidateUTF8
+	// Enc will be available after first read
+	Enc Encoding
+}
+
+// NewUTF8Reader wraps an incoming reader.
+// Options are applied the same way as for Reader; under IgnoreBOM policy
+// the BOM is kept and decoded as U+FEFF.
+// Passing a nil reader will cause a panic on the first Read call.
+func NewUTF8Reader(rd io.Reader, opts ...Option) *UTF8Reader {
+	r := &UTF8Reader{
+		rd:  NewReader(rd, opts...),
+		Enc: Unknown,
+	}
+
+	// UTF-8 payloads are checked while decoding rather than by the wrapped Reader,
+	// so invalid sequences can be replaced
+	r.valid = r.rd.opts.validate
+	r.rd.opts.validate = false
+
+	return r
+}
+
+// Read implements the io.Reader interface.
+// On the first call, it detects and removes any Byte Order Mark (BOM).
+// Subsequent calls return the payload decoded into UTF-8.
+func (r *UTF8Reader) Read(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+
+	_, err := r.rd.detect(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	r.Enc = r.rd.Enc
+
+	if r.Enc == Unknown || r.Enc == UTF8 && !r.valid {
+		return r.rd.Read(buf)
+	}
+
+	if r.Enc != UTF8 && !r.Enc.IsUTF16() && !r.Enc.IsUTF32() {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, r.Enc)
+	}
+
+	for r.pos == len(r.out) {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.fill()
+	}
+
+	n := copy(buf, r.out[r.pos:])
+	r.pos += n
+
+	return n, nil
+}
+
+// Unwrap returns the reader wrapped by r, see Reader.Unwrap.
+func (r *UTF8Reader) Unwrap() io.Reader {
+	return r.rd.rd
+}
+
+// fill reads the next chunk of raw bytes and decodes as much of it as possible.
+func (r *UTF8Reader) fill() {
+	if r.raw == nil {
+		r.raw, r.out = splitBuffer(r.rd.opts.buf, r.Enc)
+
+		if r.rd.opts.strips(r.Enc) {
+			r.off = int64(r.Enc.Len())
+		}
+	}
+
+	n, err := r.rd.Read(r.raw[len(r.raw):cap(r.raw)])
+	r.raw = r.raw[:len(r.raw)+n]
+	r.err = err
+
+	var consumed int
+
+	r.out, consumed = appendDecoded(r.out[:0], r.raw, r.Enc, errors.Is(err, io.EOF))
+	r.pos = 0
+
+	if r.rd.opts.reject {
+		// the payload preceding the invalid sequence is still delivered
+		if off := firstInvalid(r.raw[:consumed], r.Enc); off >= 0 {
+			r.out, consumed = appendDecoded(r.out[:0], r.raw[:off], r.Enc, false)
+			r.err = &TranscodeError{Offset: r.off + int64(off), Encoding: r.Enc}
+		}
+	}
+
+	if r.rd.opts.scrub {
+		r.out, _ = removeZWNBSP(r.out, !r.decoded && !r.rd.opts.strips(r.Enc))
+	}
+
+	r.decoded = r.decoded || len(r.out) != 0
+	r.off += int64(consumed)
+	r.raw = r.raw[:copy(r.raw, r.raw[consumed:])]
+}
+
+// splitBuffer carves the raw and decoded buffers of UTF8Reader out of buf, allocating them if buf is nil.
+// The decoded buffer is large enough for any raw buffer contents of enc:
+// decoding 2 bytes of UTF-16 or UTF-32 yields at most 3 bytes and the final incomplete code unit yields 3 bytes,
+// while checked UTF-8 grows threefold when every byte is invalid and replaced with utf8.RuneError.
+func splitBuffer(buf []byte, enc Encoding) ([]byte, []byte) {
+	if buf == nil {
+		return make([]byte, 0, defaultBufSize), nil
+	}
+
+	// the raw buffer gets n bytes, a multiple of 4, such that 1.5n+3 bytes are left, or 3n bytes for UTF-8
+	n := (len(buf) - 3) * 2 / 5 / 4 * 4
+	if enc == UTF8 {
+		n = len(buf) / 4 / 4 * 4
+	}
+
+	return buf[:0:n], buf[n:n]
+}
+
+// appendDecoded appends src decoded from enc as UTF-8 to dst and returns the extended buffer
+// along with the number of consumed src bytes.
+// Incomplete trailing code units are left unconsumed unless atEOF is set,
+// in which case they are replaced with utf8.RuneError, same as any invalid sequence.
+// UTF-8 is checked the same way, encodings other than UTF-8, UTF-16 and UTF-32 are copied as is.
+func appendDecoded(dst, src []byte, enc Encoding, atEOF bool) ([]byte, int) {
+	switch enc {
+	case UTF8:
+		return appendUTF8(dst, src, atEOF)
+	case UTF16BigEndian:
+		return appendUTF16(dst, src, binary.BigEndian, atEOF)
+	case UTF16LittleEndian:
+		return appendUTF16(dst, src, binary.LittleEndian, atEOF)
+	case UTF32BigEndian:
+		return appendUTF32(dst, src, binary.BigEndian, atEOF)
+	case UTF32LittleEndian:
+		return appendUTF32(dst, src, binary.LittleEndian, atEOF)
+	default:
+		return append(dst, src...), len(src)
+	}
+}
+
+func appendUTF8(dst, src []byte, atEOF bool) ([]byte, int) {
+	i := 0
+
+	for i < len(src) {
+		if src[i] < utf8.RuneSelf {
+			dst = append(dst, src[i])
+			i++
+
+			continue
+		}
+
+		if !atEOF && !utf8.FullRune(src[i:]) {
+			break
+		}
+
+		r, size := utf8.DecodeRune(src[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = utf8.AppendRune(dst, r)
+		} else {
+			dst = append(dst, src[i:i+size]...)
+		}
+
+		i += size
+	}
+
+	return dst, i
+}
+
+func appendUTF16(dst, src []byte, order binary.ByteOrder, atEOF bool) ([]byte, int) {
+	i := 0
+
+	for len(src)-i >= 2 {
+		u := rune(order.Uint16(src[i:]))
+
+		switch {
+		case !utf16.IsSurrogate(u):
+			dst = utf8.AppendRune(dst, u)
+			i += 2
+		case u >= 0xdc00:
+			// low surrogate without a preceding high one
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i += 2
+		case len(src)-i < 4 && !atEOF:
+			return dst, i
+		case len(src)-i < 4:
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i += 2
+		default:
+			r := utf16.DecodeRune(u, rune(order.Uint16(src[i+2:])))
+			if r == utf8.RuneError {
+				// high surrogate is not followed by a low one,
+				// the next code unit is decoded on its own.
+				dst = utf8.AppendRune(dst, utf8.RuneError)
+				i += 2
+
+				continue
+			}
+
+			dst = utf8.AppendRune(dst, r)
+			i += 4
+		}
+	}
+
+	if atEOF && i < len(src) {
+		dst = utf8.AppendRune(dst, utf8.RuneError)
+		i = len(src)
+	}
+
+	return dst, i
+}
+
+func appendUTF32(dst, src []byte, order binary.ByteOrder, atEOF bool) ([]byte, int) {
+	i := 0
+
+	for ; len(src)-i >= 4; i += 4 {
+		r := rune(order.Uint32(src[i:]))
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+
+		dst = utf8.AppendRune(dst, r)
+	}
+
+	if atEOF && i < len(src) {
+		dst = utf8.AppendRune(dst, utf8.RuneError)
+		i = len(src)
+	}
+
+	return dst, i
+}
@@ -0,0 +1,192 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+// encode returns s encoded as enc and prefixed with the corresponding BOM.
+func encode(enc utfbom.Encoding, s string) []byte {
+	out := enc.Bytes()
+
+	switch enc {
+	case utfbom.UTF16BigEndian:
+		for _, u := range utf16.Encode([]rune(s)) {
+			out = binary.BigEndian.AppendUint16(out, u)
+		}
+	case utfbom.UTF16LittleEndian:
+		for _, u := range utf16.Encode([]rune(s)) {
+			out = binary.LittleEndian.AppendUint16(out, u)
+		}
+	case utfbom.UTF32BigEndian:
+		for _, r := range s {
+			out = binary.BigEndian.AppendUint32(out, uint32(r))
+		}
+	case utfbom.UTF32LittleEndian:
+		for _, r := range s {
+			out = binary.LittleEndian.AppendUint32(out, uint32(r))
+		}
+	default:
+		out = append(out, s...)
+	}
+
+	return out
+}
+
+const multilingual = "Grüße, 世界! 🙂\nZażółć gęślą jaźń"
+
+func TestUTF8Reader_Decodes(t *testing.T) {
+	t.Parallel()
+
+	encodings := []utfbom.Encoding{
+		utfbom.Unknown,
+		utfbom.UTF8,
+		utfbom.UTF16BigEndian,
+		utfbom.UTF16LittleEndian,
+		utfbom.UTF32BigEndian,
+		utfbom.UTF32LittleEndian,
+	}
+
+	for _, enc := range encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			t.Parallel()
+
+			rd := utfbom.NewUTF8Reader(bytes.NewReader(encode(enc, multilingual)))
+			be.Err(t, iotest.TestReader(rd, []byte(multilingual)), nil)
+			be.Equal(t, rd.Enc, enc)
+		})
+
+		t.Run(enc.String()+"_one_byte_reader", func(t *testing.T) {
+			t.Parallel()
+
+			rd := utfbom.NewUTF8Reader(iotest.OneByteReader(bytes.NewReader(encode(enc, multilingual))))
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), multilingual)
+		})
+	}
+}
+
+func TestUTF8Reader_InvalidInputReplaced(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{"utf16le_lone_high_surrogate", []byte{0xff, 0xfe, 0x3d, 0xd8, 'a', 0x00}, "\ufffda"},
+		{"utf16le_lone_low_surrogate", []byte{0xff, 0xfe, 0x00, 0xde, 'a', 0x00}, "\ufffda"},
+		{"utf16le_high_surrogate_at_eof", []byte{0xff, 0xfe, 'a', 0x00, 0x3d, 0xd8}, "a\ufffd"},
+		{"utf16be_odd_length", []byte{0xfe, 0xff, 0x00, 'a', 0x00}, "a\ufffd"},
+		{"utf32be_out_of_range", []byte{0x00, 0x00, 0xfe, 0xff, 0x00, 0x11, 0x00, 0x00}, "\ufffd"},
+		{"utf32le_surrogate", []byte{0xff, 0xfe, 0x00, 0x00, 0x00, 0xd8, 0x00, 0x00}, "\ufffd"},
+		{"utf32le_truncated", []byte{0xff, 0xfe, 0x00, 0x00, 'a', 0x00, 0x00, 0x00, 'b'}, "a\ufffd"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(tc.input)))
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+		})
+	}
+}
+
+func TestUTF8Reader_RejectInvalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		opts     []utfbom.Option
+		expected string
+		offset   int
+	}{
+		{"utf16le_lone_high_surrogate", []byte{0xff, 0xfe, 'a', 0x00, 0x3d, 0xd8, 'b', 0x00}, nil, "a", 4},
+		{"utf16le_lone_low_surrogate", []byte{0xff, 0xfe, 0x00, 0xde, 'a', 0x00}, nil, "", 2},
+		{"utf16le_high_surrogate_at_eof", []byte{0xff, 0xfe, 'a', 0x00, 0x3d, 0xd8}, nil, "a", 4},
+		{"utf16be_odd_length", []byte{0xfe, 0xff, 0x00, 'a', 0x00}, nil, "a", 4},
+		{"utf16be_kept_bom", []byte{0xfe, 0xff, 0x00, 'a', 0xdc, 0x00}, []utfbom.Option{utfbom.Passthrough()}, "\ufeffa", 4},
+		{"utf32be_out_of_range", []byte{0x00, 0x00, 0xfe, 0xff, 'a', 0x00, 0x00, 0x00}, nil, "", 4},
+		{"utf32le_truncated", []byte{0xff, 0xfe, 0x00, 0x00, 'a', 0x00, 0x00, 0x00, 'b'}, nil, "a", 8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append([]utfbom.Option{utfbom.RejectInvalid()}, tc.opts...)
+
+			out, err := io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(tc.input), opts...))
+			be.Err(t, err, utfbom.ErrInvalidSequence)
+			be.Err(t, err, fmt.Sprintf("at offset %d", tc.offset))
+			be.Equal(t, string(out), tc.expected)
+
+			out, err = io.ReadAll(utfbom.NewUTF8Reader(iotest.OneByteReader(bytes.NewReader(tc.input)), opts...))
+			be.Err(t, err, fmt.Sprintf("at offset %d", tc.offset))
+			be.Equal(t, string(out), tc.expected)
+		})
+	}
+}
+
+func TestUTF8Reader_RejectInvalid_Offset(t *testing.T) {
+	t.Parallel()
+
+	// the lone surrogate is far past the first decoded chunk
+	input := append(encode(utfbom.UTF16BigEndian, strings.Repeat("é", 5000)), 0xd8, 0x00, 0x00, 'a')
+
+	out, err := io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(input), utfbom.RejectInvalid()))
+	be.Err(t, err, "utfbom: invalid sequence: UTF16BigEndian at offset 10002")
+	be.Equal(t, string(out), strings.Repeat("é", 5000))
+
+	out, err = io.ReadAll(utfbom.NewUTF8Reader(bytes.NewReader(input[:len(input)-4]), utfbom.RejectInvalid()))
+	be.Err(t, err, nil)
+	be.Equal(t, len(out), 10000)
+}
+
+func ExampleUTF8Reader() {
+	// "Name,City\nJürgen,Köln\n" as exported by Excel in UTF-16 Little Endian.
+	data := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune("Name,City\nJürgen,Köln\n")) {
+		data = binary.LittleEndian.AppendUint16(data, u)
+	}
+
+	rd := utfbom.NewUTF8Reader(bytes.NewReader(data))
+
+	rows, err := csv.NewReader(rd).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("detected encoding:", rd.Enc)
+	fmt.Println(rows)
+
+	// output:
+	// detected encoding: UTF16LittleEndian
+	// [[Name City] [Jürgen Köln]]
+}
+
+func TestUTF8Reader_UnsupportedEncoding(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewUTF8Reader(bytes.NewReader([]byte{0x0e, 0xfe, 0xff, 'h'}), utfbom.WithExtendedBOMs())
+
+	_, err := io.ReadAll(rd)
+	be.Err(t, err, utfbom.ErrUnsupportedEncoding)
+	be.Equal(t, err.Error(), "utfbom: encoding is not supported: SCSU")
+	be.Equal(t, rd.Enc, utfbom.SCSU)
+}
@@ -7,18 +7,42 @@ package utfbom
 
 import (
 	"bufio"
-	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"slices"
-	"sync"
+	"strings"
 )
 
-var _ io.Reader = (*Reader)(nil)
+var (
+	_ io.Reader      = (*Reader)(nil)
+	_ io.WriterTo    = (*Reader)(nil)
+	_ io.Seeker      = (*Reader)(nil)
+	_ io.ByteScanner = (*Reader)(nil)
+	_ io.RuneScanner = (*Reader)(nil)
+)
 
-// ErrRead helps to trace error origin.
+// ErrRead helps to trace error origin: errors returned as a ReadError match it with errors.Is.
 var ErrRead = errors.New("utfbom: I/O error during BOM processing")
 
+// ErrNotSeeker is returned by Reader.Seek when the wrapped reader does not implement io.Seeker.
+var ErrNotSeeker = errors.New("utfbom: underlying reader does not implement io.Seeker")
+
+// ErrNegativeOffset is returned by Reader.Seek and ReaderAt.ReadAt for offsets before the start of the payload.
+var ErrNegativeOffset = errors.New("utfbom: negative offset")
+
+// ErrSeekUnsupported is returned by Reader.Seek for io.SeekCurrent under ScrubInterior.
+var ErrSeekUnsupported = errors.New("utfbom: seek is not supported")
+
+// ErrPayloadConsumed is returned by Reader.UnreadBOM once any payload has been read or peeked at.
+var ErrPayloadConsumed = errors.New("utfbom: payload has already been read or peeked at")
+
+// ErrBOMForbidden is returned under the ForbidBOM policy when a BOM is present.
+var ErrBOMForbidden = errors.New("utfbom: BOM is forbidden")
+
+// ErrBOMExpected is returned under the ExpectBOM policy when the expected BOM is missing.
+var ErrBOMExpected = errors.New("utfbom: BOM is expected")
+
 const maxBOMLen = 4
 
 // Encoding is a character encoding standard.
@@ -48,8 +72,52 @@ const (
 	// UTF32LittleEndian represents UTF-32 encoding with little-endian byte order.
 	// Its Byte Order Mark (BOM) is 0xff 0xfe 0x00 0x00.
 	UTF32LittleEndian
+
+	// UTF7 represents UTF-7 encoding.
+	// Its Byte Order Mark (BOM) is 0x2b 0x2f 0x76 followed by one of 0x38, 0x39, 0x2b or 0x2f.
+	// The BOM is the start of a base64 run, so its last byte may carry bits of the next character,
+	// and a trimmed UTF-7 payload may not decode correctly; prefer keeping the BOM with Passthrough.
+	// Bytes returns 0x2b 0x2f 0x76 0x38.
+	UTF7
+
+	// UTF1 represents UTF-1 encoding.
+	// Its Byte Order Mark (BOM) is 0xf7 0x64 0x4c.
+	UTF1
+
+	// UTFEBCDIC represents UTF-EBCDIC encoding.
+	// Its Byte Order Mark (BOM) is 0xdd 0x73 0x66 0x73.
+	UTFEBCDIC
+
+	// SCSU represents the Standard Compression Scheme for Unicode.
+	// Its Byte Order Mark (BOM) is 0x0e 0xfe 0xff.
+	SCSU
+
+	// BOCU1 represents BOCU-1 encoding.
+	// Its Byte Order Mark (BOM) is 0xfb 0xee 0x28.
+	BOCU1
+
+	// GB18030 represents GB 18030 encoding.
+	// Its Byte Order Mark (BOM) is 0x84 0x31 0x95 0x33.
+	GB18030
 )
 
+// builtinOrder lists the built-in encodings having a BOM, longer BOMs first.
+// A BOM may start with a shorter one, as UTF-32 Little Endian starts with UTF-16 Little Endian,
+// so the longer BOM is tried first to be detected at all.
+var builtinOrder = []Encoding{
+	UTF32BigEndian,
+	UTF32LittleEndian,
+	UTF7,
+	UTFEBCDIC,
+	GB18030,
+	UTF8,
+	UTF1,
+	SCSU,
+	BOCU1,
+	UTF16BigEndian,
+	UTF16LittleEndian,
+}
+
 // DetectEncoding inspects the initial bytes of a string or byte slice (T)
 // and returns the detected text encoding based on the presence of known BOMs (Byte Order Marks).
 // If no known BOM is found, it returns Unknown.
@@ -60,40 +128,103 @@ const (
 //   - UTF-16 Little Endian (BOM: 0xff 0xfe)
 //   - UTF-32 Big Endian (BOM: 0x00 0x00 0xfe 0xff)
 //   - UTF-32 Little Endian (BOM: 0xff 0xfe 0x00 0x00)
+//
+// Signatures added with Register are detected as well.
+// The signatures of UTF-7 and the other rare encodings are not, as plain ASCII and base64 text
+// may start with them; see DetectEncodingExtended.
+//
+// The UTF-32 Little Endian BOM starts with the UTF-16 Little Endian one,
+// so 0xff 0xfe 0x00 0x00 is ambiguous: it may also be UTF-16 Little Endian text starting with U+0000.
+// DetectEncoding resolves the tie in favor of the longer BOM, UTF-32 Little Endian.
+// Use DetectEncodingPreferring or DetectAll to resolve it differently.
 func DetectEncoding[T ~string | ~[]byte](input T) Encoding {
-	if len(input) > maxBOMLen {
-		input = input[:maxBOMLen]
-	}
+	return detectEncoding(input, false)
+}
 
-	b := []byte(input)
+// DetectEncodingExtended is like DetectEncoding, but also detects the signatures of these encodings:
+//   - UTF-7 (BOM: 0x2b 0x2f 0x76 followed by 0x38, 0x39, 0x2b or 0x2f)
+//   - UTF-1 (BOM: 0xf7 0x64 0x4c)
+//   - UTF-EBCDIC (BOM: 0xdd 0x73 0x66 0x73)
+//   - SCSU (BOM: 0x0e 0xfe 0xff)
+//   - BOCU-1 (BOM: 0xfb 0xee 0x28)
+//   - GB 18030 (BOM: 0x84 0x31 0x95 0x33)
+//
+// The UTF-7 signature, "+/v8" and the like, is also the beginning of plenty of base64 text,
+// so only use it for input known to be text in one of these encodings.
+// Readers and writers detect them with WithExtendedBOMs.
+func DetectEncodingExtended[T ~string | ~[]byte](input T) Encoding {
+	return detectEncoding(input, true)
+}
 
-	if len(b) < 2 {
+// detectEncoding is DetectEncoding, including the extended signatures with extended.
+func detectEncoding[T ~string | ~[]byte](input T, extended bool) Encoding {
+	r := current()
+	if len(input) == 0 || !r.lead[input[0]] {
 		return Unknown
 	}
 
-	if len(b) >= 3 && bytes.HasPrefix(b, []byte{0xef, 0xbb, 0xbf}) {
-		return UTF8
+	for _, e := range r.order {
+		if (extended || !e.isExtended()) && hasBOM(input, e) {
+			return e
+		}
 	}
 
-	if len(b) >= 4 {
-		if bytes.HasPrefix(b, []byte{0x00, 0x00, 0xfe, 0xff}) {
-			return UTF32BigEndian
-		}
+	return Unknown
+}
 
-		if bytes.HasPrefix(b, []byte{0xff, 0xfe, 0x00, 0x00}) {
-			return UTF32LittleEndian
-		}
+// DetectEncodingPreferring is like DetectEncoding,
+// but returns preferred whenever input starts with its BOM,
+// even if input also starts with the longer BOM of another encoding.
+// For example, DetectEncodingPreferring(input, UTF16LittleEndian)
+// reports 0xff 0xfe 0x00 0x00 as UTF-16 Little Endian.
+func DetectEncodingPreferring[T ~string | ~[]byte](input T, preferred Encoding) Encoding {
+	return detectPreferring(input, preferred, false)
+}
+
+// detectPreferring is DetectEncodingPreferring, including the extended signatures with extended.
+func detectPreferring[T ~string | ~[]byte](input T, preferred Encoding, extended bool) Encoding {
+	if hasBOM(input, preferred) {
+		return preferred
 	}
 
-	if bytes.HasPrefix(b, []byte{0xfe, 0xff}) {
-		return UTF16BigEndian
+	return detectEncoding(input, extended)
+}
+
+// isExtended reports whether e is one of the encodings only DetectEncodingExtended detects.
+func (e Encoding) isExtended() bool {
+	switch e {
+	case UTF7, UTF1, UTFEBCDIC, SCSU, BOCU1, GB18030:
+		return true
+	default:
+		return false
 	}
+}
 
-	if bytes.HasPrefix(b, []byte{0xff, 0xfe}) {
-		return UTF16LittleEndian
+// hasBOM reports whether b starts with the BOM of e.
+// It inspects at most the BOM length of b and never copies it.
+func hasBOM[T ~string | ~[]byte](b T, e Encoding) bool {
+	if e == UTF7 {
+		return len(b) >= 4 && string(b[:3]) == "\x2b\x2f\x76" && strings.IndexByte("89+/", b[3]) >= 0
 	}
 
-	return Unknown
+	bom := e.bom()
+
+	return bom != "" && len(b) >= len(bom) && string(b[:len(bom)]) == bom
+}
+
+// isPartialBOM reports whether b is a proper prefix of some known BOM,
+// meaning more bytes are required before the encoding can be detected.
+// The extended signatures count as well, so that they can be detected when asked for,
+// at the price of holding back a few more bytes otherwise.
+func isPartialBOM(b []byte) bool {
+	for _, e := range detectionOrder() {
+		bom := e.bom()
+		if len(b) < len(bom) && bom[:len(b)] == string(b) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // AnyOf reports whether the Encoding value equals any of the given Encoding values.
@@ -115,7 +246,23 @@ func (e Encoding) String() string {
 		return "UTF32BigEndian"
 	case UTF32LittleEndian:
 		return "UTF32LittleEndian"
+	case UTF7:
+		return "UTF7"
+	case UTF1:
+		return "UTF1"
+	case UTFEBCDIC:
+		return "UTFEBCDIC"
+	case SCSU:
+		return "SCSU"
+	case BOCU1:
+		return "BOCU1"
+	case GB18030:
+		return "GB18030"
 	default:
+		if sig, ok := lookup(e); ok {
+			return sig.name
+		}
+
 		return "Unknown"
 	}
 }
@@ -124,36 +271,75 @@ func (e Encoding) String() string {
 func (e Encoding) Len() int {
 	switch e {
 	default:
-		return 0
-	case UTF8:
+		sig, _ := lookup(e)
+
+		return len(sig.bom)
+	case UTF8, UTF1, SCSU, BOCU1:
 		return 3
 	case UTF16BigEndian, UTF16LittleEndian:
 		return 2
-	case UTF32BigEndian, UTF32LittleEndian:
+	case UTF32BigEndian, UTF32LittleEndian, UTF7, UTFEBCDIC, GB18030:
 		return 4
 	}
 }
 
 // Bytes returns encoding bytes.
 func (e Encoding) Bytes() []byte {
+	bom := e.bom()
+	if bom == "" {
+		return nil
+	}
+
+	return []byte(bom)
+}
+
+// bom returns the BOM of e without allocating.
+func (e Encoding) bom() string {
+	if bom := builtinBOM(e); bom != "" {
+		return bom
+	}
+
+	sig, _ := lookup(e)
+
+	return sig.bom
+}
+
+// builtinBOM returns the BOM of a built-in encoding.
+func builtinBOM(e Encoding) string {
 	switch e {
 	default:
-		return nil
+		return ""
 	case UTF8:
-		return []byte{0xef, 0xbb, 0xbf}
+		return "\xef\xbb\xbf"
 	case UTF16BigEndian:
-		return []byte{0xfe, 0xff}
+		return "\xfe\xff"
 	case UTF16LittleEndian:
-		return []byte{0xff, 0xfe}
+		return "\xff\xfe"
 	case UTF32BigEndian:
-		return []byte{0x00, 0x00, 0xfe, 0xff}
+		return "\x00\x00\xfe\xff"
 	case UTF32LittleEndian:
-		return []byte{0xff, 0xfe, 0x00, 0x00}
+		return "\xff\xfe\x00\x00"
+	case UTF7:
+		return "\x2b\x2f\x76\x38"
+	case UTF1:
+		return "\xf7\x64\x4c"
+	case UTFEBCDIC:
+		return "\xdd\x73\x66\x73"
+	case SCSU:
+		return "\x0e\xfe\xff"
+	case BOCU1:
+		return "\xfb\xee\x28"
+	case GB18030:
+		return "\x84\x31\x95\x33"
 	}
 }
 
 // Trim removes the BOM prefix from the input.
 // Supports string or []byte inputs and returns the same type without the BOM.
+//
+// Trim slices the input and never copies it, so it runs in constant time for inputs of any size.
+// For []byte inputs the result shares the backing array with input:
+// modifying one modifies the other, and appending to the result may overwrite input past its end.
 func Trim[T ~string | ~[]byte](input T) (T, Encoding) {
 	enc := DetectEncoding(input)
 
@@ -164,9 +350,26 @@ func Trim[T ~string | ~[]byte](input T) (T, Encoding) {
 	return input[enc.Len():], enc
 }
 
+// Cut removes the BOM prefix from the input, same as Trim,
+// and reports whether a BOM was found, in the style of strings.CutPrefix.
+func Cut[T ~string | ~[]byte](input T) (T, Encoding, bool) {
+	rest, enc := Trim(input)
+
+	return rest, enc, enc != Unknown
+}
+
+// Has reports whether the input starts with a BOM.
+// It neither allocates nor copies the input; most inputs without a BOM
+// are rejected by looking at the first byte only.
+func Has[T ~string | ~[]byte](input T) bool {
+	return DetectEncoding(input) != Unknown
+}
+
 // Prepend adds the corresponding Byte Order Mark (BOM) for a given encoding
 // to the beginning of a string or byte slice.
 // The input is returned unmodified if enc is Unknown or if the input already has any BOM.
+// Otherwise the result never shares memory with input and is allocated exactly once,
+// except for named string types which take one more allocation.
 func Prepend[T ~string | ~[]byte](input T, enc Encoding) T {
 	if enc == Unknown {
 		return input
@@ -176,27 +379,81 @@ func Prepend[T ~string | ~[]byte](input T, enc Encoding) T {
 		return input
 	}
 
-	return T(append(enc.Bytes(), []byte(input)...))
+	switch v := any(input).(type) {
+	case string:
+		return any(enc.bom() + v).(T)
+	case []byte:
+		return any(Append(make([]byte, 0, enc.Len()+len(v)), enc, v)).(T)
+	default:
+		// named string and byte slice types
+		return T(Append(make([]byte, 0, enc.Len()+len(input)), enc, []byte(input)))
+	}
+}
+
+// Append appends the Byte Order Mark (BOM) of enc followed by payload to dst
+// and returns the extended buffer, in the style of strconv.AppendInt and friends,
+// so callers can reuse buffers.
+// Same as Prepend, the BOM is not appended if enc is Unknown or if payload already has any BOM.
+func Append(dst []byte, enc Encoding, payload []byte) []byte {
+	if DetectEncoding(payload) == Unknown {
+		dst = append(dst, enc.bom()...)
+	}
+
+	return append(dst, payload...)
 }
 
 // Reader implements automatic BOM (Unicode Byte Order Mark) checking and
 // removing as necessary for an io.Reader object.
 //
+// Only the few bytes needed for detection are buffered,
+// the rest of the payload is read straight from the wrapped reader.
+// If the wrapped reader can peek, such as *bufio.Reader, nothing is buffered at all:
+// the BOM is peeked at and discarded right in the wrapped reader.
+// Detection stops reading as soon as the bytes read can't start a BOM,
+// so a slow stream starting with, say, '{' is passed on after its first byte arrives.
+//
 // Reader is not safe for concurrent use.
 type Reader struct {
-	rd   *bufio.Reader
-	once sync.Once
+	rd       io.Reader
+	buf      [maxBOMLen]byte  // bytes read during detection
+	r, w     int              // buf[r:w] is not returned to the caller yet
+	bom      [maxBOMLen]byte  // bom[:bomLen] is the removed BOM
+	bomLen   int              // 0 if no BOM was removed
+	detected bool             // detect has been called
+	consumed bool             // some payload has been returned to the caller
+	unread   bool             // the BOM has been pushed back by UnreadBOM
+	reported bool             // an error has been passed to the hooks set by WithOnError
+	scrubber interiorScrubber // removes interior U+FEFF from the payload, see ScrubInterior
+	valid    utf8Validator    // checks UTF-8 payloads, see WithValidateUTF8
+	br       *bufio.Reader    // buffers the payload once Peek or Discard is used
+	opts     options
+	err      error // error of BOM detection
 	// Enc will be available after first read
 	Enc Encoding
 }
 
 // NewReader wraps an incoming reader.
 // Passing a nil reader will cause a panic on the first Read call.
-func NewReader(rd io.Reader) *Reader {
-	return &Reader{
-		rd:   bufio.NewReader(rd),
-		once: sync.Once{},
-		Enc:  Unknown,
+func NewReader(rd io.Reader, opts ...Option) *Reader {
+	r := &Reader{
+		rd:  rd,
+		Enc: Unknown,
+	}
+
+	r.opts.set(opts)
+
+	return r
+}
+
+// Reset discards any state and makes r read from rd, mirroring bufio.Reader.Reset.
+// The options given to NewReader are kept, as well as the buffer used by ScrubInterior,
+// so Readers can be kept in a sync.Pool instead of allocating one per stream.
+func (r *Reader) Reset(rd io.Reader) {
+	*r = Reader{
+		rd:       rd,
+		scrubber: interiorScrubber{buf: r.scrubber.buf},
+		opts:     r.opts,
+		Enc:      Unknown,
 	}
 }
 
@@ -204,34 +461,551 @@ func NewReader(rd io.Reader) *Reader {
 // On the first call, it detects and removes any Byte Order Mark (BOM).
 // Subsequent calls delegate directly to the underlying Reader.
 func (r *Reader) Read(buf []byte) (int, error) {
+	n, err := r.read(buf)
+	r.consumed = r.consumed || n != 0
+
+	if err != nil {
+		r.failed(err)
+	}
+
+	return n, err
+}
+
+// failed passes the first error other than io.EOF to the hooks set by WithOnError, if any.
+func (r *Reader) failed(err error) {
+	if r.opts.onError == nil || r.reported || errors.Is(err, io.EOF) {
+		return
+	}
+
+	r.reported = true
+	r.opts.onError(err)
+}
+
+func (r *Reader) read(buf []byte) (int, error) {
 	if len(buf) == 0 {
 		return 0, nil
 	}
 
-	var bomErr error
+	p := buf
+	if r.opts.scrub || r.opts.validate {
+		// the payload may have to go through the scrubber or the validator, so it is not read straight into buf
+		p = nil
+	}
+
+	n, err := r.detect(p)
+	if err != nil || n != 0 {
+		return n, err
+	}
+
+	if r.br != nil {
+		return r.br.Read(buf)
+	}
 
-	r.once.Do(func() {
-		b, err := r.rd.Peek(maxBOMLen)
-		// do not error out in case underlying payload is too small
-		// still attempt to read fewer than n bytes.
-		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
-			bomErr = errors.Join(ErrRead, err)
+	if r.scrubs() {
+		return r.scrub().Read(buf)
+	}
+
+	if r.validates() {
+		return r.validator().Read(buf)
+	}
 
-			return
+	return r.readPayload(buf)
+}
+
+// readPayload reads the payload left after detection.
+func (r *Reader) readPayload(buf []byte) (int, error) {
+	if r.r < r.w {
+		n := copy(buf, r.buf[r.r:r.w])
+		r.r += n
+
+		return n, nil
+	}
+
+	return r.rd.Read(buf)
+}
+
+// WriteTo implements the io.WriterTo interface, so io.Copy avoids an intermediate buffer.
+// It detects and removes any Byte Order Mark (BOM) unless that is already done
+// and writes the rest of the payload to w.
+func (r *Reader) WriteTo(w io.Writer) (int64, error) {
+	n, err := r.writeTo(w)
+	r.consumed = r.consumed || n != 0
+
+	if err != nil {
+		r.failed(err)
+	}
+
+	return n, err
+}
+
+func (r *Reader) writeTo(w io.Writer) (int64, error) {
+	_, err := r.detect(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.br != nil {
+		return r.br.WriteTo(w)
+	}
+
+	if r.scrubs() {
+		return io.Copy(w, r.scrub())
+	}
+
+	if r.validates() {
+		return io.CopyBuffer(w, r.validator(), r.opts.buf)
+	}
+
+	var written int64
+
+	if r.r < r.w {
+		n, err := w.Write(r.buf[r.r:r.w])
+		r.r += n
+		written = int64(n)
+
+		if err != nil {
+			return written, err
 		}
+	}
+
+	n, err := io.CopyBuffer(w, r.rd, r.opts.buf)
+
+	return written + n, err
+}
+
+// Seek implements the io.Seeker interface when the wrapped reader implements it,
+// otherwise it fails with ErrNotSeeker.
+// Offsets are adjusted by the length of the removed Byte Order Mark (BOM),
+// so Seek(0, io.SeekStart) lands right after the BOM and the BOM is never read again.
+// With ScrubInterior, offsets still count the interior U+FEFF characters, as they are in the wrapped reader,
+// and io.SeekCurrent is not supported, as the scrubber reads ahead.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	sk, ok := r.rd.(io.Seeker)
+	if !ok {
+		return 0, ErrNotSeeker
+	}
 
-		r.Enc = DetectEncoding(b)
-		if r.Enc != Unknown {
-			_, err = r.rd.Discard(r.Enc.Len())
-			if err != nil {
-				bomErr = errors.Join(ErrRead, err)
-			}
+	_, err := r.detect(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if r.scrubs() && whence == io.SeekCurrent {
+		return 0, fmt.Errorf("%w: io.SeekCurrent with ScrubInterior", ErrSeekUnsupported)
+	}
+
+	skip := int64(r.bomLen)
+
+	switch whence {
+	case io.SeekStart:
+		if offset < 0 {
+			return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, offset)
+		}
+
+		offset += skip
+	case io.SeekCurrent:
+		// the wrapped reader is ahead by the bytes not returned to the caller yet
+		offset -= int64(r.w - r.r)
+
+		if r.br != nil {
+			offset -= int64(r.br.Buffered())
 		}
-	})
 
-	if bomErr != nil {
-		return 0, bomErr
+		if r.validates() {
+			offset -= int64(r.valid.w)
+		}
 	}
 
-	return r.rd.Read(buf)
+	pos, err := sk.Seek(offset, whence)
+	if err != nil {
+		return 0, newReadError("seek", offset, err)
+	}
+
+	r.r, r.w = 0, 0
+	r.consumed, r.unread = pos != skip, false
+	r.br = nil
+
+	if r.validates() {
+		r.valid = utf8Validator{rd: payloadReader{r}, off: pos}
+	}
+
+	if r.scrubs() {
+		r.scrubber = interiorScrubber{buf: r.scrubber.buf}
+
+		// a leading U+FEFF kept by the policy is kept again only if the stream is read from the start
+		s := r.scrub()
+		s.keepLeading = s.keepLeading && pos == 0
+	}
+
+	if pos < skip {
+		_, err = sk.Seek(skip, io.SeekStart)
+		if err != nil {
+			return 0, newReadError("seek", skip, err)
+		}
+
+		return 0, fmt.Errorf("%w: %d", ErrNegativeOffset, pos-skip)
+	}
+
+	return pos - skip, nil
+}
+
+// UnreadBOM pushes the Byte Order Mark (BOM) removed from the stream back into it,
+// so subsequent reads return the original bytes, BOM included, for instance to a parser
+// doing its own BOM handling after the encoding has been checked with Encoding.
+// Enc and BOM keep reporting the detected BOM.
+//
+// UnreadBOM detects and removes the BOM first unless that is already done.
+// It does nothing if no BOM was removed or it was already pushed back,
+// and fails once any payload has been read or peeked at, since the BOM belongs in front of the whole payload.
+// Seek discards a pushed back BOM.
+func (r *Reader) UnreadBOM() error {
+	_, err := r.detect(nil)
+	if err != nil {
+		return err
+	}
+
+	if r.consumed || r.br != nil {
+		return fmt.Errorf("%w: cannot unread the BOM", ErrPayloadConsumed)
+	}
+
+	if r.bomLen == 0 || r.unread {
+		return nil
+	}
+
+	// no payload has been read, so the BOM and the bytes read after it fit buf
+	pending := r.w - r.r
+	copy(r.buf[r.bomLen:], r.buf[r.r:r.w])
+	copy(r.buf[:], r.bom[:r.bomLen])
+	r.r, r.w = 0, r.bomLen+pending
+	r.unread = true
+
+	// the scrubber has returned nothing yet, it is set up again to keep the leading U+FEFF,
+	// the validator is set up again to check the BOM as well
+	r.scrubber = interiorScrubber{buf: r.scrubber.buf}
+	r.valid = utf8Validator{}
+
+	return nil
+}
+
+// Peek returns the next n payload bytes without advancing the reader, see bufio.Reader.Peek.
+// It detects and removes any Byte Order Mark (BOM) unless that is already done,
+// so format sniffers see the payload only.
+//
+// On the first call of Peek, Discard, ReadByte or ReadRune, Reader starts buffering the payload in a bufio.Reader
+// of the default size, which limits n, unless the wrapped reader is a *bufio.Reader
+// that can be peeked at directly.
+func (r *Reader) Peek(n int) ([]byte, error) {
+	br, err := r.buffered()
+	if err != nil {
+		return nil, err
+	}
+
+	return br.Peek(n)
+}
+
+// Discard skips the next n payload bytes, returning the number of bytes discarded,
+// see bufio.Reader.Discard. It detects and removes any Byte Order Mark (BOM) unless that is already done.
+func (r *Reader) Discard(n int) (int, error) {
+	br, err := r.buffered()
+	if err != nil {
+		return 0, err
+	}
+
+	discarded, err := br.Discard(n)
+	r.consumed = r.consumed || discarded != 0
+
+	return discarded, err
+}
+
+// ReadByte implements the io.ByteReader interface, so Reader can be passed to APIs
+// reading byte by byte, such as binary.ReadUvarint or compress/flate, without another buffering layer.
+// It starts buffering the payload the same way as Peek.
+func (r *Reader) ReadByte() (byte, error) {
+	br, err := r.buffered()
+	if err != nil {
+		return 0, err
+	}
+
+	c, err := br.ReadByte()
+	r.consumed = r.consumed || err == nil
+
+	return c, err
+}
+
+// UnreadByte implements the io.ByteScanner interface, see bufio.Reader.UnreadByte.
+func (r *Reader) UnreadByte() error {
+	br, err := r.buffered()
+	if err != nil {
+		return err
+	}
+
+	return br.UnreadByte()
+}
+
+// ReadRune implements the io.RuneReader interface for UTF-8 payloads and payloads without a BOM,
+// see bufio.Reader.ReadRune. It starts buffering the payload the same way as Peek.
+// For payloads of other encodings it fails with ErrUnsupportedEncoding, use RuneReader to read
+// UTF-16 and UTF-32 payloads rune by rune.
+func (r *Reader) ReadRune() (rune, int, error) {
+	br, err := r.buffered()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !r.Enc.AnyOf(Unknown, UTF8) {
+		return 0, 0, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, r.Enc)
+	}
+
+	c, size, err := br.ReadRune()
+	r.consumed = r.consumed || err == nil
+
+	return c, size, err
+}
+
+// UnreadRune implements the io.RuneScanner interface, see bufio.Reader.UnreadRune.
+func (r *Reader) UnreadRune() error {
+	br, err := r.buffered()
+	if err != nil {
+		return err
+	}
+
+	return br.UnreadRune()
+}
+
+// buffered returns the bufio.Reader buffering the payload, setting it up on the first call.
+func (r *Reader) buffered() (*bufio.Reader, error) {
+	_, err := r.detect(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.br != nil {
+		return r.br, nil
+	}
+
+	br, ok := r.rd.(*bufio.Reader)
+
+	switch {
+	case ok && r.r == r.w && !r.scrubs() && !r.validates():
+		// the payload is read straight from br, which buffers it already
+		r.br = br
+	case r.scrubs():
+		r.br = bufio.NewReader(r.scrub())
+	case r.validates():
+		r.br = bufio.NewReader(r.validator())
+	default:
+		r.br = bufio.NewReader(payloadReader{r})
+	}
+
+	return r.br, nil
+}
+
+// Unwrap returns the reader wrapped by r, so callers can discover its capabilities,
+// the same way http.ResponseController does for response writers.
+// Reading from it directly bypasses r and breaks the BOM handling of r.
+func (r *Reader) Unwrap() io.Reader {
+	return r.rd
+}
+
+// Encoding detects and removes any Byte Order Mark (BOM) unless that is already done
+// and returns the detected encoding, so callers can branch on it before reading any payload.
+// A detection error is reported by every call, as well as by every Read.
+func (r *Reader) Encoding() (Encoding, error) {
+	_, err := r.detect(nil)
+
+	return r.Enc, err
+}
+
+// BOM returns a copy of the Byte Order Mark (BOM) bytes removed from the stream,
+// or nil if none were removed, either because there was no BOM or the policy kept it.
+// Unlike Enc.Bytes, it returns the exact bytes read, which matters for UTF-7,
+// whose BOM has several variants.
+// Same as Enc, it is available after the first Read or Encoding call.
+func (r *Reader) BOM() []byte {
+	if r.bomLen == 0 {
+		return nil
+	}
+
+	return slices.Clone(r.bom[:r.bomLen])
+}
+
+// peeker is implemented by buffered readers such as *bufio.Reader.
+type peeker interface {
+	Peek(n int) ([]byte, error)
+	Discard(n int) (int, error)
+}
+
+// detect reads the beginning of the stream, sets Enc and skips the BOM.
+// Only the first call does the work, the error is sticky.
+//
+// If p fits the BOM, the stream is read straight into p, so the first Read returns
+// as much payload as the first read of the underlying reader provides,
+// and detect returns the number of payload bytes left in p.
+// Otherwise the beginning of the stream is kept in buf.
+func (r *Reader) detect(p []byte) (int, error) {
+	if r.detected {
+		return 0, r.err
+	}
+
+	r.detected = true
+
+	// a peeker may wait for more bytes than its first read returns
+	if pk, ok := r.rd.(peeker); ok && !r.opts.lazy {
+		r.err = r.detectPeeker(pk)
+
+		return 0, r.err
+	}
+
+	head := r.buf[:]
+	if len(p) >= len(head) {
+		head = p
+	}
+
+	m, err := readHead(r.rd, head, r.opts.lazy)
+	// do not error out in case underlying payload is too small
+	// still attempt to read fewer than n bytes.
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.err = newReadError("detect", int64(m), err)
+
+		return 0, r.err
+	}
+
+	r.Enc = detectPreferring(head[:m], r.opts.prefer, r.opts.extended)
+	r.opts.detected(r.Enc)
+
+	r.err = r.opts.check(r.Enc)
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	var skip int
+	if r.opts.strips(r.Enc) {
+		skip = r.Enc.Len()
+		r.bomLen = copy(r.bom[:], head[:skip])
+	}
+
+	if len(p) < len(r.buf) {
+		r.r, r.w = skip, m
+
+		return 0, nil
+	}
+
+	return copy(p, p[skip:m]), nil
+}
+
+// detectPeeker is detect for wrapped readers that can peek, no bytes are copied into buf.
+func (r *Reader) detectPeeker(pk peeker) error {
+	b, err := peekHead(pk)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return newReadError("detect", int64(len(b)), err)
+	}
+
+	r.Enc = detectPreferring(b, r.opts.prefer, r.opts.extended)
+	r.opts.detected(r.Enc)
+
+	err = r.opts.check(r.Enc)
+	if err != nil {
+		return err
+	}
+
+	if r.opts.strips(r.Enc) {
+		r.bomLen = copy(r.bom[:], b[:r.Enc.Len()])
+
+		_, err = pk.Discard(r.Enc.Len())
+		if err != nil {
+			return newReadError("discard", 0, err)
+		}
+	}
+
+	return nil
+}
+
+// readHead reads the beginning of rd into head until the bytes read can't start a BOM anymore,
+// so detection never waits for bytes it doesn't need: a stream starting with '{' is resolved
+// after its first byte, while 0xff 0xfe waits for two more bytes telling UTF-16LE from UTF-32LE.
+// With once set, reading stops after the first read returning any bytes.
+// It returns the number of bytes read and the error that stopped reading, if any.
+func readHead(rd io.Reader, head []byte, once bool) (int, error) {
+	m := 0
+
+	for m < maxBOMLen && isPartialBOM(head[:m]) && (m == 0 || !once) {
+		n, err := rd.Read(head[m:])
+		m += n
+
+		if err != nil {
+			return m, err
+		}
+	}
+
+	return m, nil
+}
+
+// peekHead is readHead for readers that can peek, the bytes are left in pk.
+func peekHead(pk peeker) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	for len(b) < maxBOMLen && isPartialBOM(b) {
+		b, err = pk.Peek(len(b) + 1)
+		if err != nil {
+			return b, err
+		}
+	}
+
+	return b, nil
+}
+
+// Skip eagerly detects and consumes a Byte Order Mark (BOM) at the beginning of rd.
+// It returns a reader positioned right after the BOM together with the detected encoding,
+// so callers can branch on the encoding before reading any payload.
+func Skip(rd io.Reader) (io.Reader, Encoding, error) {
+	r := NewReader(rd)
+
+	enc, err := r.Encoding()
+
+	return r, enc, err
+}
+
+// DetectReader detects the Byte Order Mark (BOM) at the beginning of rd without consuming it.
+// It reads at most 4 bytes and returns the detected encoding together with a reader
+// that replays those bytes, BOM included, followed by the rest of rd.
+// Use it when the encoding is needed, but the BOM must be left intact for a downstream consumer.
+func DetectReader(rd io.Reader) (Encoding, io.Reader, error) {
+	r := NewReader(rd, Passthrough())
+
+	enc, err := r.Encoding()
+
+	return enc, r, err
+}
+
+var _ io.ReadCloser = (*ReadCloser)(nil)
+
+// ReadCloser is a Reader that also closes the wrapped io.ReadCloser.
+//
+// ReadCloser is not safe for concurrent use.
+type ReadCloser struct {
+	*Reader
+	closer io.Closer
+}
+
+// NewReadCloser wraps an incoming read closer, such as an *os.File or an HTTP body.
+// Passing a nil reader will cause a panic on the first Read or Close call.
+func NewReadCloser(rc io.ReadCloser, opts ...Option) *ReadCloser {
+	return &ReadCloser{
+		Reader: NewReader(rc, opts...),
+		closer: rc,
+	}
+}
+
+// Reset discards any state and makes r read from and close rc, see Reader.Reset.
+func (r *ReadCloser) Reset(rc io.ReadCloser) {
+	r.Reader.Reset(rc)
+	r.closer = rc
+}
+
+// Close implements the io.Closer interface by closing the wrapped reader.
+func (r *ReadCloser) Close() error {
+	return r.closer.Close()
 }
@@ -1,13 +1,16 @@
 package utfbom_test
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/binary"
 	"encoding/csv"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"testing"
 	"testing/iotest"
 
@@ -89,11 +92,63 @@ func TestDetectBom(t *testing.T) {
 			input:    []byte{0xff, 0xfe, 0x0, 0x0},
 			expected: utfbom.UTF32LittleEndian,
 		},
+		{
+			name:     "utf7_detected",
+			input:    []byte("+/v8-hey"),
+			expected: utfbom.UTF7,
+		},
+		{
+			name:     "utf7_detected_with_next_char_bits",
+			input:    []byte("+/v9AGgAZQB5-"),
+			expected: utfbom.UTF7,
+		},
+		{
+			name:     "utf7_invalid_fourth_byte",
+			input:    []byte("+/vA"),
+			expected: utfbom.Unknown,
+		},
+		{
+			name:     "utf7_truncated",
+			input:    []byte("+/v"),
+			expected: utfbom.Unknown,
+		},
+		{
+			name:     "utf1_detected",
+			input:    []byte{0xf7, 0x64, 0x4c, 'h'},
+			expected: utfbom.UTF1,
+		},
+		{
+			name:     "utf_ebcdic_detected",
+			input:    []byte{0xdd, 0x73, 0x66, 0x73, 0x88},
+			expected: utfbom.UTFEBCDIC,
+		},
+		{
+			name:     "scsu_detected",
+			input:    []byte{0x0e, 0xfe, 0xff, 'h'},
+			expected: utfbom.SCSU,
+		},
+		{
+			name:     "bocu1_detected",
+			input:    []byte{0xfb, 0xee, 0x28, 0xb8},
+			expected: utfbom.BOCU1,
+		},
+		{
+			name:     "gb18030_detected",
+			input:    []byte{0x84, 0x31, 0x95, 0x33, 'h'},
+			expected: utfbom.GB18030,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			be.Equal(t, utfbom.DetectEncoding(tc.input), tc.expected)
+			be.Equal(t, utfbom.DetectEncodingExtended(tc.input), tc.expected)
+
+			// the extended signatures are only detected when asked for
+			if tc.expected.AnyOf(utfbom.UTF7, utfbom.UTF1, utfbom.UTFEBCDIC, utfbom.SCSU, utfbom.BOCU1, utfbom.GB18030) {
+				be.Equal(t, utfbom.DetectEncoding(tc.input), utfbom.Unknown)
+			} else {
+				be.Equal(t, utfbom.DetectEncoding(tc.input), tc.expected)
+			}
 		})
 	}
 }
@@ -117,6 +172,33 @@ func ExampleDetectEncoding() {
 	// is UTF8:true
 }
 
+func TestDetectEncodingPreferring(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		input     []byte
+		preferred utfbom.Encoding
+		expected  utfbom.Encoding
+	}{
+		{"ambiguous_default", utf32LEBOM, utfbom.Unknown, utfbom.UTF32LittleEndian},
+		{"ambiguous_prefer_utf16le", utf32LEBOM, utfbom.UTF16LittleEndian, utfbom.UTF16LittleEndian},
+		{"ambiguous_prefer_utf32le", utf32LEBOM, utfbom.UTF32LittleEndian, utfbom.UTF32LittleEndian},
+		{"preferred_bom_missing", utf8BOM, utfbom.UTF16LittleEndian, utfbom.UTF8},
+		{"preferred_bom_truncated", utf8BOM[:2], utfbom.UTF8, utfbom.Unknown},
+		{"no_bom", []byte("hello"), utfbom.UTF16LittleEndian, utfbom.Unknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.DetectEncodingPreferring(tc.input, tc.preferred), tc.expected)
+			be.Equal(t, utfbom.DetectEncodingPreferring(string(tc.input), tc.preferred), tc.expected)
+		})
+	}
+}
+
 func ExampleTrim() {
 	input := "\ufeffhello"
 	fmt.Printf("input string: %q\n", input)
@@ -207,7 +289,7 @@ func ExampleReader() {
 func TestEncoding_String(t *testing.T) {
 	t.Parallel()
 
-	for e := utfbom.Unknown; e <= utfbom.UTF32LittleEndian; e++ {
+	for e := utfbom.Unknown; e <= utfbom.GB18030; e++ {
 		be.True(t, e.String() != "")
 	}
 
@@ -229,6 +311,12 @@ func TestEncoding_Len(t *testing.T) {
 		{"UTF16LittleEndian", utfbom.UTF16LittleEndian, 2},
 		{"UTF32BigEndian", utfbom.UTF32BigEndian, 4},
 		{"UTF32LittleEndian", utfbom.UTF32LittleEndian, 4},
+		{"UTF7", utfbom.UTF7, 4},
+		{"UTF1", utfbom.UTF1, 3},
+		{"UTFEBCDIC", utfbom.UTFEBCDIC, 4},
+		{"SCSU", utfbom.SCSU, 3},
+		{"BOCU1", utfbom.BOCU1, 3},
+		{"GB18030", utfbom.GB18030, 4},
 		{"InvalidEncoding", 999, 0},
 	}
 
@@ -261,6 +349,8 @@ func TestEncoding_Trim(t *testing.T) {
 		{"utf32_be", []byte{0x00, 0x00, 0xfe, 0xff, 0x00, 0x00, 0x00, 0x68}, utfbom.UTF32BigEndian, []byte{0x00, 0x00, 0x00, 0x68}},
 		{"utf32_le_empty", []byte{0xff, 0xfe, 0x00, 0x00}, utfbom.UTF32LittleEndian, []byte{}},
 		{"utf32_le", []byte{0xff, 0xfe, 0x00, 0x00, 0x68, 0x00, 0x65}, utfbom.UTF32LittleEndian, []byte{0x68, 0x00, 0x65}},
+		{"scsu_left_alone", []byte{0x0e, 0xfe, 0xff, 'h'}, utfbom.Unknown, []byte{0x0e, 0xfe, 0xff, 'h'}},
+		{"utf7_left_alone", []byte("+/v8AAAA"), utfbom.Unknown, []byte("+/v8AAAA")},
 	}
 
 	for _, tc := range testCases {
@@ -363,6 +453,119 @@ func TestReader_WrappeeReaderHasTinyPayload_OneByteBuffer(t *testing.T) {
 	be.Equal(t, 0, n)
 }
 
+// TestReader_ShortStreams tests that the very first Read of streams shorter than the longest BOM
+// returns data without an error.
+func TestReader_ShortStreams(t *testing.T) {
+	t.Parallel()
+
+	wrappers := map[string]func(io.Reader) io.Reader{
+		"plain":    func(rd io.Reader) io.Reader { return rd },
+		"one_byte": iotest.OneByteReader,
+		"data_err": iotest.DataErrReader,
+		"bufio":    func(rd io.Reader) io.Reader { return bufio.NewReader(rd) },
+	}
+
+	inputs := []struct {
+		input    string
+		expected string
+	}{
+		{"x", "x"},
+		{"xy", "xy"},
+		{"xyz", "xyz"},
+		{"\xef", "\xef"},
+		{"\xef\xbb", "\xef\xbb"},
+		{"\xff\xfe\x00", "\x00"},
+	}
+
+	for name, wrap := range wrappers {
+		for _, in := range inputs {
+			for _, size := range []int{1, 3, 4, 512} {
+				t.Run(fmt.Sprintf("%s/%q/%d", name, in.input, size), func(t *testing.T) {
+					t.Parallel()
+
+					rd := utfbom.NewReader(wrap(strings.NewReader(in.input)))
+					buf := make([]byte, size)
+
+					n, err := rd.Read(buf)
+					be.Err(t, err, nil)
+					be.True(t, n > 0)
+
+					rest, err := io.ReadAll(rd)
+					be.Err(t, err, nil)
+					be.Equal(t, string(buf[:n])+string(rest), in.expected)
+				})
+			}
+		}
+	}
+}
+
+// dribbleReader returns its chunks one per Read and fails the test when read past them,
+// like a network stream whose next bytes have not arrived yet.
+type dribbleReader struct {
+	t      *testing.T
+	chunks []string
+}
+
+func (d *dribbleReader) Read(p []byte) (int, error) {
+	if len(d.chunks) == 0 {
+		d.t.Error("read past the available chunks")
+
+		return 0, io.EOF
+	}
+
+	n := copy(p, d.chunks[0])
+	d.chunks = d.chunks[1:]
+
+	return n, nil
+}
+
+func TestReader_IncrementalDetection(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		chunks   []string
+		expected string
+		enc      utfbom.Encoding
+	}{
+		{"not_a_bom", []string{"{"}, "{", utfbom.Unknown},
+		{"not_a_bom_after_two_bytes", []string{"\xef", "x"}, "\xefx", utfbom.Unknown},
+		{"utf8", []string{"\xef", "\xbb", "\xbf"}, "", utfbom.UTF8},
+		{"utf8_payload", []string{"\xef\xbb", "\xbfx"}, "x", utfbom.UTF8},
+		{"utf16le_or_utf32le", []string{"\xff\xfe", "x"}, "x", utfbom.UTF16LittleEndian},
+		{"utf32le", []string{"\xff", "\xfe", "\x00", "\x00"}, "", utfbom.UTF32LittleEndian},
+	}
+
+	wrappers := map[string]func(io.Reader) io.Reader{
+		"plain": func(rd io.Reader) io.Reader { return rd },
+		"bufio": func(rd io.Reader) io.Reader { return bufio.NewReader(rd) },
+	}
+
+	for _, tc := range testCases {
+		for name, wrap := range wrappers {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				t.Parallel()
+
+				rd := utfbom.NewReader(wrap(&dribbleReader{t: t, chunks: tc.chunks}))
+
+				enc, err := rd.Encoding()
+				be.Err(t, err, nil)
+				be.Equal(t, enc, tc.enc)
+
+				if tc.expected == "" {
+					return
+				}
+
+				buf := make([]byte, 512)
+
+				n, err := rd.Read(buf)
+				be.Err(t, err, nil)
+				be.Equal(t, string(buf[:n]), tc.expected)
+			})
+		}
+	}
+}
+
 func TestEncoding_Bytes(t *testing.T) {
 	t.Parallel()
 
@@ -401,6 +604,36 @@ func TestEncoding_Bytes(t *testing.T) {
 			enc:      utfbom.UTF32LittleEndian,
 			expected: []byte{0xff, 0xfe, 0x00, 0x00},
 		},
+		{
+			name:     "UTF7",
+			enc:      utfbom.UTF7,
+			expected: []byte{0x2b, 0x2f, 0x76, 0x38},
+		},
+		{
+			name:     "UTF1",
+			enc:      utfbom.UTF1,
+			expected: []byte{0xf7, 0x64, 0x4c},
+		},
+		{
+			name:     "UTFEBCDIC",
+			enc:      utfbom.UTFEBCDIC,
+			expected: []byte{0xdd, 0x73, 0x66, 0x73},
+		},
+		{
+			name:     "SCSU",
+			enc:      utfbom.SCSU,
+			expected: []byte{0x0e, 0xfe, 0xff},
+		},
+		{
+			name:     "BOCU1",
+			enc:      utfbom.BOCU1,
+			expected: []byte{0xfb, 0xee, 0x28},
+		},
+		{
+			name:     "GB18030",
+			enc:      utfbom.GB18030,
+			expected: []byte{0x84, 0x31, 0x95, 0x33},
+		},
 		{
 			name:     "InvalidEncoding",
 			enc:      utfbom.Encoding(999),
@@ -426,6 +659,12 @@ func TestBytes_NoAliasing(t *testing.T) {
 		utfbom.UTF16LittleEndian,
 		utfbom.UTF32BigEndian,
 		utfbom.UTF32LittleEndian,
+		utfbom.UTF7,
+		utfbom.UTF1,
+		utfbom.UTFEBCDIC,
+		utfbom.SCSU,
+		utfbom.BOCU1,
+		utfbom.GB18030,
 	}
 
 	for _, enc := range encodings {
@@ -444,6 +683,104 @@ func TestBytes_NoAliasing(t *testing.T) {
 	}
 }
 
+func TestCut(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input string
+		rest  string
+		enc   utfbom.Encoding
+		found bool
+	}{
+		{"empty", "", "", utfbom.Unknown, false},
+		{"no_bom", "hello", "hello", utfbom.Unknown, false},
+		{"only_bom", "\ufeff", "", utfbom.UTF8, true},
+		{"utf8", "\ufeffhello", "hello", utfbom.UTF8, true},
+		{"utf16le", "\xff\xfeh\x00", "h\x00", utfbom.UTF16LittleEndian, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rest, enc, found := utfbom.Cut(tc.input)
+			be.Equal(t, rest, tc.rest)
+			be.Equal(t, enc, tc.enc)
+			be.Equal(t, found, tc.found)
+
+			restBytes, enc, found := utfbom.Cut([]byte(tc.input))
+			be.Equal(t, string(restBytes), tc.rest)
+			be.Equal(t, enc, tc.enc)
+			be.Equal(t, found, tc.found)
+		})
+	}
+}
+
+func ExampleCut() {
+	for _, input := range []string{"\ufeffhello", "hello"} {
+		if rest, enc, found := utfbom.Cut(input); found {
+			fmt.Printf("%q had a %s BOM\n", rest, enc)
+		} else {
+			fmt.Printf("%q had no BOM\n", rest)
+		}
+	}
+
+	// output:
+	// "hello" had a UTF8 BOM
+	// "hello" had no BOM
+}
+
+func TestHas(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"empty", "", false},
+		{"no_bom", "hello", false},
+		{"incomplete_bom", "\xef\xbb", false},
+		{"bom_in_the_middle", "hello\ufeff", false},
+		{"utf8", "\ufeffhello", true},
+		{"utf16be", "\xfe\xff", true},
+		{"utf32le", "\xff\xfe\x00\x00", true},
+		{"extended_signature", "\x84\x31\x95\x33", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.Has(tc.input), tc.expected)
+			be.Equal(t, utfbom.Has([]byte(tc.input)), tc.expected)
+		})
+	}
+}
+
+func TestHas_Allocations(t *testing.T) {
+	inputs := []string{"hello", "\ufeffhello", "\xef\xbb"}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, input := range inputs {
+			_ = utfbom.Has(input)
+		}
+	})
+
+	be.Equal(t, allocs, 0.0)
+}
+
+func BenchmarkHas(b *testing.B) {
+	inputs := []string{"hello", "\ufeffhello", "{\"key\":\"value\"}"}
+
+	for b.Loop() {
+		for _, input := range inputs {
+			_ = utfbom.Has(input)
+		}
+	}
+}
+
 func TestPrepend(t *testing.T) {
 	t.Parallel()
 
@@ -489,6 +826,71 @@ func TestPrepend(t *testing.T) {
 	})
 }
 
+func TestPrepend_Allocations(t *testing.T) {
+	str := strings.Repeat("a", 100)
+	b := []byte(str)
+
+	be.Equal(t, testing.AllocsPerRun(100, func() { _ = utfbom.Prepend(str, utfbom.UTF8) }), 1.0)
+	be.Equal(t, testing.AllocsPerRun(100, func() { _ = utfbom.Prepend(b, utfbom.UTF8) }), 1.0)
+
+	// the result does not share memory with the input
+	out := utfbom.Prepend(b[:50], utfbom.UTF8)
+	out = append(out[:3], 'b')
+	be.Equal(t, out, append(utf8BOM, 'b'))
+	be.Equal(t, string(b), str)
+}
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		dst      []byte
+		enc      utfbom.Encoding
+		payload  []byte
+		expected []byte
+	}{
+		{"nil_dst", nil, utfbom.UTF8, []byte("hi"), append(utf8BOM, "hi"...)},
+		{"non_empty_dst", []byte("a,"), utfbom.UTF16BigEndian, []byte{0x00, 'h'}, []byte{'a', ',', 0xfe, 0xff, 0x00, 'h'}},
+		{"empty_payload", []byte("a"), utfbom.UTF8, nil, append([]byte("a"), utf8BOM...)},
+		{"unknown", []byte("a"), utfbom.Unknown, []byte("hi"), []byte("ahi")},
+		{"payload_with_bom", nil, utfbom.UTF8, append(utf16LEBOM, 'h', 0x00), append(utf16LEBOM, 'h', 0x00)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			be.Equal(t, utfbom.Append(tc.dst, tc.enc, tc.payload), tc.expected)
+		})
+	}
+}
+
+func TestAppend_ReusesBuffer(t *testing.T) {
+	payload := []byte("hello")
+	buf := make([]byte, 0, 64)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = utfbom.Append(buf[:0], utfbom.UTF8, payload)
+	})
+
+	be.Equal(t, allocs, 0.0)
+	be.Equal(t, buf, append(utf8BOM, "hello"...))
+}
+
+func ExampleAppend() {
+	buf := make([]byte, 0, 64)
+
+	for _, line := range []string{"first", "second"} {
+		buf = utfbom.Append(buf[:0], utfbom.UTF8, []byte(line))
+		fmt.Printf("%q\n", buf)
+	}
+
+	// output:
+	// "\ufefffirst"
+	// "\ufeffsecond"
+}
+
 type CustomString string
 
 type CustomBytes []byte
@@ -569,3 +971,924 @@ func TestNewReader_NilPanics(t *testing.T) {
 	buf := make([]byte, 10)
 	_, _ = rd.Read(buf)
 }
+
+func TestSkip(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input []byte
+		enc   utfbom.Encoding
+		rest  []byte
+	}{
+		{"empty", nil, utfbom.Unknown, []byte{}},
+		{"no_bom", []byte("hello"), utfbom.Unknown, []byte("hello")},
+		{"utf8", []byte("\ufeffhello"), utfbom.UTF8, []byte("hello")},
+		{"utf16le", []byte{0xff, 0xfe, 'h', 0x00}, utfbom.UTF16LittleEndian, []byte{'h', 0x00}},
+		{"utf32be", []byte{0x00, 0x00, 0xfe, 0xff, 0x00, 0x00, 0x00, 'h'}, utfbom.UTF32BigEndian, []byte{0x00, 0x00, 0x00, 'h'}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd, enc, err := utfbom.Skip(bytes.NewReader(tc.input))
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.enc)
+
+			rest, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, rest, tc.rest)
+		})
+	}
+}
+
+func TestSkip_UnderlyingReaderError(t *testing.T) {
+	t.Parallel()
+
+	_, enc, err := utfbom.Skip(iotest.ErrReader(errors.New("disk failure")))
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.Equal(t, enc, utfbom.Unknown)
+}
+
+func TestDetectReader(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input []byte
+		enc   utfbom.Encoding
+	}{
+		{"empty", nil, utfbom.Unknown},
+		{"no_bom", []byte("hello"), utfbom.Unknown},
+		{"utf8_bom_only", utf8BOM, utfbom.UTF8},
+		{"utf8", []byte("\xef\xbb\xbfhello"), utfbom.UTF8},
+		{"utf16le", []byte{0xff, 0xfe, 'h', 0x00}, utfbom.UTF16LittleEndian},
+		{"utf32be", []byte{0x00, 0x00, 0xfe, 0xff, 0x00, 0x00, 0x00, 'h'}, utfbom.UTF32BigEndian},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, src := range []io.Reader{
+				bytes.NewReader(tc.input),
+				iotest.OneByteReader(bytes.NewReader(tc.input)),
+				bufio.NewReader(bytes.NewReader(tc.input)),
+			} {
+				enc, rd, err := utfbom.DetectReader(src)
+				be.Err(t, err, nil)
+				be.Equal(t, enc, tc.enc)
+
+				out, err := io.ReadAll(rd)
+				be.Err(t, err, nil)
+				be.Equal(t, string(out), string(tc.input))
+			}
+		})
+	}
+}
+
+func TestDetectReader_UnderlyingReaderError(t *testing.T) {
+	t.Parallel()
+
+	enc, _, err := utfbom.DetectReader(iotest.ErrReader(errors.New("disk failure")))
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.Equal(t, enc, utfbom.Unknown)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed int
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed++
+
+	return nil
+}
+
+func TestReadCloser(t *testing.T) {
+	t.Parallel()
+
+	src := &closeRecorder{Reader: strings.NewReader(teststring)}
+	rc := utfbom.NewReadCloser(src)
+
+	out, err := io.ReadAll(rc)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), teststring[3:])
+	be.Equal(t, rc.Enc, utfbom.UTF8)
+
+	be.Err(t, rc.Close(), nil)
+	be.Equal(t, src.closed, 1)
+}
+
+func TestReadCloser_Reset(t *testing.T) {
+	t.Parallel()
+
+	first := &closeRecorder{Reader: strings.NewReader(teststring)}
+	rc := utfbom.NewReadCloser(first)
+
+	_, err := io.ReadAll(rc)
+	be.Err(t, err, nil)
+
+	second := &closeRecorder{Reader: strings.NewReader("hello")}
+	rc.Reset(second)
+
+	out, err := io.ReadAll(rc)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "hello")
+	be.Equal(t, rc.Enc, utfbom.Unknown)
+
+	be.Err(t, rc.Close(), nil)
+	be.Equal(t, first.closed, 0)
+	be.Equal(t, second.closed, 1)
+}
+
+func TestReader_Reset(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []utfbom.Option
+		input    string
+		expected string
+		enc      utfbom.Encoding
+	}{
+		{"default", nil, "\xef\xbb\xbfhello", "hello", utfbom.UTF8},
+		{"no_bom", nil, "hello", "hello", utfbom.Unknown},
+		{"passthrough_is_kept", []utfbom.Option{utfbom.Passthrough()}, "\xef\xbb\xbfhello", "\xef\xbb\xbfhello", utfbom.UTF8},
+		{"scrub_is_kept", []utfbom.Option{utfbom.ScrubInterior()}, "\xef\xbb\xbfhe\xef\xbb\xbfllo", "hello", utfbom.UTF8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// detection state of a failed stream must not leak into the next one
+			rd := utfbom.NewReader(iotest.ErrReader(errors.New("disk failure")), tc.opts...)
+			_, err := rd.Read(make([]byte, 16))
+			be.True(t, errors.Is(err, utfbom.ErrRead))
+
+			for range 2 {
+				rd.Reset(strings.NewReader(tc.input))
+
+				out, err := io.ReadAll(rd)
+				be.Err(t, err, nil)
+				be.Equal(t, string(out), tc.expected)
+				be.Equal(t, rd.Enc, tc.enc)
+			}
+
+			// leftovers of a partially read stream are dropped
+			rd.Reset(strings.NewReader("\xef\xbb\xbfab"))
+			_, err = rd.Read(make([]byte, 1))
+			be.Err(t, err, nil)
+
+			rd.Reset(strings.NewReader(tc.input))
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+		})
+	}
+}
+
+func TestReader_Reset_Allocations(t *testing.T) {
+	src := strings.NewReader("")
+	rd := utfbom.NewReader(src)
+	buf := make([]byte, 16)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		src.Reset("\ufeffhello")
+		rd.Reset(src)
+
+		_, err := rd.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	be.Equal(t, allocs, 0.0)
+}
+
+func ExampleReader_Reset() {
+	pool := sync.Pool{
+		New: func() any {
+			return utfbom.NewReader(nil)
+		},
+	}
+
+	for _, body := range []string{"\ufeffhello", "world"} {
+		rd, _ := pool.Get().(*utfbom.Reader)
+		rd.Reset(strings.NewReader(body))
+
+		out, err := io.ReadAll(rd)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("%s %q\n", rd.Enc, out)
+
+		pool.Put(rd)
+	}
+
+	// output:
+	// UTF8 "hello"
+	// Unknown "world"
+}
+
+func TestReader_Encoding(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader(teststring))
+
+	enc, err := rd.Encoding()
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF8)
+	be.Equal(t, rd.Enc, utfbom.UTF8)
+
+	// repeated calls neither consume payload nor change the result
+	enc, err = rd.Encoding()
+	be.Err(t, err, nil)
+	be.Equal(t, enc, utfbom.UTF8)
+
+	be.Err(t, iotest.TestReader(rd, []byte(teststring[3:])), nil)
+}
+
+func TestReader_BOM(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []utfbom.Option
+		input    []byte
+		expected []byte
+	}{
+		{"empty", nil, nil, nil},
+		{"no_bom", nil, []byte("hello"), nil},
+		{"utf8", nil, append(utf8BOM, "hello"...), utf8BOM},
+		{"utf16le", nil, append(utf16LEBOM, 'h', 0x00), utf16LEBOM},
+		{"utf32be", nil, append(utf32BEBOM, 0x00, 0x00, 0x00, 'h'), utf32BEBOM},
+		{"utf7_variant", []utfbom.Option{utfbom.WithExtendedBOMs()}, []byte("+/v9-hello"), []byte("+/v9")},
+		{"utf7_left_alone", nil, []byte("+/v9-hello"), nil},
+		{"passthrough", []utfbom.Option{utfbom.Passthrough()}, append(utf8BOM, "hello"...), nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, src := range []io.Reader{
+				iotest.OneByteReader(bytes.NewReader(tc.input)),
+				bufio.NewReader(bytes.NewReader(tc.input)),
+			} {
+				rd := utfbom.NewReader(src, tc.opts...)
+				be.Equal(t, rd.BOM(), nil)
+
+				_, err := io.ReadAll(rd)
+				be.Err(t, err, nil)
+				be.Equal(t, rd.BOM(), tc.expected)
+			}
+		})
+	}
+}
+
+func TestReader_UnreadBOM(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		opts     []utfbom.Option
+		input    []byte
+		enc      utfbom.Encoding
+		expected []byte
+	}{
+		{"empty", nil, nil, utfbom.Unknown, []byte{}},
+		{"no_bom", nil, []byte("hello"), utfbom.Unknown, []byte("hello")},
+		{"bom_only", nil, utf8BOM, utfbom.UTF8, utf8BOM},
+		{"utf8", nil, append(utf8BOM, "hello"...), utfbom.UTF8, append(utf8BOM, "hello"...)},
+		{"utf8_short", nil, append(utf8BOM, 'h'), utfbom.UTF8, append(utf8BOM, 'h')},
+		{"utf16le", nil, append(utf16LEBOM, 'h', 0x00), utfbom.UTF16LittleEndian, append(utf16LEBOM, 'h', 0x00)},
+		{"utf32be", nil, append(utf32BEBOM, 0x00, 0x00, 0x00, 'h'), utfbom.UTF32BigEndian, append(utf32BEBOM, 0x00, 0x00, 0x00, 'h')},
+		{"utf7_variant", []utfbom.Option{utfbom.WithExtendedBOMs()}, []byte("+/v9-hello"), utfbom.UTF7, []byte("+/v9-hello")},
+		{"passthrough", []utfbom.Option{utfbom.Passthrough()}, append(utf8BOM, "hello"...), utfbom.UTF8, append(utf8BOM, "hello"...)},
+		{
+			"scrub",
+			[]utfbom.Option{utfbom.ScrubInterior()},
+			append(append(utf8BOM, "he"...), append(utf8BOM, "llo"...)...), utfbom.UTF8, append(utf8BOM, "hello"...),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, src := range []io.Reader{
+				iotest.OneByteReader(bytes.NewReader(tc.input)),
+				bufio.NewReader(bytes.NewReader(tc.input)),
+			} {
+				rd := utfbom.NewReader(src, tc.opts...)
+
+				// unreading twice puts the BOM back once
+				be.Err(t, rd.UnreadBOM(), nil)
+				be.Err(t, rd.UnreadBOM(), nil)
+				be.Equal(t, rd.Enc, tc.enc)
+
+				out, err := io.ReadAll(rd)
+				be.Err(t, err, nil)
+				be.Equal(t, out, tc.expected)
+			}
+		})
+	}
+}
+
+func TestReader_UnreadBOM_AfterRead(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(bytes.NewReader(append(utf8BOM, "hello"...)))
+
+	buf := make([]byte, 1)
+
+	n, err := rd.Read(buf)
+	be.Err(t, err, nil)
+	be.Equal(t, string(buf[:n]), "h")
+
+	be.Err(t, rd.UnreadBOM(), utfbom.ErrPayloadConsumed)
+
+	// the payload is read from the start again after seeking there
+	_, err = rd.Seek(0, io.SeekStart)
+	be.Err(t, err, nil)
+	be.Err(t, rd.UnreadBOM(), nil)
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, out, append(utf8BOM, "hello"...))
+}
+
+func TestReader_UnreadBOM_BOMOnlyRead(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(bytes.NewReader(utf16BEBOM))
+
+	n, err := rd.Read(make([]byte, 16))
+	be.Err(t, err, io.EOF)
+	be.Equal(t, n, 0)
+
+	be.Err(t, rd.UnreadBOM(), nil)
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, out, utf16BEBOM)
+}
+
+func TestReader_UnreadBOM_DetectionError(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(bytes.NewReader(utf8BOM), utfbom.Forbid())
+	be.Err(t, rd.UnreadBOM(), utfbom.ErrBOMForbidden)
+}
+
+func TestReader_Seek(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		opts    []utfbom.Option
+		input   string
+		payload string
+	}{
+		{"no_bom", nil, "hello world", "hello world"},
+		{"utf8", nil, "\xef\xbb\xbfhello world", "hello world"},
+		{"utf32be", nil, "\x00\x00\xfe\xffhello world", "hello world"},
+		{"passthrough", []utfbom.Option{utfbom.Passthrough()}, "\xef\xbb\xbfhello world", "\xef\xbb\xbfhello world"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rd := utfbom.NewReader(strings.NewReader(tc.input), tc.opts...)
+
+			// seeking before any read detects the BOM first
+			pos, err := rd.Seek(0, io.SeekCurrent)
+			be.Err(t, err, nil)
+			be.Equal(t, pos, int64(0))
+
+			buf := make([]byte, 2)
+			_, err = io.ReadFull(rd, buf)
+			be.Err(t, err, nil)
+			be.Equal(t, string(buf), tc.payload[:2])
+
+			pos, err = rd.Seek(0, io.SeekCurrent)
+			be.Err(t, err, nil)
+			be.Equal(t, pos, int64(2))
+
+			pos, err = rd.Seek(-5, io.SeekEnd)
+			be.Err(t, err, nil)
+			be.Equal(t, pos, int64(len(tc.payload)-5))
+
+			out, err := io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), "world")
+
+			pos, err = rd.Seek(0, io.SeekStart)
+			be.Err(t, err, nil)
+			be.Equal(t, pos, int64(0))
+
+			out, err = io.ReadAll(rd)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.payload)
+
+			pos, err = rd.Seek(-1, io.SeekStart)
+			be.Err(t, err, utfbom.ErrNegativeOffset)
+			be.Equal(t, pos, int64(0))
+
+			// positions before the BOM are rejected by the wrapped reader, positions within it by Reader
+			pos, err = rd.Seek(-int64(len(tc.payload))-1, io.SeekEnd)
+			be.True(t, err != nil)
+			be.Equal(t, pos, int64(0))
+		})
+	}
+}
+
+func TestReader_Seek_Scrub(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("\xef\xbb\xbfab\xef\xbb\xbfcd"), utfbom.Passthrough(), utfbom.ScrubInterior())
+
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\xef\xbb\xbfabcd")
+
+	_, err = rd.Seek(0, io.SeekCurrent)
+	be.Err(t, err, utfbom.ErrSeekUnsupported)
+
+	pos, err := rd.Seek(0, io.SeekStart)
+	be.Err(t, err, nil)
+	be.Equal(t, pos, int64(0))
+
+	out, err = io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\xef\xbb\xbfabcd")
+
+	pos, err = rd.Seek(2, io.SeekStart)
+	be.Err(t, err, nil)
+	be.Equal(t, pos, int64(2))
+
+	out, err = io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "\xbfabcd")
+}
+
+func TestReader_Seek_IntoBOM(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("\xef\xbb\xbfhello"))
+
+	_, err := rd.Seek(-6, io.SeekEnd)
+	be.Err(t, err, utfbom.ErrNegativeOffset)
+
+	// the wrapped reader is left right after the BOM
+	out, err := io.ReadAll(rd)
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "hello")
+}
+
+func TestReader_Seek_NotSeeker(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(iotest.OneByteReader(strings.NewReader("hello")))
+
+	_, err := rd.Seek(0, io.SeekStart)
+	be.Err(t, err, utfbom.ErrNotSeeker)
+}
+
+func TestReader_Encoding_UnderlyingReaderError(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(iotest.ErrReader(errors.New("disk failure")))
+
+	for range 2 {
+		enc, err := rd.Encoding()
+		be.True(t, errors.Is(err, utfbom.ErrRead))
+		be.Equal(t, enc, utfbom.Unknown)
+	}
+}
+
+func TestReader_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected []byte
+	}{
+		{"empty", nil, nil},
+		{"no_bom", []byte("hello"), []byte("hello")},
+		{"only_bom", utf8BOM, nil},
+		{"utf8", append(utf8BOM, "hello"...), []byte("hello")},
+		{"utf32le", append(utf32LEBOM, 'h', 0x00, 0x00, 0x00), []byte{'h', 0x00, 0x00, 0x00}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			n, err := utfbom.NewReader(iotest.OneByteReader(bytes.NewReader(tc.input))).WriteTo(&buf)
+			be.Err(t, err, nil)
+			be.Equal(t, n, int64(len(tc.expected)))
+			be.Equal(t, buf.Bytes(), tc.expected)
+		})
+	}
+}
+
+func TestReader_WriteTo_AfterRead(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("\ufeffhello"))
+
+	buf := make([]byte, 2)
+	_, err := io.ReadFull(rd, buf)
+	be.Err(t, err, nil)
+
+	var out strings.Builder
+
+	n, err := io.Copy(&out, rd)
+	be.Err(t, err, nil)
+	be.Equal(t, n, int64(3))
+	be.Equal(t, string(buf)+out.String(), "hello")
+}
+
+func TestReader_WriteTo_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := utfbom.NewReader(iotest.ErrReader(errors.New("disk failure"))).WriteTo(io.Discard)
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+
+	_, err = utfbom.NewReader(strings.NewReader("\ufeffhello"), utfbom.Forbid()).WriteTo(io.Discard)
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+
+	errFull := errors.New("disk full")
+	_, err = utfbom.NewReader(strings.NewReader("hello")).WriteTo(errWriter{errFull})
+	be.Err(t, err, errFull)
+}
+
+func TestNewReader_Allocations(t *testing.T) {
+	src := strings.NewReader("")
+	buf := make([]byte, 16)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		src.Reset("\ufeffhello")
+
+		_, err := utfbom.NewReader(src).Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// no read buffer is allocated besides the Reader itself
+	be.True(t, allocs <= 1)
+}
+
+func TestReader_BufioReaderIsReused(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		enc      utfbom.Encoding
+		expected string
+	}{
+		{"empty", "", utfbom.Unknown, ""},
+		{"short", "h", utfbom.Unknown, "h"},
+		{"no_bom", "hello", utfbom.Unknown, "hello"},
+		{"utf8", "\xef\xbb\xbfhello", utfbom.UTF8, "hello"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			br := bufio.NewReader(iotest.OneByteReader(strings.NewReader(tc.input)))
+			rd := utfbom.NewReader(br)
+
+			enc, err := rd.Encoding()
+			be.Err(t, err, nil)
+			be.Equal(t, enc, tc.enc)
+
+			// nothing is held back by Reader, the payload can be read from the bufio.Reader itself
+			out, err := io.ReadAll(br)
+			be.Err(t, err, nil)
+			be.Equal(t, string(out), tc.expected)
+		})
+	}
+}
+
+func TestReader_BufioReaderError(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(bufio.NewReader(iotest.ErrReader(errors.New("disk failure"))))
+
+	_, err := rd.Read(make([]byte, 8))
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+}
+
+func BenchmarkNewReader(b *testing.B) {
+	src := strings.NewReader("")
+	buf := make([]byte, 64)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		src.Reset("\ufeffhello")
+
+		_, _ = utfbom.NewReader(src).Read(buf)
+	}
+}
+
+func BenchmarkReader_Read(b *testing.B) {
+	src := bytes.NewReader(nil)
+	input := append(utf8BOM, bytes.Repeat([]byte("a"), 1<<12)...)
+	buf := make([]byte, 16)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+
+	for b.Loop() {
+		src.Reset(input)
+
+		rd := utfbom.NewReader(src)
+		for {
+			_, err := rd.Read(buf)
+			if err != nil {
+				break
+			}
+		}
+	}
+}
+
+func TestDetectEncoding_ZeroCopy(t *testing.T) {
+	str := "\xef\xbb\xbf" + strings.Repeat("a", 1<<20)
+	b := []byte(str)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if utfbom.DetectEncoding(str) != utfbom.UTF8 || utfbom.DetectEncoding(b) != utfbom.UTF8 {
+			t.Fatal("UTF8 is not detected")
+		}
+
+		if utfbom.DetectEncodingPreferring(str, utfbom.UTF16LittleEndian) != utfbom.UTF8 {
+			t.Fatal("UTF8 is not detected")
+		}
+	})
+
+	be.Equal(t, allocs, 0.0)
+}
+
+func BenchmarkDetectEncoding_String(b *testing.B) {
+	input := "\ufeff" + strings.Repeat("a", 1<<20)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = utfbom.DetectEncoding(input)
+	}
+}
+
+func TestTrim_ZeroCopy(t *testing.T) {
+	str := "\xef\xbb\xbf" + strings.Repeat("a", 1<<20)
+	b := []byte(str)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		outStr, _ := utfbom.Trim(str)
+		outBytes, _ := utfbom.Trim(b)
+
+		if len(outStr) != 1<<20 || len(outBytes) != 1<<20 {
+			t.Fatal("BOM is not trimmed")
+		}
+	})
+
+	be.Equal(t, allocs, 0.0)
+
+	// the result aliases a []byte input
+	out, _ := utfbom.Trim(b)
+	out[0] = 'b'
+	be.Equal(t, b[3], byte('b'))
+}
+
+func TestUnwrap(t *testing.T) {
+	t.Parallel()
+
+	src := strings.NewReader("hello")
+	be.Equal(t, utfbom.NewReader(src).Unwrap(), io.Reader(src))
+	be.Equal(t, utfbom.NewUTF8Reader(src).Unwrap(), io.Reader(src))
+
+	rc := &closeRecorder{Reader: src}
+	be.Equal(t, utfbom.NewReadCloser(rc).Unwrap(), io.Reader(rc))
+
+	var dst bytes.Buffer
+
+	be.Equal(t, utfbom.NewWriter(&dst, utfbom.UTF8).Unwrap(), io.Writer(&dst))
+	be.Equal(t, utfbom.NewTrimWriter(&dst).Unwrap(), io.Writer(&dst))
+	be.Equal(t, utfbom.NewEncodingWriter(&dst, utfbom.UTF16LittleEndian).Unwrap(), io.Writer(&dst))
+
+	// capabilities of the wrapped value can be discovered
+	_, ok := utfbom.NewReader(src).Unwrap().(io.Seeker)
+	be.True(t, ok)
+}
+
+func TestReader_Peek(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		opts  []utfbom.Option
+		input []byte
+	}{
+		{"no_bom", nil, []byte("PK\x03\x04hello")},
+		{"utf8", nil, append(utf8BOM, "PK\x03\x04hello"...)},
+		{"utf32le", nil, append(utf32LEBOM, "PK\x03\x04hello"...)},
+		{"scrub", []utfbom.Option{utfbom.ScrubInterior()}, append(append(utf8BOM, "PK"...), append(utf8BOM, "\x03\x04hello"...)...)},
+	}
+
+	wrappers := map[string]func(io.Reader) io.Reader{
+		"one_byte": iotest.OneByteReader,
+		"bufio":    func(rd io.Reader) io.Reader { return bufio.NewReader(rd) },
+	}
+
+	for _, tc := range testCases {
+		for name, wrap := range wrappers {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				t.Parallel()
+
+				rd := utfbom.NewReader(wrap(bytes.NewReader(tc.input)), tc.opts...)
+
+				magic, err := rd.Peek(4)
+				be.Err(t, err, nil)
+				be.Equal(t, magic, []byte("PK\x03\x04"))
+
+				n, err := rd.Discard(2)
+				be.Err(t, err, nil)
+				be.Equal(t, n, 2)
+
+				buf := make([]byte, 3)
+
+				n, err = rd.Read(buf)
+				be.Err(t, err, nil)
+				be.Equal(t, buf[:n], []byte("\x03\x04h")[:n])
+
+				rest, err := io.ReadAll(rd)
+				be.Err(t, err, nil)
+				be.Equal(t, string(buf[:n])+string(rest), "\x03\x04hello")
+			})
+		}
+	}
+}
+
+func TestReader_Peek_Errors(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(bytes.NewReader(utf8BOM), utfbom.Forbid())
+
+	_, err := rd.Peek(1)
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+
+	_, err = rd.Discard(1)
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+
+	rd = utfbom.NewReader(bytes.NewReader(append(utf8BOM, "ab"...)))
+
+	b, err := rd.Peek(3)
+	be.Err(t, err, io.EOF)
+	be.Equal(t, b, []byte("ab"))
+
+	// the BOM can't be put in front of a payload peeked at
+	be.Err(t, rd.UnreadBOM(), utfbom.ErrPayloadConsumed)
+}
+
+func TestReader_Peek_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("\ufeffhello"))
+
+	b, err := rd.Peek(2)
+	be.Err(t, err, nil)
+	be.Equal(t, b, []byte("he"))
+
+	var out bytes.Buffer
+
+	n, err := rd.WriteTo(&out)
+	be.Err(t, err, nil)
+	be.Equal(t, n, int64(5))
+	be.Equal(t, out.String(), "hello")
+}
+
+func TestReader_Peek_Seek(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("\ufeffhello"))
+
+	_, err := rd.Peek(2)
+	be.Err(t, err, nil)
+
+	_, err = rd.Discard(1)
+	be.Err(t, err, nil)
+
+	pos, err := rd.Seek(0, io.SeekCurrent)
+	be.Err(t, err, nil)
+	be.Equal(t, pos, int64(1))
+
+	b, err := rd.Peek(2)
+	be.Err(t, err, nil)
+	be.Equal(t, b, []byte("el"))
+}
+
+func TestReader_ReadByte(t *testing.T) {
+	t.Parallel()
+
+	payload := binary.AppendUvarint(nil, 300)
+	payload = append(payload, 'x')
+
+	for _, src := range []io.Reader{
+		iotest.OneByteReader(bytes.NewReader(append(utf8BOM, payload...))),
+		bufio.NewReader(bytes.NewReader(append(utf8BOM, payload...))),
+	} {
+		rd := utfbom.NewReader(src)
+
+		v, err := binary.ReadUvarint(rd)
+		be.Err(t, err, nil)
+		be.Equal(t, v, uint64(300))
+
+		c, err := rd.ReadByte()
+		be.Err(t, err, nil)
+		be.Equal(t, c, byte('x'))
+
+		be.Err(t, rd.UnreadByte(), nil)
+
+		rest, err := io.ReadAll(rd)
+		be.Err(t, err, nil)
+		be.Equal(t, rest, []byte("x"))
+
+		_, err = rd.ReadByte()
+		be.Err(t, err, io.EOF)
+	}
+}
+
+func TestReader_ReadRune(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(strings.NewReader("\ufeffGrüße"))
+
+	var got []rune
+
+	for {
+		r, _, err := rd.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		be.Err(t, err, nil)
+
+		got = append(got, r)
+	}
+
+	be.Equal(t, string(got), "Grüße")
+
+	// the rune scanner steps back one rune
+	rd = utfbom.NewReader(strings.NewReader("ü!"))
+
+	r, size, err := rd.ReadRune()
+	be.Err(t, err, nil)
+	be.Equal(t, r, 'ü')
+	be.Equal(t, size, 2)
+	be.Err(t, rd.UnreadRune(), nil)
+
+	r, _, err = rd.ReadRune()
+	be.Err(t, err, nil)
+	be.Equal(t, r, 'ü')
+}
+
+func TestReader_ReadRune_Errors(t *testing.T) {
+	t.Parallel()
+
+	rd := utfbom.NewReader(bytes.NewReader(append(utf16LEBOM, 'h', 0x00)))
+
+	_, _, err := rd.ReadRune()
+	be.Err(t, err, utfbom.ErrUnsupportedEncoding)
+
+	// bytes are still readable
+	c, err := rd.ReadByte()
+	be.Err(t, err, nil)
+	be.Equal(t, c, byte('h'))
+
+	rd = utfbom.NewReader(bytes.NewReader(utf8BOM), utfbom.Forbid())
+
+	_, _, err = rd.ReadRune()
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+
+	_, err = rd.ReadByte()
+	be.Err(t, err, utfbom.ErrBOMForbidden)
+
+	be.Err(t, rd.UnreadByte(), utfbom.ErrBOMForbidden)
+	be.Err(t, rd.UnreadRune(), utfbom.ErrBOMForbidden)
+}
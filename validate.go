@@ -0,0 +1,401 @@
+package utfbom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Report describes a stream checked by Validate.
+type Report struct {
+	// Encoding is the encoding of the BOM, Unknown if the stream has none.
+	Encoding Encoding
+	// Size is the number of bytes read, BOM included.
+	Size int64
+	// Valid reports whether the payload is valid in its encoding.
+	Valid bool
+	// Invalid is the number of invalid sequences, such as malformed UTF-8,
+	// unpaired surrogates or a truncated last code unit.
+	Invalid int
+	// InvalidOffsets holds the byte offsets of the first invalid sequences, see ValidateMaxOffsets.
+	InvalidOffsets []int64
+	// LoneSurrogates is the number of unpaired surrogates of UTF-16 payloads,
+	// they are counted as invalid sequences as well.
+	LoneSurrogates int
+	// Interior is the number of U+FEFF (zero width no-break space) characters past the BOM,
+	// usually left over from concatenated BOM-prefixed files.
+	Interior int
+	// InteriorOffsets holds the byte offsets of the first interior U+FEFF characters, see ValidateMaxOffsets.
+	InteriorOffsets []int64
+}
+
+// ValidateOption configures Validate.
+type ValidateOption func(*validateConfig)
+
+type validateConfig struct {
+	assume     Encoding
+	maxOffsets int
+}
+
+// ValidateAssume sets the encoding payloads without a BOM are validated as, UTF8 by default.
+func ValidateAssume(enc Encoding) ValidateOption {
+	return func(c *validateConfig) {
+		c.assume = enc
+	}
+}
+
+// ValidateMaxOffsets limits the number of offsets recorded in Report.InvalidOffsets
+// and Report.InteriorOffsets, 100 each by default, so that the report of a badly broken stream stays small.
+// The counts are exact regardless of the limit. Negative values are ignored.
+func ValidateMaxOffsets(n int) ValidateOption {
+	return func(c *validateConfig) {
+		if n >= 0 {
+			c.maxOffsets = n
+		}
+	}
+}
+
+// Validate reads rd until EOF and reports its BOM, whether the payload is valid in the encoding
+// of the BOM and where interior U+FEFF characters are, as a machine-readable pre-flight check.
+// Offsets are counted in bytes from the beginning of rd, BOM included.
+//
+// UTF-8, UTF-16 and UTF-32 payloads are validated; UTF-16 unpaired surrogates are counted separately.
+// Payloads of other encodings, such as registered ones or UTF-7 assumed with ValidateAssume,
+// make Validate fail with ErrUnsupportedEncoding.
+// Read errors are returned as a ReadError together with the report of the data read so far.
+func Validate(rd io.Reader, opts ...ValidateOption) (Report, error) {
+	cfg := validateConfig{
+		assume:     UTF8,
+		maxOffsets: 100,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	buf := make([]byte, defaultBufSize)
+
+	n, err := readHead(rd, buf[:maxBOMLen], false)
+
+	v := validator{
+		rep: Report{
+			Encoding: DetectEncoding(buf[:n]),
+			Size:     int64(n),
+		},
+		max: cfg.maxOffsets,
+	}
+
+	v.enc = v.rep.Encoding
+	if v.enc == Unknown {
+		v.enc = cfg.assume
+	}
+
+	if !v.enc.AnyOf(UTF8) && !v.enc.IsUTF16() && !v.enc.IsUTF32() {
+		return v.rep, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, v.enc)
+	}
+
+	v.order = byteOrder(v.enc)
+	v.off = int64(v.rep.Encoding.Len())
+	r, w := v.rep.Encoding.Len(), n
+
+	for {
+		atEOF := errors.Is(err, io.EOF)
+		if err != nil && !atEOF {
+			return v.rep, newReadError("read", v.rep.Size, err)
+		}
+
+		r += v.scan(buf[r:w], atEOF)
+
+		if atEOF {
+			break
+		}
+
+		w = copy(buf, buf[r:w])
+		r = 0
+
+		n, err = rd.Read(buf[w:])
+		w += n
+		v.rep.Size += int64(n)
+	}
+
+	v.rep.Valid = v.rep.Invalid == 0
+
+	return v.rep, nil
+}
+
+// validator accumulates the Report of Validate chunk by chunk.
+type validator struct {
+	rep   Report
+	enc   Encoding
+	order binary.ByteOrder
+	off   int64 // stream offset of the next chunk
+	max   int
+}
+
+// scan validates b and returns the number of bytes consumed.
+// A sequence incomplete at the end of b is left for the next chunk unless atEOF is set.
+func (v *validator) scan(b []byte, atEOF bool) int {
+	var i int
+
+	switch v.enc.Family() {
+	case FamilyUTF16:
+		i = v.scanUTF16(b, atEOF)
+	case FamilyUTF32:
+		i = v.scanUTF32(b, atEOF)
+	default:
+		i = v.scanUTF8(b, atEOF)
+	}
+
+	v.off += int64(i)
+
+	return i
+}
+
+func (v *validator) scanUTF8(b []byte, atEOF bool) int {
+	i := 0
+
+	for i < len(b) {
+		if b[i] < utf8.RuneSelf {
+			i++
+
+			continue
+		}
+
+		if !atEOF && !utf8.FullRune(b[i:]) {
+			break
+		}
+
+		r, n := utf8.DecodeRune(b[i:])
+
+		switch {
+		case r == utf8.RuneError && n == 1:
+			v.invalid(i)
+		case r == '\ufeff':
+			v.interior(i)
+		default:
+		}
+
+		i += n
+	}
+
+	return i
+}
+
+func (v *validator) scanUTF16(b []byte, atEOF bool) int {
+	i := 0
+
+	for len(b)-i >= 2 {
+		u := rune(v.order.Uint16(b[i:]))
+
+		switch {
+		case u == '\ufeff':
+			v.interior(i)
+		case !utf16.IsSurrogate(u):
+		case u >= 0xdc00:
+			v.loneSurrogate(i)
+		case len(b)-i < 4 && !atEOF:
+			return i
+		case len(b)-i < 4:
+			v.loneSurrogate(i)
+		default:
+			if next := rune(v.order.Uint16(b[i+2:])); next >= 0xdc00 && next <= 0xdfff {
+				i += 2
+			} else {
+				v.loneSurrogate(i)
+			}
+		}
+
+		i += 2
+	}
+
+	if atEOF && i < len(b) {
+		v.invalid(i)
+		i = len(b)
+	}
+
+	return i
+}
+
+func (v *validator) scanUTF32(b []byte, atEOF bool) int {
+	i := 0
+
+	for ; len(b)-i >= 4; i += 4 {
+		r := rune(v.order.Uint32(b[i:]))
+
+		switch {
+		case r == '\ufeff':
+			v.interior(i)
+		case !utf8.ValidRune(r):
+			v.invalid(i)
+		default:
+		}
+	}
+
+	if atEOF && i < len(b) {
+		v.invalid(i)
+		i = len(b)
+	}
+
+	return i
+}
+
+// invalid records an invalid sequence at offset i of the current chunk.
+func (v *validator) invalid(i int) {
+	v.rep.Invalid++
+
+	if len(v.rep.InvalidOffsets) < v.max {
+		v.rep.InvalidOffsets = append(v.rep.InvalidOffsets, v.off+int64(i))
+	}
+}
+
+// loneSurrogate records an unpaired surrogate at offset i of the current chunk.
+func (v *validator) loneSurrogate(i int) {
+	v.rep.LoneSurrogates++
+	v.invalid(i)
+}
+
+// interior records U+FEFF at offset i of the current chunk.
+func (v *validator) interior(i int) {
+	v.rep.Interior++
+
+	if len(v.rep.InteriorOffsets) < v.max {
+		v.rep.InteriorOffsets = append(v.rep.InteriorOffsets, v.off+int64(i))
+	}
+}
+
+// validates reports whether the payload is checked by Reader.
+func (r *Reader) validates() bool {
+	return r.opts.validate && r.Enc == UTF8
+}
+
+// validator returns the validator of the payload, setting it up on the first call after NewReader, Reset or UnreadBOM.
+func (r *Reader) validator() *utf8Validator {
+	if r.valid.rd == nil {
+		r.valid.rd = payloadReader{r}
+
+		if !r.unread {
+			r.valid.off = int64(r.bomLen)
+		}
+	}
+
+	return &r.valid
+}
+
+// utf8Validator fails reading once the stream it wraps turns out not to be valid UTF-8.
+// The payload is read straight into the caller's buffer, except for a sequence split across reads,
+// which is held back until it is complete, so invalid bytes are never returned.
+type utf8Validator struct {
+	rd    io.Reader
+	hold  [utf8.UTFMax]byte // bytes read but not returned yet
+	w     int               // number of bytes in hold
+	ready int               // hold[:ready] is valid, hold[ready:w] is an incomplete sequence
+	off   int64             // stream offset of hold[0], or of the next byte read if hold is empty
+	err   error             // invalid sequence
+}
+
+// Read implements the io.Reader interface.
+func (v *utf8Validator) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		if v.ready != 0 {
+			n := copy(p, v.hold[:v.ready])
+			v.w = copy(v.hold[:], v.hold[n:v.w])
+			v.ready -= n
+			v.off += int64(n)
+
+			return n, nil
+		}
+
+		if v.err != nil {
+			return 0, v.err
+		}
+
+		if len(p) <= v.w {
+			// p can't take the held bytes along with new ones, the sequence is completed in hold
+			n, err := v.rd.Read(v.hold[v.w:])
+			v.w += n
+
+			i, bad := scanUTF8(v.hold[:v.w], errors.Is(err, io.EOF))
+			v.ready = i
+
+			if bad {
+				v.w = i
+				v.err = &TranscodeError{Offset: v.off + int64(i), Encoding: UTF8}
+			}
+
+			if err != nil && v.ready == 0 && !bad {
+				return 0, err
+			}
+
+			continue
+		}
+
+		k := copy(p, v.hold[:v.w])
+
+		n, err := v.rd.Read(p[k:])
+		b := p[:k+n]
+
+		i, bad := scanUTF8(b, errors.Is(err, io.EOF))
+		v.w = 0
+
+		if bad {
+			v.err = &TranscodeError{Offset: v.off + int64(i), Encoding: UTF8}
+		} else {
+			v.w = copy(v.hold[:], b[i:])
+		}
+
+		v.off += int64(i)
+
+		switch {
+		case i != 0:
+			// the bytes preceding the invalid or incomplete sequence are delivered first
+			if bad || v.w != 0 {
+				return i, nil
+			}
+
+			return i, err
+		case bad:
+			return 0, v.err
+		case err != nil:
+			return 0, err
+		default:
+		}
+	}
+}
+
+// scanUTF8 returns the length of the valid UTF-8 prefix of b and whether it is followed by an invalid sequence
+// rather than by an incomplete one. Incomplete sequences at the end of b are invalid if atEOF is set.
+func scanUTF8(b []byte, atEOF bool) (int, bool) {
+	if utf8.Valid(b) {
+		return len(b), false
+	}
+
+	i := 0
+
+	for i < len(b) {
+		if b[i] < utf8.RuneSelf {
+			i++
+
+			continue
+		}
+
+		if !atEOF && !utf8.FullRune(b[i:]) {
+			return i, false
+		}
+
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i, true
+		}
+
+		i += size
+	}
+
+	return i, false
+}
@@ -0,0 +1,277 @@
+package utfbom_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected utfbom.Report
+	}{
+		{
+			name:     "utf8",
+			input:    encode(utfbom.UTF8, "héllo"),
+			expected: utfbom.Report{Encoding: utfbom.UTF8, Size: 9, Valid: true},
+		},
+		{
+			name:     "no_bom",
+			input:    []byte("plain"),
+			expected: utfbom.Report{Encoding: utfbom.Unknown, Size: 5, Valid: true},
+		},
+		{
+			name:     "empty",
+			input:    nil,
+			expected: utfbom.Report{Encoding: utfbom.Unknown, Valid: true},
+		},
+		{
+			name:  "utf8_invalid_and_interior",
+			input: []byte("\xef\xbb\xbfa\xffb\xef\xbb\xbfc\xe2\x82"),
+			expected: utfbom.Report{
+				Encoding:        utfbom.UTF8,
+				Size:            12,
+				Invalid:         3,
+				InvalidOffsets:  []int64{4, 10, 11},
+				Interior:        1,
+				InteriorOffsets: []int64{6},
+			},
+		},
+		{
+			name:     "utf16_pair",
+			input:    encode(utfbom.UTF16LittleEndian, "a😀"),
+			expected: utfbom.Report{Encoding: utfbom.UTF16LittleEndian, Size: 8, Valid: true},
+		},
+		{
+			name:  "utf16_lone_surrogates",
+			input: []byte("\xff\xfea\x00\x00\xd8b\x00\x00\xdc\xff\xfe\x00\xd8"),
+			expected: utfbom.Report{
+				Encoding:        utfbom.UTF16LittleEndian,
+				Size:            14,
+				Invalid:         3,
+				InvalidOffsets:  []int64{4, 8, 12},
+				LoneSurrogates:  3,
+				Interior:        1,
+				InteriorOffsets: []int64{10},
+			},
+		},
+		{
+			name:  "utf16_truncated",
+			input: []byte("\xfe\xff\x00a\x00"),
+			expected: utfbom.Report{
+				Encoding:       utfbom.UTF16BigEndian,
+				Size:           5,
+				Invalid:        1,
+				InvalidOffsets: []int64{4},
+			},
+		},
+		{
+			name:  "utf32_out_of_range",
+			input: []byte("\x00\x00\xfe\xff\x00\x11\x00\x00\x00\x00\xfe\xff"),
+			expected: utfbom.Report{
+				Encoding:        utfbom.UTF32BigEndian,
+				Size:            12,
+				Invalid:         1,
+				InvalidOffsets:  []int64{4},
+				Interior:        1,
+				InteriorOffsets: []int64{8},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rep, err := utfbom.Validate(bytes.NewReader(tc.input))
+			be.Err(t, err, nil)
+			be.Equal(t, rep, tc.expected)
+
+			rep, err = utfbom.Validate(iotest.OneByteReader(bytes.NewReader(tc.input)))
+			be.Err(t, err, nil)
+			be.Equal(t, rep, tc.expected)
+		})
+	}
+}
+
+func TestValidate_ChunkBoundaries(t *testing.T) {
+	t.Parallel()
+
+	for _, enc := range []utfbom.Encoding{utfbom.UTF8, utfbom.UTF16BigEndian, utfbom.UTF32LittleEndian} {
+		input := encode(enc, strings.Repeat("é😀", 1000))
+
+		rep, err := utfbom.Validate(bytes.NewReader(input))
+		be.Err(t, err, nil)
+		be.True(t, rep.Valid)
+		be.Equal(t, rep.Size, int64(len(input)))
+	}
+}
+
+func TestValidate_Options(t *testing.T) {
+	t.Parallel()
+
+	input := encodeNoBOM(utfbom.UTF16LittleEndian, "é")
+
+	rep, err := utfbom.Validate(bytes.NewReader(input))
+	be.Err(t, err, nil)
+	be.Equal(t, rep.Valid, false)
+
+	rep, err = utfbom.Validate(bytes.NewReader(input), utfbom.ValidateAssume(utfbom.UTF16LittleEndian))
+	be.Err(t, err, nil)
+	be.True(t, rep.Valid)
+	be.Equal(t, rep.Encoding, utfbom.Unknown)
+
+	rep, err = utfbom.Validate(strings.NewReader("\xff\xff\xff"), utfbom.ValidateMaxOffsets(1))
+	be.Err(t, err, nil)
+	be.Equal(t, rep.Invalid, 3)
+	be.Equal(t, rep.InvalidOffsets, []int64{0})
+}
+
+func TestValidate_Errors(t *testing.T) {
+	t.Parallel()
+
+	rep, err := utfbom.Validate(strings.NewReader("+/v8hello"), utfbom.ValidateAssume(utfbom.UTF7))
+	be.Err(t, err, utfbom.ErrUnsupportedEncoding)
+	be.Equal(t, rep.Encoding, utfbom.Unknown)
+
+	errDisk := errors.New("disk failure")
+
+	rep, err = utfbom.Validate(iotest.DataErrReader(iotest.TimeoutReader(strings.NewReader("\ufeffhello"))))
+	be.Err(t, err, utfbom.ErrRead)
+	be.Err(t, err, iotest.ErrTimeout)
+	be.Equal(t, rep.Encoding, utfbom.UTF8)
+
+	_, err = utfbom.Validate(iotest.ErrReader(errDisk))
+	be.Err(t, err, errDisk)
+}
+
+func ExampleValidate() {
+	rep, _ := utfbom.Validate(strings.NewReader("\ufeffid,name\n1,\ufeffJürgen\n"))
+
+	fmt.Println(rep.Encoding, rep.Valid, rep.Interior, rep.InteriorOffsets)
+
+	// output:
+	// UTF8 true 1 [13]
+}
+
+func TestWithValidateUTF8(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		err      string
+	}{
+		{"valid", "\ufeffhéllo", "héllo", ""},
+		{"no_bom", "h\xffllo", "h\xffllo", ""},
+		{"invalid_byte", "\ufeffhé\xffllo", "hé", "utfbom: invalid sequence: UTF8 at offset 6"},
+		{"invalid_first", "\ufeff\xc3(", "", "at offset 3"},
+		{"truncated", "\ufeffhé\xe2\x82", "hé", "at offset 6"},
+		{"surrogate", "\ufeffa\xed\xa0\x80", "a", "at offset 4"},
+		{"interior_bom", "\ufeffa\ufeffb", "a\ufeffb", ""},
+	}
+
+	readers := map[string]func(string) io.Reader{
+		"plain":      func(s string) io.Reader { return strings.NewReader(s) },
+		"one_byte":   func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		"buffered":   func(s string) io.Reader { return bufio.NewReader(strings.NewReader(s)) },
+		"data_error": func(s string) io.Reader { return iotest.DataErrReader(strings.NewReader(s)) },
+	}
+
+	for _, tc := range testCases {
+		for name, newReader := range readers {
+			t.Run(tc.name+"/"+name, func(t *testing.T) {
+				t.Parallel()
+
+				rd := utfbom.NewReader(newReader(tc.input), utfbom.WithValidateUTF8())
+
+				out, err := io.ReadAll(iotest.OneByteReader(rd))
+				be.Equal(t, string(out), tc.expected)
+
+				var buf bytes.Buffer
+
+				_, werr := io.Copy(&buf, utfbom.NewReader(newReader(tc.input), utfbom.WithValidateUTF8()))
+				be.Equal(t, buf.String(), tc.expected)
+
+				if tc.err == "" {
+					be.Err(t, err, nil)
+					be.Err(t, werr, nil)
+
+					return
+				}
+
+				be.Err(t, err, utfbom.ErrInvalidSequence)
+				be.Err(t, err, tc.err)
+				be.Err(t, werr, tc.err)
+			})
+		}
+	}
+}
+
+func TestWithValidateUTF8_Combined(t *testing.T) {
+	t.Parallel()
+
+	const input = "\ufeffa\ufeffb\xffc"
+
+	out, err := io.ReadAll(utfbom.NewReader(strings.NewReader(input), utfbom.WithValidateUTF8(), utfbom.ScrubInterior()))
+	be.Err(t, err, "at offset 8")
+	be.Equal(t, string(out), "ab")
+
+	rd := utfbom.NewReader(strings.NewReader(input), utfbom.WithValidateUTF8())
+
+	b, err := rd.Peek(4)
+	be.Err(t, err, nil)
+	be.Equal(t, string(b), "a\ufeff")
+
+	out, err = io.ReadAll(rd)
+	be.Err(t, err, "at offset 8")
+	be.Equal(t, string(out), "a\ufeffb")
+
+	rd = utfbom.NewReader(strings.NewReader(input), utfbom.WithValidateUTF8())
+	be.Err(t, rd.UnreadBOM(), nil)
+
+	out, err = io.ReadAll(rd)
+	be.Err(t, err, "at offset 8")
+	be.Equal(t, string(out), "\ufeffa\ufeffb")
+
+	rd = utfbom.NewReader(strings.NewReader(input), utfbom.WithValidateUTF8())
+
+	pos, err := rd.Seek(4, io.SeekStart)
+	be.Err(t, err, nil)
+	be.Equal(t, pos, int64(4))
+
+	out, err = io.ReadAll(rd)
+	be.Err(t, err, "at offset 8")
+	be.Equal(t, string(out), "b")
+}
+
+func TestWithValidateUTF8_UTF8Reader(t *testing.T) {
+	t.Parallel()
+
+	const input = "\ufeffhé\xffllo\xe2\x82"
+
+	out, err := io.ReadAll(utfbom.NewUTF8Reader(iotest.OneByteReader(strings.NewReader(input)), utfbom.WithValidateUTF8()))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), "hé�llo��")
+
+	out, err = io.ReadAll(utfbom.NewUTF8Reader(strings.NewReader(input), utfbom.WithValidateUTF8(), utfbom.RejectInvalid()))
+	be.Err(t, err, "utfbom: invalid sequence: UTF8 at offset 6")
+	be.Equal(t, string(out), "hé")
+
+	out, err = io.ReadAll(utfbom.NewUTF8Reader(strings.NewReader(input)))
+	be.Err(t, err, nil)
+	be.Equal(t, string(out), input[3:])
+}
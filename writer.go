@@ -0,0 +1,413 @@
+package utfbom
+
+import (
+	"errors"
+	"io"
+	"slices"
+)
+
+var (
+	_ io.Writer       = (*Writer)(nil)
+	_ io.StringWriter = (*Writer)(nil)
+	_ io.ReaderFrom   = (*Writer)(nil)
+)
+
+// ErrWrite helps to trace error origin.
+var ErrWrite = errors.New("utfbom: I/O error during BOM writing")
+
+// Writer implements automatic BOM (Unicode Byte Order Mark) writing
+// for an io.Writer object.
+//
+// The BOM is written lazily, right before the first payload byte,
+// so an empty output stays empty.
+//
+// Writer is not safe for concurrent use.
+type Writer struct {
+	wr io.Writer
+	// bom holds BOM bytes that still have to be written.
+	bom []byte
+	// from is the Reader whose BOM is restored, see NewRoundTripWriter.
+	from *Reader
+	// buf is the copy buffer of ReadFrom given by WithBuffer.
+	buf []byte
+	// dedup is set until the payload is known to start or not to start with the BOM, see DedupBOM.
+	dedup bool
+	// seen is the number of leading payload bytes matching the BOM so far.
+	seen int
+}
+
+// NewWriter wraps an outgoing writer.
+// The BOM of enc is written exactly once, before the first payload byte.
+// For Unknown encoding, as well as under IgnoreBOM and ForbidBOM policies,
+// Writer passes all writes through unchanged.
+// With DedupBOM, a payload already starting with the BOM doesn't get a second one.
+func NewWriter(wr io.Writer, enc Encoding, opts ...Option) *Writer {
+	o := newOptions(opts)
+
+	w := &Writer{
+		wr:    wr,
+		buf:   o.buf,
+		dedup: o.dedup,
+	}
+
+	if o.policy.writesBOM() {
+		w.bom = enc.Bytes()
+	}
+
+	return w
+}
+
+// NewRoundTripWriter wraps an outgoing writer restoring the Byte Order Mark (BOM) removed by rd,
+// so a tool reading a file, transforming the text and writing it back reproduces the original BOM.
+// The exact BOM bytes returned by rd.BOM are written before the first payload byte,
+// nothing is added if rd removed no BOM.
+//
+// The BOM is looked up on the first non-empty write; if rd has not been read from by then,
+// its BOM is detected at that point, which makes the write fail if the detection fails.
+// Same as with NewWriter, an empty output stays empty.
+func NewRoundTripWriter(wr io.Writer, rd *Reader) *Writer {
+	return &Writer{
+		wr:   wr,
+		from: rd,
+	}
+}
+
+// Write implements the io.Writer interface.
+// On the first non-empty call, it writes the Byte Order Mark (BOM).
+// Subsequent calls delegate directly to the underlying Writer.
+// The returned byte count never includes BOM bytes.
+func (w *Writer) Write(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+
+	if w.dedup {
+		return w.writeDedup(buf)
+	}
+
+	err := w.writeBOM()
+	if err != nil {
+		return 0, err
+	}
+
+	return w.wr.Write(buf)
+}
+
+// WriteString implements the io.StringWriter interface, same as Write, but for a string.
+// The string is passed to the WriteString method of the underlying Writer if it has one,
+// so it is not copied into a byte slice.
+func (w *Writer) WriteString(s string) (int, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	if w.dedup {
+		var head [maxBOMLen]byte
+
+		n, err := w.Write(head[:copy(head[:], s)])
+		if err != nil || n == len(s) {
+			return n, err
+		}
+
+		m, err := io.WriteString(w.wr, s[n:])
+
+		return n + m, err
+	}
+
+	err := w.writeBOM()
+	if err != nil {
+		return 0, err
+	}
+
+	return io.WriteString(w.wr, s)
+}
+
+// ReadFrom implements the io.ReaderFrom interface, so io.Copy streams directly.
+// The Byte Order Mark (BOM) is written before the first payload byte read from rd,
+// the rest is copied with io.Copy to the underlying Writer, making use of
+// the io.WriterTo implementation of rd or the io.ReaderFrom implementation of the underlying Writer.
+// The returned byte count never includes BOM bytes.
+func (w *Writer) ReadFrom(rd io.Reader) (int64, error) {
+	var written int64
+
+	if len(w.bom) != 0 || w.from != nil || w.dedup {
+		buf := w.buf
+		if buf == nil {
+			buf = make([]byte, defaultBufSize)
+		}
+
+		for len(w.bom) != 0 || w.from != nil || w.dedup {
+			n, err := rd.Read(buf)
+			if n > 0 {
+				n, werr := w.Write(buf[:n])
+				written += int64(n)
+
+				if werr != nil {
+					return written, werr
+				}
+			}
+
+			if errors.Is(err, io.EOF) {
+				return written, nil
+			}
+
+			if err != nil {
+				return written, err
+			}
+		}
+	}
+
+	n, err := io.CopyBuffer(w.wr, rd, w.buf)
+
+	return written + n, err
+}
+
+// Unwrap returns the writer wrapped by w, so callers can discover its capabilities,
+// the same way http.ResponseController does for response writers.
+// Writing to it directly bypasses w and may put payload before the BOM.
+func (w *Writer) Unwrap() io.Writer {
+	return w.wr
+}
+
+// writeDedup writes buf unless it continues the BOM, dropping the part of it repeating the BOM.
+// The returned byte count includes the dropped bytes.
+func (w *Writer) writeDedup(buf []byte) (int, error) {
+	err := w.lookupBOM()
+	if err != nil {
+		return 0, err
+	}
+
+	rest := w.bom[w.seen:]
+	k := 0
+
+	for k < len(buf) && k < len(rest) && buf[k] == rest[k] {
+		k++
+	}
+
+	if k < len(rest) {
+		if k == len(buf) {
+			w.seen += k
+
+			return k, nil
+		}
+
+		// not a BOM after all, the bytes held back are payload written after the BOM
+		w.bom = append(slices.Clip(w.bom), w.bom[:w.seen]...)
+		k = 0
+	}
+
+	w.dedup = false
+
+	err = w.writeBOM()
+	if err != nil {
+		return k, err
+	}
+
+	n, err := w.wr.Write(buf[k:])
+
+	return k + n, err
+}
+
+// lookupBOM takes the BOM to write from the Reader given to NewRoundTripWriter, if any.
+func (w *Writer) lookupBOM() error {
+	if w.from == nil {
+		return nil
+	}
+
+	_, err := w.from.Encoding()
+	if err != nil {
+		return err
+	}
+
+	w.bom = w.from.BOM()
+	w.from = nil
+
+	return nil
+}
+
+// writeBOM writes pending BOM bytes, if any.
+// A partially written BOM is resumed on the next call.
+func (w *Writer) writeBOM() error {
+	err := w.lookupBOM()
+	if err != nil {
+		return err
+	}
+
+	if len(w.bom) == 0 {
+		return nil
+	}
+
+	n, err := w.wr.Write(w.bom)
+	w.bom = w.bom[n:]
+
+	if err != nil {
+		return errors.Join(ErrWrite, err)
+	}
+
+	if len(w.bom) != 0 {
+		return errors.Join(ErrWrite, io.ErrShortWrite)
+	}
+
+	w.bom = nil
+
+	return nil
+}
+
+var (
+	_ io.Writer       = (*TrimWriter)(nil)
+	_ io.StringWriter = (*TrimWriter)(nil)
+)
+
+// TrimWriter implements automatic BOM (Unicode Byte Order Mark) checking and
+// removing as necessary for an io.Writer object.
+//
+// A BOM split across several Write calls is removed as well.
+// TrimWriter buffers at most 4 bytes until the BOM question is resolved,
+// so call Flush after the last Write to make sure short payloads reach
+// the underlying writer.
+//
+// TrimWriter is not safe for concurrent use.
+type TrimWriter struct {
+	wr   io.Writer
+	opts options
+	err  error // BOM policy violation
+	buf  [maxBOMLen]byte
+	n    int // number of buffered bytes
+	off  int // start of buffered bytes not yet written
+	done bool
+	// Enc will be available once BOM detection is resolved
+	Enc Encoding
+}
+
+// NewTrimWriter wraps an outgoing writer.
+// Options follow the same rules as for Reader: under IgnoreBOM the BOM is kept,
+// ExpectBOM and ForbidBOM make Write and Flush fail when the policy is violated.
+func NewTrimWriter(wr io.Writer, opts ...Option) *TrimWriter {
+	return &TrimWriter{
+		wr:   wr,
+		opts: newOptions(opts),
+		Enc:  Unknown,
+	}
+}
+
+// Write implements the io.Writer interface.
+// Leading bytes are buffered until they either form a BOM, which is dropped,
+// or can no longer be one, in which case they are written out.
+// Subsequent calls delegate directly to the underlying Writer.
+func (w *TrimWriter) Write(buf []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if w.done {
+		err := w.flushBuffered()
+		if err != nil {
+			return 0, err
+		}
+
+		return w.wr.Write(buf)
+	}
+
+	n := copy(w.buf[w.n:], buf)
+	w.n += n
+
+	if w.n < maxBOMLen && isPartialBOM(w.buf[:w.n]) {
+		return n, nil
+	}
+
+	w.resolve()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	err := w.flushBuffered()
+	if err != nil {
+		return n, err
+	}
+
+	if n == len(buf) {
+		return n, nil
+	}
+
+	m, err := w.wr.Write(buf[n:])
+
+	return n + m, err
+}
+
+// WriteString implements the io.StringWriter interface, same as Write, but for a string.
+// Once the BOM question is resolved, the string is passed to the WriteString method
+// of the underlying Writer if it has one, so it is not copied into a byte slice.
+func (w *TrimWriter) WriteString(s string) (int, error) {
+	if !w.done {
+		var head [maxBOMLen]byte
+
+		n, err := w.Write(head[:copy(head[:], s)])
+		if err != nil || n == len(s) {
+			return n, err
+		}
+
+		m, err := io.WriteString(w.wr, s[n:])
+
+		return n + m, err
+	}
+
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	err := w.flushBuffered()
+	if err != nil {
+		return 0, err
+	}
+
+	return io.WriteString(w.wr, s)
+}
+
+// Flush resolves BOM detection with whatever has been buffered so far
+// and writes buffered payload bytes to the underlying Writer.
+func (w *TrimWriter) Flush() error {
+	if !w.done {
+		w.resolve()
+	}
+
+	if w.err != nil {
+		return w.err
+	}
+
+	return w.flushBuffered()
+}
+
+// Unwrap returns the writer wrapped by w, see Writer.Unwrap.
+func (w *TrimWriter) Unwrap() io.Writer {
+	return w.wr
+}
+
+func (w *TrimWriter) resolve() {
+	w.Enc = detectPreferring(w.buf[:w.n], w.opts.prefer, w.opts.extended)
+	w.err = w.opts.check(w.Enc)
+	w.done = true
+
+	if w.opts.strips(w.Enc) {
+		w.off = w.Enc.Len()
+	}
+}
+
+func (w *TrimWriter) flushBuffered() error {
+	if w.off == w.n {
+		return nil
+	}
+
+	n, err := w.wr.Write(w.buf[w.off:w.n])
+	w.off += n
+
+	if err != nil {
+		return errors.Join(ErrWrite, err)
+	}
+
+	if w.off != w.n {
+		return errors.Join(ErrWrite, io.ErrShortWrite)
+	}
+
+	return nil
+}
@@ -0,0 +1,576 @@
+package utfbom_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestWriter_BOMWrittenOnce(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		enc      utfbom.Encoding
+		expected []byte
+	}{
+		{"unknown", utfbom.Unknown, []byte("hello world")},
+		{"utf8", utfbom.UTF8, append(utf8BOM, "hello world"...)},
+		{"utf16be", utfbom.UTF16BigEndian, append(utf16BEBOM, "hello world"...)},
+		{"utf16le", utfbom.UTF16LittleEndian, append(utf16LEBOM, "hello world"...)},
+		{"utf32be", utfbom.UTF32BigEndian, append(utf32BEBOM, "hello world"...)},
+		{"utf32le", utfbom.UTF32LittleEndian, append(utf32LEBOM, "hello world"...)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			w := utfbom.NewWriter(&out, tc.enc)
+
+			for _, chunk := range []string{"hello", " ", "world"} {
+				n, err := w.Write([]byte(chunk))
+				be.Err(t, err, nil)
+				be.Equal(t, n, len(chunk))
+			}
+
+			be.Equal(t, out.Bytes(), tc.expected)
+		})
+	}
+}
+
+func TestWriter_EmptyOutputHasNoBOM(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	w := utfbom.NewWriter(&out, utfbom.UTF8)
+
+	for range 10 {
+		n, err := w.Write(nil)
+		be.Equal(t, 0, n)
+		be.Err(t, err, nil)
+	}
+
+	n, err := w.WriteString("")
+	be.Equal(t, 0, n)
+	be.Err(t, err, nil)
+
+	copied, err := io.Copy(w, strings.NewReader(""))
+	be.Equal(t, int64(0), copied)
+	be.Err(t, err, nil)
+
+	cw := utfbom.NewExcelCSVWriter(&out)
+	cw.Flush()
+	be.Err(t, cw.Error(), nil)
+
+	be.Equal(t, out.Len(), 0)
+}
+
+// shortWriter accepts at most limit bytes per Write call.
+type shortWriter struct {
+	out   bytes.Buffer
+	limit int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		p = p[:w.limit]
+	}
+
+	return w.out.Write(p)
+}
+
+func TestWriter_ShortBOMWriteIsResumed(t *testing.T) {
+	t.Parallel()
+
+	sw := &shortWriter{limit: 1}
+	w := utfbom.NewWriter(sw, utfbom.UTF8)
+
+	n, err := w.Write([]byte("a"))
+	be.True(t, errors.Is(err, utfbom.ErrWrite))
+	be.True(t, errors.Is(err, io.ErrShortWrite))
+	be.Equal(t, 0, n)
+
+	sw.limit = 100
+
+	n, err = w.Write([]byte("a"))
+	be.Err(t, err, nil)
+	be.Equal(t, 1, n)
+	be.Equal(t, sw.out.Bytes(), append(utf8BOM, 'a'))
+}
+
+func TestWriter_UnderlyingWriterError(t *testing.T) {
+	t.Parallel()
+
+	w := utfbom.NewWriter(errWriter{errors.New("disk full")}, utfbom.UTF8)
+
+	n, err := w.Write([]byte("a"))
+	be.Equal(t, 0, n)
+	be.True(t, errors.Is(err, utfbom.ErrWrite))
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestWriter_ReadFrom(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		src      func() io.Reader
+		expected []byte
+	}{
+		{"empty", func() io.Reader { return bytes.NewReader(nil) }, nil},
+		{"writer_to", func() io.Reader { return strings.NewReader("hello") }, append(utf8BOM, "hello"...)},
+		{"one_byte_reader", func() io.Reader { return iotest.OneByteReader(strings.NewReader("hello")) }, append(utf8BOM, "hello"...)},
+		{"data_with_eof", func() io.Reader { return iotest.DataErrReader(strings.NewReader("hello")) }, append(utf8BOM, "hello"...)},
+		{"empty_reads", func() io.Reader { return &emptyReadsReader{rd: strings.NewReader("hello")} }, append(utf8BOM, "hello"...)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			n, err := utfbom.NewWriter(&out, utfbom.UTF8).ReadFrom(tc.src())
+			be.Err(t, err, nil)
+			be.Equal(t, n, int64(max(len(tc.expected)-len(utf8BOM), 0)))
+			be.Equal(t, out.Bytes(), tc.expected)
+		})
+	}
+}
+
+// emptyReadsReader returns no data and no error on every other Read call.
+type emptyReadsReader struct {
+	rd    io.Reader
+	empty bool
+}
+
+func (r *emptyReadsReader) Read(p []byte) (int, error) {
+	r.empty = !r.empty
+	if r.empty {
+		return 0, nil
+	}
+
+	return r.rd.Read(p)
+}
+
+func TestWriter_ReadFrom_ViaCopy(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	w := utfbom.NewWriter(&out, utfbom.UTF16LittleEndian)
+
+	n, err := io.Copy(w, iotest.HalfReader(bytes.NewReader(bytes.Repeat([]byte{'a', 0x00}, 5000))))
+	be.Err(t, err, nil)
+	be.Equal(t, n, int64(10000))
+	be.Equal(t, out.Bytes(), append(utf16LEBOM, bytes.Repeat([]byte{'a', 0x00}, 5000)...))
+
+	// BOM is not repeated
+	n, err = w.ReadFrom(strings.NewReader("b\x00"))
+	be.Err(t, err, nil)
+	be.Equal(t, n, int64(2))
+	be.Equal(t, out.Len(), 10004)
+}
+
+func TestWriter_ReadFrom_Errors(t *testing.T) {
+	t.Parallel()
+
+	errDisk := errors.New("disk failure")
+
+	n, err := utfbom.NewWriter(io.Discard, utfbom.UTF8).ReadFrom(iotest.ErrReader(errDisk))
+	be.Err(t, err, errDisk)
+	be.Equal(t, n, int64(0))
+
+	n, err = utfbom.NewWriter(errWriter{errDisk}, utfbom.UTF8).ReadFrom(strings.NewReader("hello"))
+	be.True(t, errors.Is(err, utfbom.ErrWrite))
+	be.Equal(t, n, int64(0))
+
+	n, err = utfbom.NewWriter(errWriter{errDisk}, utfbom.Unknown).ReadFrom(strings.NewReader("hello"))
+	be.Err(t, err, errDisk)
+	be.Equal(t, n, int64(0))
+}
+
+func ExampleWriter() {
+	var out bytes.Buffer
+
+	cw := csv.NewWriter(utfbom.NewWriter(&out, utfbom.UTF8))
+	_ = cw.Write([]string{"Name", "City"})
+	_ = cw.Write([]string{"Jürgen", "Köln"})
+	cw.Flush()
+
+	fmt.Printf("%q\n", out.String())
+
+	// output:
+	// "\ufeffName,City\nJürgen,Köln\n"
+}
+
+func TestRoundTripWriter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"no_bom", []byte("hello")},
+		{"utf8", []byte("\xef\xbb\xbfhello")},
+		{"utf16le", []byte{0xff, 0xfe, 'h', 0x00}},
+		{"utf32be", []byte{0x00, 0x00, 0xfe, 0xff, 0x00, 0x00, 0x00, 'h'}},
+		{"utf7_variant", []byte("+/v9-hello")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			rd := utfbom.NewReader(bytes.NewReader(tc.input))
+			w := utfbom.NewRoundTripWriter(&out, rd)
+
+			// the BOM is detected on write when nothing was read yet
+			n, err := io.Copy(w, rd)
+			be.Err(t, err, nil)
+			be.Equal(t, n, int64(len(tc.input)-len(rd.BOM())))
+			be.Equal(t, out.Bytes(), tc.input)
+		})
+	}
+}
+
+func TestRoundTripWriter_DetectionError(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	rd := utfbom.NewReader(iotest.ErrReader(errors.New("disk failure")))
+	w := utfbom.NewRoundTripWriter(&out, rd)
+
+	n, err := w.Write([]byte("hello"))
+	be.True(t, errors.Is(err, utfbom.ErrRead))
+	be.Equal(t, n, 0)
+	be.Equal(t, out.Len(), 0)
+}
+
+func ExampleNewRoundTripWriter() {
+	rd := utfbom.NewReader(strings.NewReader("\ufeffhello world"))
+
+	text, err := io.ReadAll(rd)
+	if err != nil {
+		panic(err)
+	}
+
+	var out bytes.Buffer
+
+	_, err = utfbom.NewRoundTripWriter(&out, rd).Write(bytes.ToUpper(text))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q\n", out.String())
+
+	// output:
+	// "\ufeffHELLO WORLD"
+}
+
+func TestTrimWriter_SplitWrites(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte("hello world")
+
+	testCases := []struct {
+		name  string
+		input []byte
+		enc   utfbom.Encoding
+	}{
+		{"no_bom", payload, utfbom.Unknown},
+		{"utf8", append(utf8BOM, payload...), utfbom.UTF8},
+		{"utf16be", append(utf16BEBOM, payload...), utfbom.UTF16BigEndian},
+		{"utf16le", append(utf16LEBOM, payload...), utfbom.UTF16LittleEndian},
+		{"utf32be", append(utf32BEBOM, payload...), utfbom.UTF32BigEndian},
+		{"utf32le", append(utf32LEBOM, payload...), utfbom.UTF32LittleEndian},
+	}
+
+	for _, tc := range testCases {
+		for chunk := 1; chunk <= 5; chunk++ {
+			t.Run(fmt.Sprintf("%s_chunk_%d", tc.name, chunk), func(t *testing.T) {
+				t.Parallel()
+
+				var out bytes.Buffer
+
+				w := utfbom.NewTrimWriter(&out)
+
+				for in := tc.input; len(in) > 0; {
+					c := in[:min(chunk, len(in))]
+					in = in[len(c):]
+
+					n, err := w.Write(c)
+					be.Err(t, err, nil)
+					be.Equal(t, n, len(c))
+				}
+
+				be.Err(t, w.Flush(), nil)
+				be.Equal(t, w.Enc, tc.enc)
+				be.Equal(t, out.Bytes(), payload)
+			})
+		}
+	}
+}
+
+func TestTrimWriter_ShortPayloadNeedsFlush(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		enc      utfbom.Encoding
+		expected []byte
+	}{
+		{"empty", nil, utfbom.Unknown, nil},
+		{"incomplete_utf8_bom", []byte{0xef, 0xbb}, utfbom.Unknown, []byte{0xef, 0xbb}},
+		{"only_utf8_bom", utf8BOM, utfbom.UTF8, nil},
+		{"only_utf16le_bom", utf16LEBOM, utfbom.UTF16LittleEndian, nil},
+		{"utf16le_bom_and_nul", []byte{0xff, 0xfe, 0x00}, utfbom.UTF16LittleEndian, []byte{0x00}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			w := utfbom.NewTrimWriter(&out)
+
+			_, err := w.Write(tc.input)
+			be.Err(t, err, nil)
+			be.Equal(t, out.Len(), 0)
+
+			be.Err(t, w.Flush(), nil)
+			be.Equal(t, w.Enc, tc.enc)
+			be.Equal(t, out.Bytes(), tc.expected)
+		})
+	}
+}
+
+func TestTrimWriter_NonBOMPrefixIsNotBuffered(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	w := utfbom.NewTrimWriter(&out)
+
+	n, err := w.Write([]byte("{"))
+	be.Err(t, err, nil)
+	be.Equal(t, 1, n)
+	be.Equal(t, out.String(), "{")
+}
+
+func TestTrimWriter_UnderlyingWriterError(t *testing.T) {
+	t.Parallel()
+
+	w := utfbom.NewTrimWriter(errWriter{errors.New("disk full")})
+
+	n, err := w.Write([]byte("abcdef"))
+	be.Equal(t, 4, n)
+	be.True(t, errors.Is(err, utfbom.ErrWrite))
+}
+
+// stringRecorder is a writer counting the calls of its WriteString method.
+type stringRecorder struct {
+	bytes.Buffer
+	strings int
+}
+
+func (w *stringRecorder) WriteString(s string) (int, error) {
+	w.strings++
+
+	return w.Buffer.WriteString(s)
+}
+
+func TestWriter_WriteString(t *testing.T) {
+	t.Parallel()
+
+	var dst stringRecorder
+
+	w := utfbom.NewWriter(&dst, utfbom.UTF8)
+
+	n, err := w.WriteString("")
+	be.Err(t, err, nil)
+	be.Equal(t, n, 0)
+	be.Equal(t, dst.Len(), 0)
+
+	_, err = fmt.Fprintf(w, "%s=%d\n", "a", 1)
+	be.Err(t, err, nil)
+
+	n, err = w.WriteString("Grüße\n")
+	be.Err(t, err, nil)
+	be.Equal(t, n, len("Grüße\n"))
+	be.Equal(t, dst.strings, 1)
+	be.Equal(t, dst.String(), "\ufeffa=1\nGrüße\n")
+
+	_, err = utfbom.NewWriter(errWriter{errors.New("boom")}, utfbom.UTF8).WriteString("x")
+	be.Err(t, err, utfbom.ErrWrite)
+}
+
+func TestWriter_WriteString_Allocations(t *testing.T) {
+	w := utfbom.NewWriter(io.Discard, utfbom.UTF8)
+	s := strings.Repeat("x", 100)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = w.WriteString(s)
+	})
+	be.Equal(t, allocs, 0.0)
+}
+
+func TestTrimWriter_WriteString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		chunks   []string
+		expected string
+		strings  int
+	}{
+		{"no_bom", []string{"hello", " world"}, "hello world", 2},
+		{"bom", []string{"\ufeffhello", " world"}, "hello world", 2},
+		{"split_bom", []string{"\xef", "\xbb", "\xbfhello", " world"}, "hello world", 2},
+		{"bom_only", []string{"\ufeff", "hello"}, "hello", 1},
+		{"short", []string{"ab"}, "ab", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var dst stringRecorder
+
+			w := utfbom.NewTrimWriter(&dst)
+
+			for _, s := range tc.chunks {
+				n, err := w.WriteString(s)
+				be.Err(t, err, nil)
+				be.Equal(t, n, len(s))
+			}
+
+			be.Err(t, w.Flush(), nil)
+			be.Equal(t, dst.String(), tc.expected)
+			be.Equal(t, dst.strings, tc.strings)
+		})
+	}
+}
+
+func TestWriter_DedupBOM(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		enc      utfbom.Encoding
+		chunks   []string
+		expected string
+	}{
+		{"no_bom", utfbom.UTF8, []string{"hello"}, "\ufeffhello"},
+		{"bom", utfbom.UTF8, []string{"\ufeffhello"}, "\ufeffhello"},
+		{"split_bom", utfbom.UTF8, []string{"\xef", "\xbb", "\xbfhel", "lo"}, "\ufeffhello"},
+		{"bom_only", utfbom.UTF8, []string{"\ufeff"}, "\ufeff"},
+		{"bom_prefix", utfbom.UTF8, []string{"\xef\xbb", "x"}, "\ufeff\xef\xbbx"},
+		{"other_bom", utfbom.UTF8, []string{"\xff\xfeh\x00"}, "\ufeff\xff\xfeh\x00"},
+		{"interior_bom", utfbom.UTF8, []string{"a", "\ufeffb"}, "\ufeffa\ufeffb"},
+		{"utf16", utfbom.UTF16LittleEndian, []string{"\xff", "\xfeh\x00"}, "\xff\xfeh\x00"},
+		{"unknown", utfbom.Unknown, []string{"\ufeffhello"}, "\ufeffhello"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			w := utfbom.NewWriter(&out, tc.enc, utfbom.DedupBOM())
+
+			for _, chunk := range tc.chunks {
+				n, err := w.Write([]byte(chunk))
+				be.Err(t, err, nil)
+				be.Equal(t, n, len(chunk))
+			}
+
+			be.Equal(t, out.String(), tc.expected)
+
+			out.Reset()
+
+			w = utfbom.NewWriter(&out, tc.enc, utfbom.DedupBOM())
+
+			for _, chunk := range tc.chunks {
+				n, err := w.WriteString(chunk)
+				be.Err(t, err, nil)
+				be.Equal(t, n, len(chunk))
+			}
+
+			be.Equal(t, out.String(), tc.expected)
+		})
+	}
+}
+
+func TestWriter_DedupBOM_Copy(t *testing.T) {
+	t.Parallel()
+
+	for _, src := range []string{"\ufeffName,City\n", "Name,City\n"} {
+		var out bytes.Buffer
+
+		n, err := io.Copy(utfbom.NewWriter(&out, utfbom.UTF8, utfbom.DedupBOM()), iotest.OneByteReader(strings.NewReader(src)))
+		be.Err(t, err, nil)
+		be.Equal(t, n, int64(len(src)))
+		be.Equal(t, out.String(), "\ufeffName,City\n")
+	}
+}
+
+func TestWriter_DedupBOM_ShortWrite(t *testing.T) {
+	t.Parallel()
+
+	sw := &shortWriter{limit: 2}
+	w := utfbom.NewWriter(sw, utfbom.UTF8, utfbom.DedupBOM())
+
+	n, err := w.Write([]byte("\xef\xbb"))
+	be.Err(t, err, nil)
+	be.Equal(t, n, 2)
+	be.Equal(t, sw.out.Len(), 0)
+
+	n, err = w.Write([]byte("x"))
+	be.Err(t, err, io.ErrShortWrite)
+	be.Equal(t, n, 0)
+
+	sw.limit = 100
+
+	n, err = w.Write([]byte("x"))
+	be.Err(t, err, nil)
+	be.Equal(t, n, 1)
+	be.Equal(t, sw.out.String(), "\ufeff\xef\xbbx")
+}
+
+func ExampleDedupBOM() {
+	var out bytes.Buffer
+
+	w := utfbom.NewWriter(&out, utfbom.UTF8, utfbom.DedupBOM())
+	_, _ = io.Copy(w, strings.NewReader("\ufeffName,City\n"))
+
+	fmt.Printf("%q\n", out.String())
+
+	// output:
+	// "\ufeffName,City\n"
+}
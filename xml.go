@@ -0,0 +1,114 @@
+package utfbom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrEncodingMismatch is returned when the encoding declared by a document contradicts
+// its Byte Order Mark (BOM) or the encoding its first characters are written in.
+var ErrEncodingMismatch = errors.New("utfbom: declared encoding does not match")
+
+// xmlDeclLen limits the part of a document searched for the XML declaration.
+const xmlDeclLen = 1024
+
+// DetectXML detects the encoding of the XML document b following Appendix F of the XML specification.
+// It returns the detected encoding, the value of the encoding attribute of the
+// <?xml ... encoding="..."?> declaration, or an empty string if there is none, and an error
+// wrapping ErrEncodingMismatch if the two contradict each other.
+//
+// The encoding is told by the BOM, or without one by the null bytes of the UTF-16 or UTF-32 encoded "<?",
+// in which case the byte order is taken from the null bytes as well. Unknown is returned for
+// documents in UTF-8 or another ASCII-compatible encoding without a BOM; their declaration is trusted as is.
+// A declaration of "UTF-16" or "UTF-32" matches either byte order, other names are
+// recognized the same way as by ParseEncoding.
+func DetectXML(b []byte) (Encoding, string, error) {
+	enc := DetectEncoding(b)
+	payload := b[enc.Len():]
+
+	if enc == Unknown {
+		enc = xmlEncoding(payload)
+	}
+
+	head := payload[:min(len(payload), xmlDeclLen)]
+	if enc.IsUTF16() || enc.IsUTF32() {
+		head, _ = appendDecoded(nil, head, enc, true)
+	}
+
+	declared := xmlDeclaredEncoding(head)
+	if enc == Unknown || declared == "" || declares(declared, enc) {
+		return enc, declared, nil
+	}
+
+	return enc, declared, fmt.Errorf("%w: detected %s, declared %q", ErrEncodingMismatch, enc, declared)
+}
+
+// xmlEncoding recognizes BOM-less UTF-16 and UTF-32 XML documents by the way "<?" is encoded,
+// see Appendix F of the XML specification. It returns Unknown for anything else.
+func xmlEncoding(b []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(b, []byte{0x00, 0x00, 0x00, '<'}):
+		return UTF32BigEndian
+	case bytes.HasPrefix(b, []byte{'<', 0x00, 0x00, 0x00}):
+		return UTF32LittleEndian
+	case bytes.HasPrefix(b, []byte{0x00, '<', 0x00, '?'}):
+		return UTF16BigEndian
+	case bytes.HasPrefix(b, []byte{'<', 0x00, '?', 0x00}):
+		return UTF16LittleEndian
+	default:
+		return Unknown
+	}
+}
+
+// xmlDeclaredEncoding returns the value of the encoding attribute of the XML declaration
+// at the beginning of text, or an empty string if there is none.
+func xmlDeclaredEncoding(text []byte) string {
+	const space = " \t\r\n"
+
+	decl, ok := bytes.CutPrefix(text, []byte("<?xml"))
+	if !ok || len(decl) == 0 || !bytes.ContainsRune([]byte(space), rune(decl[0])) {
+		return ""
+	}
+
+	decl, _, ok = bytes.Cut(decl, []byte("?>"))
+	if !ok {
+		return ""
+	}
+
+	_, attr, ok := bytes.Cut(decl, []byte("encoding"))
+	if !ok {
+		return ""
+	}
+
+	attr, ok = bytes.CutPrefix(bytes.TrimLeft(attr, space), []byte("="))
+	if !ok {
+		return ""
+	}
+
+	attr = bytes.TrimLeft(attr, space)
+	if len(attr) == 0 || attr[0] != '"' && attr[0] != '\'' {
+		return ""
+	}
+
+	value, _, ok := bytes.Cut(attr[1:], attr[:1])
+	if !ok {
+		return ""
+	}
+
+	return string(value)
+}
+
+// declares reports whether the encoding name declared by a document stands for enc.
+func declares(declared string, enc Encoding) bool {
+	switch normalizeName(declared) {
+	case "utf16":
+		return enc.IsUTF16()
+	case "utf32":
+		return enc.IsUTF32()
+	default:
+		d, ok := lookupName(declared)
+
+		return ok && d == enc
+	}
+}
@@ -0,0 +1,69 @@
+package utfbom_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nalgeon/be"
+	"github.com/slash3b/utfbom"
+)
+
+func TestDetectXML(t *testing.T) {
+	t.Parallel()
+
+	const (
+		decl8  = `<?xml version="1.0" encoding="UTF-8"?><a/>`
+		decl16 = `<?xml version='1.0' encoding = 'utf-16' ?><a/>`
+	)
+
+	testCases := []struct {
+		name     string
+		input    []byte
+		enc      utfbom.Encoding
+		declared string
+		err      error
+	}{
+		{"empty", nil, utfbom.Unknown, "", nil},
+		{"no_declaration", []byte("<a/>"), utfbom.Unknown, "", nil},
+		{"no_encoding", []byte(`<?xml version="1.0"?><a/>`), utfbom.Unknown, "", nil},
+		{"latin1_no_bom", []byte(`<?xml version="1.0" encoding="ISO-8859-1"?><a/>`), utfbom.Unknown, "ISO-8859-1", nil},
+		{"utf8_bom", encode(utfbom.UTF8, decl8), utfbom.UTF8, "UTF-8", nil},
+		{"utf8_bom_no_declaration", encode(utfbom.UTF8, "<a/>"), utfbom.UTF8, "", nil},
+		{"utf8_bom_latin1", encode(utfbom.UTF8, `<?xml version="1.0" encoding="ISO-8859-1"?>`), utfbom.UTF8, "ISO-8859-1", utfbom.ErrEncodingMismatch},
+		{"utf16le_bom", encode(utfbom.UTF16LittleEndian, decl16), utfbom.UTF16LittleEndian, "utf-16", nil},
+		{"utf16be_bom_specific", encode(utfbom.UTF16BigEndian, `<?xml version="1.0" encoding="UTF-16BE"?>`), utfbom.UTF16BigEndian, "UTF-16BE", nil},
+		{"utf16be_bom_utf8", encode(utfbom.UTF16BigEndian, decl8), utfbom.UTF16BigEndian, "UTF-8", utfbom.ErrEncodingMismatch},
+		{"utf16be_bom_wrong_order", encode(utfbom.UTF16BigEndian, `<?xml version="1.0" encoding="UTF-16LE"?>`), utfbom.UTF16BigEndian, "UTF-16LE", utfbom.ErrEncodingMismatch},
+		{"utf16le_no_bom", encodeNoBOM(utfbom.UTF16LittleEndian, decl16), utfbom.UTF16LittleEndian, "utf-16", nil},
+		{"utf16be_no_bom_utf8", encodeNoBOM(utfbom.UTF16BigEndian, decl8), utfbom.UTF16BigEndian, "UTF-8", utfbom.ErrEncodingMismatch},
+		{"utf32le_no_bom", encodeNoBOM(utfbom.UTF32LittleEndian, `<?xml version="1.0" encoding="UTF-32"?>`), utfbom.UTF32LittleEndian, "UTF-32", nil},
+		{"utf32be_bom_utf16", encode(utfbom.UTF32BigEndian, decl16), utfbom.UTF32BigEndian, "utf-16", utfbom.ErrEncodingMismatch},
+		{"unterminated", encode(utfbom.UTF8, `<?xml version="1.0" encoding="latin1"`), utfbom.UTF8, "", nil},
+		{"unquoted", encode(utfbom.UTF8, `<?xml version="1.0" encoding=latin1?>`), utfbom.UTF8, "", nil},
+		{"not_a_declaration", encode(utfbom.UTF8, `<?xml-stylesheet encoding="latin1"?>`), utfbom.UTF8, "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			enc, declared, err := utfbom.DetectXML(tc.input)
+			be.Err(t, err, tc.err)
+			be.Equal(t, enc, tc.enc)
+			be.Equal(t, declared, tc.declared)
+		})
+	}
+}
+
+func ExampleDetectXML() {
+	// a UTF-8 BOM in front of a document claiming to be Latin-1
+	doc := append([]byte{0xef, 0xbb, 0xbf}, `<?xml version="1.0" encoding="ISO-8859-1"?><a/>`...)
+
+	enc, declared, err := utfbom.DetectXML(doc)
+	fmt.Println(enc, declared)
+	fmt.Println(err)
+
+	// output:
+	// UTF8 ISO-8859-1
+	// utfbom: declared encoding does not match: detected UTF8, declared "ISO-8859-1"
+}